@@ -3,38 +3,123 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"ReelTalkBot-Go/internal/app"
+	"ReelTalkBot-Go/internal/metrics"
+	"ReelTalkBot-Go/internal/middleware"
 	"ReelTalkBot-Go/internal/types"
 )
 
 func main() {
 	botApp := app.NewApp()
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", webhookHandler(botApp))
+
+	// Discord interactions endpoint, when the Discord adapter is configured
+	if botApp.DiscordHandler != nil {
+		mux.HandleFunc("/discord", botApp.DiscordHandler.HandleDiscordInteraction)
+	}
+
+	// WhatsApp Cloud API webhook, when the WhatsApp adapter is configured
+	if botApp.WhatsAppHandler != nil {
+		mux.HandleFunc("/whatsapp", botApp.WhatsAppHandler.HandleWhatsAppWebhook)
+	}
+
+	// Health endpoint for load balancers; add ?openai=1 to also ping OpenAI
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status, healthy := botApp.Health(r.URL.Query().Get("openai") == "1")
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Printf("Failed to write health status: %v", err)
+		}
+	})
+
+	// Prometheus metrics: message volume, rate-limit hits, KB and OpenAI answers, errors, and OpenAI latency
+	mux.Handle("/metrics", metrics.Handler())
+
+	var handler http.Handler = mux
+	if botApp.AccessLogEnabled {
+		handler = middleware.AccessLog(mux)
+	}
+
+	port := ":8080"
+	server := &http.Server{Addr: port, Handler: handler}
+
+	// On SIGINT or SIGTERM stop accepting requests, then flush buffered logs before exiting
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		log.Println("Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Failed to shut down server cleanly: %v", err)
+		}
+		botApp.Close()
+	}()
+
+	log.Printf("Starting server on port %s...", port)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+	<-shutdownDone
+}
+
+// webhookHandler receives Telegram updates and dispatches them for processing.
+func webhookHandler(botApp *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			log.Printf("Ignoring webhook request with method %s", r.Method)
+			rejectUpdate(w, botApp.WebhookStrictErrors, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Reject updates that don't come from Telegram before spending anything on them
+		if !botApp.VerifyWebhookSecret(r.Header.Get("X-Telegram-Bot-Api-Secret-Token")) {
+			log.Printf("Rejected webhook request with a missing or invalid secret token")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
 		var update types.TelegramUpdate // Changed from types.Update to types.TelegramUpdate
 		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
 			log.Printf("Failed to decode update: %v", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+			rejectUpdate(w, botApp.WebhookStrictErrors, "Bad request", http.StatusBadRequest)
 			return
 		}
 
-		go botApp.HandleUpdate(&update)
+		if !botApp.DispatchUpdate(&update) {
+			log.Printf("Update queue full. Rejecting update %d", update.UpdateID)
+			w.WriteHeader(botApp.QueueFullStatus)
+			return
+		}
 
 		w.WriteHeader(http.StatusOK)
-	})
+	}
+}
 
-	port := ":8080"
-	log.Printf("Starting server on port %s...", port)
-	if err := http.ListenAndServe(port, nil); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+// rejectUpdate answers a webhook request the bot won't process. Telegram retries anything other than 2xx,
+// so unless strict errors are enabled for debugging the request is acknowledged with 200 and only logged.
+func rejectUpdate(w http.ResponseWriter, strict bool, message string, status int) {
+	if !strict {
+		w.WriteHeader(http.StatusOK)
+		return
 	}
+	http.Error(w, message, status)
 }
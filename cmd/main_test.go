@@ -0,0 +1,76 @@
+// cmd/main_test.go
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/app"
+	"ReelTalkBot-Go/internal/queue"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestWebhookResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		strict       bool
+		method       string
+		secret       string // Sent in the secret token header
+		body         string
+		wantStatus   int
+		wantDispatch bool
+	}{
+		{"update", false, http.MethodPost, "s3cret", `{"update_id":1,"message":{"message_id":5,"text":"Hi"}}`, http.StatusOK, true},
+		{"unhandled update kind", false, http.MethodPost, "s3cret", `{"update_id":2,"poll":{"id":"p1"}}`, http.StatusOK, true},
+		{"malformed update", false, http.MethodPost, "s3cret", `{"update_id":`, http.StatusOK, false},
+		{"wrong method", false, http.MethodGet, "s3cret", "", http.StatusOK, false},
+		{"malformed update, strict", true, http.MethodPost, "s3cret", `{"update_id":`, http.StatusBadRequest, false},
+		{"wrong method, strict", true, http.MethodGet, "s3cret", "", http.StatusMethodNotAllowed, false},
+		{"wrong secret", false, http.MethodPost, "guess", `{"update_id":3}`, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex      sync.Mutex
+				dispatched []*types.TelegramUpdate
+			)
+			botApp := &app.App{
+				WebhookSecret:       "s3cret",
+				WebhookStrictErrors: tt.strict,
+				QueueFullStatus:     http.StatusServiceUnavailable,
+				UpdateQueue: queue.NewUpdateQueue(10, 1, func(update *types.TelegramUpdate) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					dispatched = append(dispatched, update)
+				}),
+			}
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.secret)
+			rec := httptest.NewRecorder()
+			webhookHandler(botApp)(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			deadline := time.Now().Add(time.Second)
+			for {
+				mutex.Lock()
+				got := len(dispatched)
+				mutex.Unlock()
+				if (got > 0) == tt.wantDispatch || time.Now().After(deadline) {
+					if (got > 0) != tt.wantDispatch {
+						t.Errorf("dispatched %d updates, want dispatch %v", got, tt.wantDispatch)
+					}
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -6,90 +6,309 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"ReelTalkBot-Go/internal/httpclient"
 	"ReelTalkBot-Go/internal/types"
-	"ReelTalkBot-Go/internal/utils"
+)
+
+// Finish reasons reported by OpenAI that need special handling
+const (
+	finishReasonLength        = "length"
+	finishReasonContentFilter = "content_filter"
+)
+
+// DefaultTruncationNotice is appended to answers that were cut off by the token limit
+const DefaultTruncationNotice = "\n\n_(This answer was cut short. Send /retry for a complete answer.)_"
+
+// continuationPrompt asks OpenAI to pick up an answer that was cut off by the token limit
+const continuationPrompt = "Continue exactly where you left off, without repeating anything."
+
+// DefaultPartialStreamNotice is appended to streamed answers that broke off before OpenAI finished them
+const DefaultPartialStreamNotice = "\n\n_(The connection dropped, so this answer may be incomplete. Send /retry to try again.)_"
+
+// ErrContentFiltered is returned when OpenAI withholds a response because of its content filter
+var ErrContentFiltered = errors.New("OpenAI response was blocked by the content filter")
+
+// ErrNoChoices is returned when OpenAI keeps answering without any choices after EmptyChoiceRetries retries
+var ErrNoChoices = errors.New("no choices returned in OpenAI response")
+
+// Default retry settings for OpenAI requests
+const (
+	DefaultMaxRetries     = 2
+	DefaultRetryBaseDelay = time.Second
+	DefaultDeadline       = 30 * time.Second
+
+	DefaultEmptyChoiceRetries = 1
+	DefaultMaxContinuations   = 0
+)
+
+// Default sampling settings used unless others are configured
+const (
+	DefaultModel       = "gpt-4o-mini"
+	DefaultTemperature = 0.7
+	DefaultMaxTokens   = 4096
+)
+
+// Accepted ranges for the sampling settings
+const (
+	MaxTemperature = 2.0
+	MinMaxTokens   = 16
+	MaxMaxTokens   = 16384
 )
 
 // APIHandler handles OpenAI API interactions
 type APIHandler struct {
-	OpenAIKey      string
-	OpenAIEndpoint string
-	Client         *http.Client
+	OpenAIKey           string
+	OpenAIEndpoint      string
+	Model               string
+	Temperature         float64 // Sampling temperature between 0 and MaxTemperature
+	MaxTokens           int     // max_tokens requested for each completion, between MinMaxTokens and MaxMaxTokens
+	Client              *http.Client
+	TruncationNotice    string                                      // Appended when finish_reason is "length"; empty disables the notice
+	OnUsage             func(model string, usage types.OpenAIUsage) // Optional hook called with the token usage of each response
+	ContextTokens       int                                         // Overrides the model's context window; 0 uses the known size
+	MaxRetries          int                                         // Extra attempts after a network error, 429, or 5xx response
+	RetryBaseDelay      time.Duration                               // Delay before the first retry, doubled on each further retry
+	Deadline            time.Duration                               // Overall time allowed for a query including retries
+	EmptyChoiceRetries  int                                         // Extra attempts when a response has no choices
+	MaxContinuations    int                                         // Follow-up requests made to finish an answer cut off by the token limit
+	KeepPartialStream   bool                                        // Return the content received before a stream broke off instead of an error
+	PartialStreamNotice string                                      // Appended to partial streamed answers; empty disables the notice
+	TranscriptionModel  string                                      // Model used by TranscribeAudio
+	DebugLog            bool                                        // Logs each request and raw response, redacted; for diagnosing answers, not for production
+	DebugMaskPII        bool                                        // Masks email addresses and phone numbers in the debug log
+	DebugMaxBytes       int                                         // Caps each body written to the debug log; 0 logs it whole
+	tokens              *tokenEstimator
 }
 
 // NewAPIHandler initializes a new APIHandler
 func NewAPIHandler(openAIKey, openAIEndpoint string) *APIHandler {
 	return &APIHandler{
-		OpenAIKey:      openAIKey,
-		OpenAIEndpoint: openAIEndpoint,
-		Client: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		OpenAIKey:           openAIKey,
+		OpenAIEndpoint:      openAIEndpoint,
+		Model:               DefaultModel,
+		Temperature:         DefaultTemperature,
+		MaxTokens:           DefaultMaxTokens,
+		Client:              httpclient.New(15 * time.Second),
+		TruncationNotice:    DefaultTruncationNotice,
+		MaxRetries:          DefaultMaxRetries,
+		RetryBaseDelay:      DefaultRetryBaseDelay,
+		Deadline:            DefaultDeadline,
+		EmptyChoiceRetries:  DefaultEmptyChoiceRetries,
+		MaxContinuations:    DefaultMaxContinuations,
+		KeepPartialStream:   true,
+		PartialStreamNotice: DefaultPartialStreamNotice,
+		TranscriptionModel:  DefaultTranscriptionModel,
+		DebugMaskPII:        true,
+		DebugMaxBytes:       DefaultDebugMaxBytes,
+		tokens:              newTokenEstimator(),
 	}
 }
 
+// postWithRetry posts the body to OpenAI, retrying network errors, 429, and 5xx responses with exponential
+// backoff (or the server's Retry-After) until MaxRetries is reached or the context expires.
+func (api *APIHandler) postWithRetry(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+api.OpenAIKey)
+
+		var wait time.Duration
+		resp, err := api.Client.Do(req)
+		if err != nil {
+			err = fmt.Errorf("error making request to OpenAI: %w", err)
+		} else {
+			bodyBytes, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			switch {
+			case readErr != nil:
+				err = fmt.Errorf("error reading response body: %w", readErr)
+			case resp.StatusCode == http.StatusOK:
+				return bodyBytes, nil
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+				err = &types.APIError{Service: "OpenAI", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+				wait = retryAfter(resp.Header.Get("Retry-After"))
+			default:
+				// Other client errors won't succeed on retry
+				return nil, &types.APIError{Service: "OpenAI", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+			}
+		}
+
+		if attempt >= api.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+		if wait == 0 {
+			wait = api.RetryBaseDelay << attempt
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, err // The retry could not finish before the deadline
+		}
+
+		log.Printf("OpenAI request failed (attempt %d): %v. Retrying in %s", attempt+1, err, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date. It returns 0 when absent or invalid.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
 // QueryOpenAIWithMessages sends a request to OpenAI with given messages and returns response text
 func (api *APIHandler) QueryOpenAIWithMessages(messages []types.OpenAIMessage) (string, error) {
-	fullEndpoint := fmt.Sprintf("%s/chat/completions", api.OpenAIEndpoint)
+	return api.QueryOpenAIWithModel(api.Model, messages)
+}
+
+// QueryOpenAIWithModel sends a request to OpenAI using the given model instead of the configured one
+func (api *APIHandler) QueryOpenAIWithModel(model string, messages []types.OpenAIMessage) (string, error) {
+	return api.CompleteWithModel(context.Background(), model, messages)
+}
+
+// CompleteWithModel implements LLMProvider. Answers cut off by the token limit are continued up to
+// MaxContinuations times before the truncation notice is appended.
+func (api *APIHandler) CompleteWithModel(ctx context.Context, model string, messages []types.OpenAIMessage) (string, error) {
+	if err := ValidateEndpoint(api.OpenAIEndpoint); err != nil {
+		return "", err
+	}
+	fullEndpoint := fmt.Sprintf("%s/chat/completions", strings.TrimRight(api.OpenAIEndpoint, "/"))
+	if model == "" {
+		model = api.Model
+	}
+
+	// The deadline covers every attempt, including retries and continuations
+	ctx, cancel := context.WithTimeout(ctx, api.Deadline)
+	defer cancel()
+
+	var content string
+	for continuation := 0; ; continuation++ {
+		choice, err := api.complete(ctx, fullEndpoint, model, messages)
+		if err != nil {
+			if continuation > 0 {
+				// Keep the part already received rather than losing the whole answer
+				log.Printf("OpenAI continuation %d failed: %v", continuation, err)
+				return content + api.TruncationNotice, nil
+			}
+			return "", err
+		}
+		log.Printf("OpenAI finish reason: %s", choice.FinishReason)
+
+		switch choice.FinishReason {
+		case finishReasonContentFilter:
+			return "", ErrContentFiltered
+		case finishReasonLength:
+			content += choice.Message.Content
+			if continuation < api.MaxContinuations && ctx.Err() == nil {
+				log.Printf("OpenAI answer hit the token limit, requesting continuation %d", continuation+1)
+				messages = append(messages[:len(messages):len(messages)],
+					types.OpenAIMessage{Role: "assistant", Content: choice.Message.Content},
+					types.OpenAIMessage{Role: "user", Content: continuationPrompt},
+				)
+				continue
+			}
+			return content + api.TruncationNotice, nil
+		}
+		// Long answers are split into several Telegram messages when sent
+		return content + choice.Message.Content, nil
+	}
+}
+
+// complete requests a single completion, retrying responses without choices up to EmptyChoiceRetries times.
+func (api *APIHandler) complete(ctx context.Context, endpoint, model string, messages []types.OpenAIMessage) (types.OpenAIResponseChoice, error) {
+	// Trim history up front rather than letting OpenAI reject an over-long request
+	if fitted := api.fitToContext(model, messages); len(fitted) < len(messages) {
+		log.Printf("Trimmed %d oldest messages to fit the %s context window", len(messages)-len(fitted), model)
+		messages = fitted
+	}
 
 	query := types.OpenAIQuery{
-		Model:       "gpt-4o-mini", // Corrected model name
+		Model:       model,
 		Messages:    messages,
-		Temperature: 0.7,
-		MaxTokens:   4096, // Increased character limit
+		Temperature: api.Temperature,
+		MaxTokens:   api.MaxTokens,
 	}
 
 	body, err := json.Marshal(query)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal OpenAI query: %w", err)
+		return types.OpenAIResponseChoice{}, fmt.Errorf("failed to marshal OpenAI query: %w", err)
 	}
+	api.debugLog("request", model, body)
 
-	// Use context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// A response without choices is occasionally transient, so it is retried separately from HTTP failures
+	for attempt := 0; ; attempt++ {
+		bodyBytes, err := api.postWithRetry(ctx, endpoint, body)
+		if err != nil {
+			return types.OpenAIResponseChoice{}, err
+		}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", fullEndpoint, bytes.NewBuffer(body))
-	if err != nil {
-		return "", fmt.Errorf("failed to create OpenAI request: %w", err)
+		api.debugLog("response", model, bodyBytes)
+
+		// Parse and handle response
+		var result types.OpenAIResponse
+		if err := json.Unmarshal(bodyBytes, &result); err != nil {
+			return types.OpenAIResponseChoice{}, fmt.Errorf("error unmarshalling response: %w", err)
+		}
+		if api.OnUsage != nil {
+			api.OnUsage(model, result.Usage)
+		}
+		if len(result.Choices) > 0 {
+			return result.Choices[0], nil
+		}
+		if attempt >= api.EmptyChoiceRetries || ctx.Err() != nil {
+			return types.OpenAIResponseChoice{}, ErrNoChoices
+		}
+		log.Printf("OpenAI returned no choices (attempt %d). Retrying", attempt+1)
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// Ping checks that the OpenAI endpoint is reachable and accepts the API key by listing models,
+// which costs nothing, unlike a completion.
+func (api *APIHandler) Ping(ctx context.Context) error {
+	if err := ValidateEndpoint(api.OpenAIEndpoint); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(api.OpenAIEndpoint, "/")+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create OpenAI ping request: %w", err)
+	}
 	req.Header.Set("Authorization", "Bearer "+api.OpenAIKey)
 
 	resp, err := api.Client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error making request to OpenAI: %w", err)
+		return fmt.Errorf("error pinging OpenAI: %w", err)
 	}
 	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response body: %w", err)
-	}
+	io.Copy(io.Discard, resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OpenAI returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Parse and handle response
-	var result types.OpenAIResponse
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("error unmarshalling response: %w", err)
+		return &types.APIError{Service: "OpenAI ping", StatusCode: resp.StatusCode}
 	}
-
-	// Extract content
-	if len(result.Choices) > 0 {
-		content := result.Choices[0].Message.Content
-		if len(content) > 4096 { // Telegram's max message length
-			content = utils.SummarizeToLength(content, 4096)
-		}
-		return content, nil
-	}
-
-	return "", fmt.Errorf("no choices returned in OpenAI response")
+	return nil
 }
@@ -0,0 +1,324 @@
+// internal/api/api_requests_test.go
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// choice is one completion a test server returns.
+type choice struct {
+	content      string
+	finishReason string
+}
+
+// newTestHandler returns a handler for a server that answers each completion request with the next choice.
+func newTestHandler(t *testing.T, choices []choice) (*APIHandler, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(choices) {
+			n = len(choices)
+		}
+		c := choices[n-1]
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q},"finish_reason":%q}]}`, c.content, c.finishReason)
+	}))
+	t.Cleanup(server.Close)
+	return NewAPIHandler("key", server.URL), &requests
+}
+
+func TestCompleteWithModelFinishReasons(t *testing.T) {
+	tests := []struct {
+		name             string
+		choices          []choice
+		maxContinuations int
+		want             string
+		wantErr          error
+		wantRequests     int32
+	}{
+		{
+			name:         "stop returns the answer",
+			choices:      []choice{{"Use a drop shot.", "stop"}},
+			want:         "Use a drop shot.",
+			wantRequests: 1,
+		},
+		{
+			name:         "length appends the truncation notice",
+			choices:      []choice{{"Use a drop", "length"}},
+			want:         "Use a drop" + DefaultTruncationNotice,
+			wantRequests: 1,
+		},
+		{
+			name:             "length is continued when allowed",
+			choices:          []choice{{"Use a drop", "length"}, {" shot.", "stop"}},
+			maxContinuations: 1,
+			want:             "Use a drop shot.",
+			wantRequests:     2,
+		},
+		{
+			name:             "notice once continuations run out",
+			choices:          []choice{{"Use", "length"}, {" a drop", "length"}},
+			maxContinuations: 1,
+			want:             "Use a drop" + DefaultTruncationNotice,
+			wantRequests:     2,
+		},
+		{
+			name:         "content filter is reported",
+			choices:      []choice{{"", "content_filter"}},
+			wantErr:      ErrContentFiltered,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, requests := newTestHandler(t, tt.choices)
+			api.MaxContinuations = tt.maxContinuations
+
+			got, err := api.CompleteWithModel(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best rig for bass?"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("answer = %q, want %q", got, tt.want)
+			}
+			if n := atomic.LoadInt32(requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// response is one reply a retry test server sends.
+type response struct {
+	status     int
+	retryAfter string
+}
+
+func TestQueryOpenAIRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []response
+		timeout      time.Duration
+		wantErr      bool
+		wantStatus   int // Status of the returned APIError
+		wantRequests int32
+	}{
+		{"429 then 200", []response{{http.StatusTooManyRequests, ""}, {http.StatusOK, ""}}, time.Second, false, 0, 2},
+		{"503 then 200", []response{{http.StatusServiceUnavailable, ""}, {http.StatusOK, ""}}, time.Second, false, 0, 2},
+		{"client errors are not retried", []response{{http.StatusBadRequest, ""}}, time.Second, true, http.StatusBadRequest, 1},
+		{"gives up after MaxRetries", []response{{http.StatusTooManyRequests, ""}}, time.Second, true, http.StatusTooManyRequests, DefaultMaxRetries + 1},
+		{"Retry-After past the deadline stops retrying", []response{{http.StatusTooManyRequests, "60"}, {http.StatusOK, ""}}, 200 * time.Millisecond, true, http.StatusTooManyRequests, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&requests, 1))
+				if n > len(tt.responses) {
+					n = len(tt.responses)
+				}
+				resp := tt.responses[n-1]
+				if resp.retryAfter != "" {
+					w.Header().Set("Retry-After", resp.retryAfter)
+				}
+				if resp.status != http.StatusOK {
+					w.WriteHeader(resp.status)
+					return
+				}
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Use a jig."},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+
+			api := NewAPIHandler("key", server.URL)
+			api.RetryBaseDelay = time.Millisecond
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			answer, err := api.CompleteWithModel(ctx, "", []types.OpenAIMessage{{Role: "user", Content: "Best rig for bass?"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && answer != "Use a jig." {
+				t.Errorf("answer = %q", answer)
+			}
+			var apiErr *types.APIError
+			if tt.wantErr && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus) {
+				t.Errorf("error = %v, want an APIError with status %d", err, tt.wantStatus)
+			}
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout {
+				t.Errorf("took %v, longer than the %v deadline", elapsed, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"absent", "", 0, 0},
+		{"seconds", "3", 3 * time.Second, 3 * time.Second},
+		{"invalid", "soon", 0, 0},
+		{"HTTP date", time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{"date in the past", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header); got < tt.min || got > tt.max {
+				t.Errorf("retryAfter(%q) = %v, want between %v and %v", tt.header, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestEmptyChoicesAreRetried(t *testing.T) {
+	const empty = `{"choices":[]}`
+	const valid = `{"choices":[{"message":{"role":"assistant","content":"Use a jig."},"finish_reason":"stop"}]}`
+	tests := []struct {
+		name         string
+		bodies       []string
+		retries      int
+		want         string
+		wantErr      error
+		wantRequests int32
+	}{
+		{"empty then valid", []string{empty, valid}, DefaultEmptyChoiceRetries, "Use a jig.", nil, 2},
+		{"persistently empty", []string{empty}, DefaultEmptyChoiceRetries, "", ErrNoChoices, 2},
+		{"retries disabled", []string{empty, valid}, 0, "", ErrNoChoices, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&requests, 1))
+				if n > len(tt.bodies) {
+					n = len(tt.bodies)
+				}
+				fmt.Fprint(w, tt.bodies[n-1])
+			}))
+			defer server.Close()
+
+			api := NewAPIHandler("key", server.URL)
+			api.EmptyChoiceRetries = tt.retries
+
+			got, err := api.CompleteWithModel(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best rig for bass?"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("answer = %q, want %q", got, tt.want)
+			}
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestSamplingSettingsAreSent(t *testing.T) {
+	tests := []struct {
+		name            string
+		model           string // Model passed to CompleteWithModel; empty uses the handler's
+		handlerModel    string
+		temperature     float64
+		maxTokens       int
+		wantModel       string
+		wantTemperature float64
+		wantMaxTokens   int
+	}{
+		{"defaults", "", DefaultModel, DefaultTemperature, DefaultMaxTokens, DefaultModel, DefaultTemperature, DefaultMaxTokens},
+		{"configured", "", "gpt-4o", 0.2, 1024, "gpt-4o", 0.2, 1024},
+		{"per-call model", "gpt-4.1-mini", "gpt-4o", 1.5, 256, "gpt-4.1-mini", 1.5, 256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Model       string  `json:"model"`
+				Temperature float64 `json:"temperature"`
+				MaxTokens   int     `json:"max_tokens"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Use a jig."},"finish_reason":"stop"}]}`)
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("key", server.URL)
+			handler.Model = tt.handlerModel
+			handler.Temperature = tt.temperature
+			handler.MaxTokens = tt.maxTokens
+
+			if _, err := handler.CompleteWithModel(context.Background(), tt.model, []types.OpenAIMessage{{Role: "user", Content: "Best lure?"}}); err != nil {
+				t.Fatalf("CompleteWithModel error = %v", err)
+			}
+			if got.Model != tt.wantModel || got.Temperature != tt.wantTemperature || got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("sent model %q, temperature %g, max_tokens %d; want %q, %g, %d",
+					got.Model, got.Temperature, got.MaxTokens, tt.wantModel, tt.wantTemperature, tt.wantMaxTokens)
+			}
+		})
+	}
+}
+
+func TestContinuationRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		secondStatus int
+		want         string
+	}{
+		{"continuation completes the answer", http.StatusOK, "Use a drop shot."},
+		{"failed continuation keeps the partial answer", http.StatusBadRequest, "Use a drop" + DefaultTruncationNotice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests [][]types.OpenAIMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var query types.OpenAIQuery
+				json.NewDecoder(r.Body).Decode(&query)
+				requests = append(requests, query.Messages)
+				if len(requests) == 1 {
+					fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Use a drop"},"finish_reason":"length"}]}`)
+					return
+				}
+				w.WriteHeader(tt.secondStatus)
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":" shot."},"finish_reason":"stop"}]}`)
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("key", server.URL)
+			handler.MaxContinuations = 1
+
+			got, err := handler.CompleteWithModel(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best rig for bass?"}})
+			if err != nil {
+				t.Fatalf("CompleteWithModel error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("answer = %q, want %q", got, tt.want)
+			}
+			if len(requests) != 2 {
+				t.Fatalf("sent %d requests, want 2", len(requests))
+			}
+			// The continuation carries the cut-off answer and asks the model to go on
+			continued := requests[1]
+			if len(continued) != 3 || continued[1].Role != "assistant" || continued[1].Content != "Use a drop" ||
+				continued[2].Role != "user" || continued[2].Content != continuationPrompt {
+				t.Errorf("continuation messages = %+v", continued)
+			}
+		})
+	}
+}
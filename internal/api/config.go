@@ -0,0 +1,42 @@
+// internal/api/config.go
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultOpenAIEndpoint is used when OPENAI_ENDPOINT is not set
+const DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+
+// ConfigError reports a misconfigured OpenAI setting
+type ConfigError struct {
+	Setting string
+	Value   string
+	Reason  string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Setting, e.Value, e.Reason)
+}
+
+// ValidateEndpoint checks that endpoint is an absolute http(s) URL with a host
+func ValidateEndpoint(endpoint string) error {
+	if strings.TrimSpace(endpoint) == "" {
+		return &ConfigError{Setting: "OPENAI_ENDPOINT", Value: endpoint, Reason: "endpoint is empty"}
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return &ConfigError{Setting: "OPENAI_ENDPOINT", Value: endpoint, Reason: err.Error()}
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return &ConfigError{Setting: "OPENAI_ENDPOINT", Value: endpoint, Reason: "scheme must be http or https"}
+	}
+	if parsed.Host == "" {
+		return &ConfigError{Setting: "OPENAI_ENDPOINT", Value: endpoint, Reason: "host is missing"}
+	}
+	return nil
+}
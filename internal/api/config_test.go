@@ -0,0 +1,74 @@
+// internal/api/config_test.go
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		wantErr    bool
+		wantReason string // Expected ConfigError reason; empty accepts any
+	}{
+		{"default", DefaultOpenAIEndpoint, false, ""},
+		{"local proxy", "http://localhost:8080/v1", false, ""},
+		{"empty", "  ", true, "endpoint is empty"},
+		{"missing scheme", "api.openai.com/v1", true, "scheme must be http or https"},
+		{"wrong scheme", "ftp://api.openai.com/v1", true, "scheme must be http or https"},
+		{"missing host", "https:///v1", true, "host is missing"},
+		{"unparsable", "https://api openai.com/%zz", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEndpoint(tt.endpoint)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("ValidateEndpoint(%q) error = %v", tt.endpoint, err)
+				}
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || configErr.Setting != "OPENAI_ENDPOINT" {
+				t.Fatalf("ValidateEndpoint(%q) error = %v, want an OPENAI_ENDPOINT ConfigError", tt.endpoint, err)
+			}
+			if tt.wantReason != "" && configErr.Reason != tt.wantReason {
+				t.Errorf("ValidateEndpoint(%q) reason = %q, want %q", tt.endpoint, configErr.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestInvalidEndpointFailsWithConfigError(t *testing.T) {
+	handler := NewAPIHandler("key", "api.openai.com/v1")
+	messages := []types.OpenAIMessage{{Role: "user", Content: "Best bait for bass?"}}
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"CompleteWithModel", func() error {
+			_, err := handler.CompleteWithModel(context.Background(), "gpt-4o", messages)
+			return err
+		}},
+		{"CompleteStream", func() error {
+			_, err := handler.CompleteStream(context.Background(), "gpt-4o", messages, func(string) {})
+			return err
+		}},
+		{"Ping", func() error { return handler.Ping(context.Background()) }},
+	}
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			var configErr *ConfigError
+			if err := tt.call(); !errors.As(err, &configErr) {
+				t.Errorf("%s() error = %v, want a *ConfigError", tt.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// internal/api/debug.go
+
+package api
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+// DefaultDebugMaxBytes caps each request or response body written by the debug log
+const DefaultDebugMaxBytes = 4000
+
+// Patterns redacted from the debug log
+var (
+	debugSecretPattern = regexp.MustCompile(`(?i)\b(sk-[a-z0-9_-]{8,}|\d{6,}:[a-z0-9_-]{30,})\b`)
+	debugEmailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	debugPhonePattern  = regexp.MustCompile(`\+?\d[\d\s().-]{7,}\d`)
+)
+
+// debugLog writes an OpenAI request or response body to the log when DebugLog is set. API keys and
+// tokens are always redacted, email addresses and phone numbers too when DebugMaskPII is set, and the
+// body is cut to DebugMaxBytes.
+func (api *APIHandler) debugLog(kind, model string, body []byte) {
+	if !api.DebugLog {
+		return
+	}
+	log.Printf("OpenAI %s (%s): %s", kind, model, api.redact(string(body)))
+}
+
+// redact prepares text for the debug log.
+func (api *APIHandler) redact(text string) string {
+	if api.OpenAIKey != "" {
+		text = strings.ReplaceAll(text, api.OpenAIKey, "[REDACTED]")
+	}
+	text = debugSecretPattern.ReplaceAllString(text, "[REDACTED]")
+	if api.DebugMaskPII {
+		text = debugEmailPattern.ReplaceAllString(text, "[EMAIL]")
+		text = debugPhonePattern.ReplaceAllString(text, "[PHONE]")
+	}
+	if limit := api.DebugMaxBytes; limit > 0 && len(text) > limit {
+		text = text[:limit] + "… (truncated)"
+	}
+	return text
+}
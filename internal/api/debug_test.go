@@ -0,0 +1,83 @@
+// internal/api/debug_test.go
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		maskPII  bool
+		maxBytes int
+		text     string
+		want     string
+	}{
+		{"configured key", false, 0, "Bearer my-secret-key", "Bearer [REDACTED]"},
+		{"OpenAI-style key", false, 0, "key sk-abcdef123456 used", "key [REDACTED] used"},
+		{"Telegram bot token", false, 0, "token 123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw0 leaked", "token [REDACTED] leaked"},
+		{"PII masked", true, 0, "Mail jane@example.com or call +1 (555) 123-4567.", "Mail [EMAIL] or call [PHONE]."},
+		{"PII kept when unmasked", false, 0, "Mail jane@example.com", "Mail jane@example.com"},
+		{"truncated", false, 10, "Fish the seams below the riffle.", "Fish the s… (truncated)"},
+		{"no cap", false, 0, "Fish the seams below the riffle.", "Fish the seams below the riffle."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewAPIHandler("my-secret-key", "https://api.openai.com/v1")
+			api.DebugMaskPII = tt.maskPII
+			api.DebugMaxBytes = tt.maxBytes
+			if got := api.redact(tt.text); got != tt.want {
+				t.Errorf("redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{"enabled", true},
+		{"disabled", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Email jane@example.com"},"finish_reason":"stop"}]}`)
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("sk-live-secret-key", server.URL)
+			handler.DebugLog = tt.enabled
+
+			var logs bytes.Buffer
+			defer log.SetOutput(log.Writer())
+			log.SetOutput(&logs)
+
+			if _, err := handler.CompleteWithModel(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best lure?"}}); err != nil {
+				t.Fatalf("CompleteWithModel error = %v", err)
+			}
+
+			output := logs.String()
+			if got := strings.Contains(output, "OpenAI request") && strings.Contains(output, "Best lure?"); got != tt.enabled {
+				t.Errorf("request logged = %v, want %v", got, tt.enabled)
+			}
+			if got := strings.Contains(output, "OpenAI response"); got != tt.enabled {
+				t.Errorf("response logged = %v, want %v", got, tt.enabled)
+			}
+			if strings.Contains(output, "sk-live-secret-key") || strings.Contains(output, "jane@example.com") {
+				t.Errorf("debug log leaked the key or an email address: %s", output)
+			}
+		})
+	}
+}
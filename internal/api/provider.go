@@ -0,0 +1,53 @@
+// internal/api/provider.go
+
+package api
+
+import (
+	"context"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// LLMProvider answers chat completions. *APIHandler is the default implementation, backed by OpenAI;
+// an alternative can be supplied to run the bot against another model host without changing call sites.
+type LLMProvider interface {
+	// Complete returns the answer to messages from the provider's default model.
+	Complete(ctx context.Context, messages []types.OpenAIMessage) (string, error)
+	// CompleteWithModel returns the answer from the named model, or from the default model when model is empty.
+	CompleteWithModel(ctx context.Context, model string, messages []types.OpenAIMessage) (string, error)
+	// ModelName returns the default model, which is recorded with each answer.
+	ModelName() string
+}
+
+// StreamingProvider is implemented by providers that can stream an answer as it is generated.
+type StreamingProvider interface {
+	CompleteStream(ctx context.Context, model string, messages []types.OpenAIMessage, onDelta func(delta string)) (string, error)
+}
+
+// Transcriber is implemented by providers that can turn speech into text.
+type Transcriber interface {
+	TranscribeAudio(ctx context.Context, data []byte) (string, error)
+}
+
+// Pinger is implemented by providers that can check they are reachable without generating an answer.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Compile-time checks that APIHandler provides every capability
+var (
+	_ LLMProvider       = (*APIHandler)(nil)
+	_ StreamingProvider = (*APIHandler)(nil)
+	_ Transcriber       = (*APIHandler)(nil)
+	_ Pinger            = (*APIHandler)(nil)
+)
+
+// Complete implements LLMProvider using the configured model.
+func (api *APIHandler) Complete(ctx context.Context, messages []types.OpenAIMessage) (string, error) {
+	return api.CompleteWithModel(ctx, api.Model, messages)
+}
+
+// ModelName implements LLMProvider.
+func (api *APIHandler) ModelName() string {
+	return api.Model
+}
@@ -0,0 +1,162 @@
+// internal/api/stream.go
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// streamDone is the data of the server-sent event that ends a streamed response.
+const streamDone = "[DONE]"
+
+// QueryOpenAIStream sends a streaming request to OpenAI using the configured model, calling onDelta with each
+// piece of content as it arrives, and returns the complete response text.
+func (api *APIHandler) QueryOpenAIStream(messages []types.OpenAIMessage, onDelta func(delta string)) (string, error) {
+	return api.QueryOpenAIStreamWithModel(api.Model, messages, onDelta)
+}
+
+// QueryOpenAIStreamWithModel is QueryOpenAIStream using the given model instead of the configured one.
+// Streamed requests are not retried, so callers should fall back to QueryOpenAIWithModel on error. If the stream
+// breaks off after some content and KeepPartialStream is set, that content is returned with PartialStreamNotice.
+func (api *APIHandler) QueryOpenAIStreamWithModel(model string, messages []types.OpenAIMessage, onDelta func(delta string)) (string, error) {
+	return api.CompleteStream(context.Background(), model, messages, onDelta)
+}
+
+// CompleteStream implements StreamingProvider; it is QueryOpenAIStreamWithModel bounded by ctx as well as Deadline.
+func (api *APIHandler) CompleteStream(ctx context.Context, model string, messages []types.OpenAIMessage, onDelta func(delta string)) (string, error) {
+	if err := ValidateEndpoint(api.OpenAIEndpoint); err != nil {
+		return "", err
+	}
+	fullEndpoint := fmt.Sprintf("%s/chat/completions", strings.TrimRight(api.OpenAIEndpoint, "/"))
+	if model == "" {
+		model = api.Model
+	}
+
+	if fitted := api.fitToContext(model, messages); len(fitted) < len(messages) {
+		log.Printf("Trimmed %d oldest messages to fit the %s context window", len(messages)-len(fitted), model)
+		messages = fitted
+	}
+
+	body, err := json.Marshal(types.OpenAIQuery{
+		Model:         model,
+		Messages:      messages,
+		Temperature:   api.Temperature,
+		MaxTokens:     api.MaxTokens,
+		Stream:        true,
+		StreamOptions: &types.OpenAIStreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal OpenAI query: %w", err)
+	}
+	api.debugLog("request", model, body)
+
+	ctx, cancel := context.WithTimeout(ctx, api.Deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fullEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create OpenAI request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+api.OpenAIKey)
+
+	// The client timeout would cut off long streams; the deadline above bounds the request instead
+	client := *api.Client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making streaming request to OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", &types.APIError{Service: "OpenAI", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	content, finishReason, usage, err := ReadStream(resp.Body, onDelta)
+	api.debugLog("streamed response", model, []byte(content))
+	if usage != nil && api.OnUsage != nil {
+		api.OnUsage(model, *usage)
+	}
+	if err != nil {
+		// Keep a partial answer the user may already have seen rather than starting over
+		if !api.KeepPartialStream || strings.TrimSpace(content) == "" {
+			return "", err
+		}
+		log.Printf("OpenAI stream broke off after %d bytes, sending the partial answer: %v", len(content), err)
+		return content + api.PartialStreamNotice, nil
+	}
+	log.Printf("OpenAI finish reason: %s", finishReason)
+
+	switch finishReason {
+	case finishReasonContentFilter:
+		return "", ErrContentFiltered
+	case finishReasonLength:
+		content += api.TruncationNotice
+	}
+	if content == "" {
+		return "", ErrNoChoices
+	}
+	return content, nil
+}
+
+// ReadStream parses the server-sent events of a streamed OpenAI response, calling onDelta (when non-nil)
+// with each piece of content. It returns the concatenated content, the finish reason, and the token usage
+// when OpenAI reported it. Keep-alive comments are skipped. When the stream breaks off, the content received
+// so far is returned along with the error.
+func ReadStream(r io.Reader, onDelta func(delta string)) (content, finishReason string, usage *types.OpenAIUsage, err error) {
+	var b strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // Blank separators, ": keep-alive" comments, and other fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "" {
+			continue // Keep-alive sent as an empty data field
+		}
+		if data == streamDone {
+			return b.String(), finishReason, usage, nil
+		}
+
+		var chunk types.OpenAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return b.String(), finishReason, usage, fmt.Errorf("error unmarshalling stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if delta := choice.Delta.Content; delta != "" {
+				b.WriteString(delta)
+				if onDelta != nil {
+					onDelta(delta)
+				}
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return b.String(), finishReason, usage, fmt.Errorf("error reading OpenAI stream: %w", err)
+	}
+	if finishReason != "" {
+		return b.String(), finishReason, usage, nil // Finished, but the connection closed before [DONE]
+	}
+	return b.String(), finishReason, usage, fmt.Errorf("OpenAI stream ended before the answer was finished")
+}
@@ -0,0 +1,204 @@
+// internal/api/stream_test.go
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// sseChunk returns the data line of a streamed chunk carrying delta and, when non-empty, a finish reason.
+func sseChunk(delta, finishReason string) string {
+	return fmt.Sprintf("data: {\"choices\":[{\"delta\":{\"content\":%q},\"finish_reason\":%q}]}\n\n", delta, finishReason)
+}
+
+func TestReadStream(t *testing.T) {
+	tests := []struct {
+		name             string
+		stream           string
+		wantContent      string
+		wantDeltas       []string
+		wantFinishReason string
+		wantTokens       int
+	}{
+		{
+			name:             "deltas until done",
+			stream:           sseChunk("Use a ", "") + sseChunk("drop shot.", "stop") + "data: [DONE]\n\n",
+			wantContent:      "Use a drop shot.",
+			wantDeltas:       []string{"Use a ", "drop shot."},
+			wantFinishReason: "stop",
+		},
+		{
+			name:             "keep-alives are skipped",
+			stream:           ": keep-alive\n\n" + "data:\n\n" + sseChunk("Jig.", "stop") + "event: ping\n\n" + "data: [DONE]\n\n",
+			wantContent:      "Jig.",
+			wantDeltas:       []string{"Jig."},
+			wantFinishReason: "stop",
+		},
+		{
+			name:             "empty deltas are not reported",
+			stream:           sseChunk("", "") + sseChunk("Jig.", "") + sseChunk("", "length") + "data: [DONE]\n\n",
+			wantContent:      "Jig.",
+			wantDeltas:       []string{"Jig."},
+			wantFinishReason: "length",
+		},
+		{
+			name:             "usage on the final chunk",
+			stream:           sseChunk("Jig.", "stop") + "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n" + "data: [DONE]\n\n",
+			wantContent:      "Jig.",
+			wantDeltas:       []string{"Jig."},
+			wantFinishReason: "stop",
+			wantTokens:       15,
+		},
+		{
+			name:             "finished without done",
+			stream:           sseChunk("Jig.", "stop"),
+			wantContent:      "Jig.",
+			wantDeltas:       []string{"Jig."},
+			wantFinishReason: "stop",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deltas []string
+			content, finishReason, usage, err := ReadStream(strings.NewReader(tt.stream), func(delta string) { deltas = append(deltas, delta) })
+			if err != nil {
+				t.Fatalf("ReadStream error = %v", err)
+			}
+			if content != tt.wantContent || finishReason != tt.wantFinishReason {
+				t.Errorf("ReadStream = (%q, %q), want (%q, %q)", content, finishReason, tt.wantContent, tt.wantFinishReason)
+			}
+			if strings.Join(deltas, "|") != strings.Join(tt.wantDeltas, "|") {
+				t.Errorf("deltas = %q, want %q", deltas, tt.wantDeltas)
+			}
+			tokens := 0
+			if usage != nil {
+				tokens = usage.TotalTokens
+			}
+			if tokens != tt.wantTokens {
+				t.Errorf("usage total tokens = %d, want %d", tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestCompleteStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		want    string
+		wantErr error
+	}{
+		{"answer", sseChunk("Use a ", "") + sseChunk("jig.", "stop") + "data: [DONE]\n\n", "Use a jig.", nil},
+		{"token limit appends the truncation notice", sseChunk("Use a", "length") + "data: [DONE]\n\n", "Use a" + DefaultTruncationNotice, nil},
+		{"content filter", sseChunk("", "content_filter") + "data: [DONE]\n\n", "", ErrContentFiltered},
+		{"no content", sseChunk("", "stop") + "data: [DONE]\n\n", "", ErrNoChoices},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, tt.stream)
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("key", server.URL)
+
+			var streamed strings.Builder
+			got, err := handler.CompleteStream(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best lure?"}}, func(delta string) { streamed.WriteString(delta) })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteStream error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CompleteStream = %q, want %q", got, tt.want)
+			}
+			if accept != "text/event-stream" {
+				t.Errorf("Accept header = %q, want text/event-stream", accept)
+			}
+			if tt.wantErr == nil && !strings.HasPrefix(got, streamed.String()) {
+				t.Errorf("streamed %q, which the answer %q does not start with", streamed.String(), got)
+			}
+		})
+	}
+}
+
+func TestCompleteStreamReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	handler := NewAPIHandler("key", server.URL)
+
+	_, err := handler.CompleteStream(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best lure?"}}, nil)
+	var apiErr *types.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("CompleteStream error = %v, want an APIError with status 503", err)
+	}
+}
+
+func TestReadStreamBrokenOff(t *testing.T) {
+	tests := []struct {
+		name        string
+		stream      string
+		wantContent string
+	}{
+		{"closed before finishing", sseChunk("Use a ", "") + sseChunk("drop", ""), "Use a drop"},
+		{"truncated chunk", sseChunk("Use a ", "") + "data: {\"choices\":[{\"delta\":", "Use a "},
+		{"nothing received", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, _, _, err := ReadStream(strings.NewReader(tt.stream), nil)
+			if err == nil {
+				t.Fatal("ReadStream reported a broken stream as complete")
+			}
+			if content != tt.wantContent {
+				t.Errorf("content = %q, want the %q received before the break", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestCompleteStreamKeepsPartialAnswer(t *testing.T) {
+	tests := []struct {
+		name        string
+		stream      string
+		keepPartial bool
+		notice      string
+		want        string
+		wantErr     bool
+	}{
+		{"partial answer with notice", sseChunk("Use a ", "") + sseChunk("drop", ""), true, DefaultPartialStreamNotice, "Use a drop" + DefaultPartialStreamNotice, false},
+		{"partial answer without notice", sseChunk("Use a drop", ""), true, "", "Use a drop", false},
+		{"keeping partial answers disabled", sseChunk("Use a drop", ""), false, DefaultPartialStreamNotice, "", true},
+		{"nothing to keep", sseChunk("  ", ""), true, DefaultPartialStreamNotice, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, tt.stream) // The connection closes without a finish reason or [DONE]
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("key", server.URL)
+			handler.KeepPartialStream = tt.keepPartial
+			handler.PartialStreamNotice = tt.notice
+
+			got, err := handler.CompleteStream(context.Background(), "", []types.OpenAIMessage{{Role: "user", Content: "Best lure?"}}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompleteStream error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CompleteStream = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
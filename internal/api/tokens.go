@@ -0,0 +1,94 @@
+// internal/api/tokens.go
+
+package api
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// Limits used to keep requests inside the model's context window.
+const (
+	maxTokenCacheEntries = 5000 // The estimate cache is cleared once it holds this many messages
+	defaultContextTokens = 8192 // Context size assumed for models missing from modelContextTokens
+)
+
+// modelContextTokens lists the context window of the models the bot is known to use
+var modelContextTokens = map[string]int{
+	"gpt-4o-mini":   128000,
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+}
+
+// tokenEstimator estimates message token counts, caching the estimate for each distinct message.
+type tokenEstimator struct {
+	mutex sync.Mutex
+	cache map[[sha256.Size]byte]int
+}
+
+func newTokenEstimator() *tokenEstimator {
+	return &tokenEstimator{cache: make(map[[sha256.Size]byte]int)}
+}
+
+// estimate returns the approximate token count of a single message.
+func (e *tokenEstimator) estimate(message types.OpenAIMessage) int {
+	key := sha256.Sum256([]byte(message.Role + "\x00" + message.Content))
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if tokens, ok := e.cache[key]; ok {
+		return tokens
+	}
+	tokens := utils.EstimateMessageTokens(message)
+	if len(e.cache) >= maxTokenCacheEntries {
+		e.cache = make(map[[sha256.Size]byte]int)
+	}
+	e.cache[key] = tokens
+	return tokens
+}
+
+// estimateAll returns the approximate token count of a request's messages.
+func (e *tokenEstimator) estimateAll(messages []types.OpenAIMessage) int {
+	total := 0
+	for _, message := range messages {
+		total += e.estimate(message)
+	}
+	return total
+}
+
+// contextTokens returns the context window for a model, preferring the configured override.
+func (api *APIHandler) contextTokens(model string) int {
+	if api.ContextTokens > 0 {
+		return api.ContextTokens
+	}
+	if tokens, ok := modelContextTokens[model]; ok {
+		return tokens
+	}
+	return defaultContextTokens
+}
+
+// fitToContext drops the oldest turns after the system prompt until the messages plus the
+// response allowance fit in the model's context. The system prompt and latest message are always kept.
+func (api *APIHandler) fitToContext(model string, messages []types.OpenAIMessage) []types.OpenAIMessage {
+	budget := api.contextTokens(model) - api.MaxTokens
+	total := api.tokens.estimateAll(messages)
+	if total <= budget {
+		return messages
+	}
+
+	start := 0
+	if len(messages) > 0 && messages[0].Role == "system" {
+		start = 1
+	}
+	trimmed := append([]types.OpenAIMessage(nil), messages...)
+	for total > budget && len(trimmed) > start+1 {
+		total -= api.tokens.estimate(trimmed[start])
+		trimmed = append(trimmed[:start], trimmed[start+1:]...)
+	}
+	return trimmed
+}
@@ -0,0 +1,127 @@
+// internal/api/tokens_test.go
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// turn returns a message of about tokens estimated tokens.
+func turn(role string, tokens int) types.OpenAIMessage {
+	return types.OpenAIMessage{Role: role, Content: strings.Repeat("x", (tokens-4)*4)}
+}
+
+func TestFitToContext(t *testing.T) {
+	system := turn("system", 20)
+	tests := []struct {
+		name     string
+		messages []types.OpenAIMessage
+		want     int // Number of messages kept
+	}{
+		{"fits", []types.OpenAIMessage{system, turn("user", 20), turn("assistant", 20), turn("user", 20)}, 4},
+		{"oldest turns dropped", []types.OpenAIMessage{system, turn("user", 30), turn("assistant", 30), turn("user", 20)}, 3},
+		{"latest message always kept", []types.OpenAIMessage{system, turn("user", 200)}, 2},
+		{"no system prompt", []types.OpenAIMessage{turn("user", 50), turn("assistant", 30), turn("user", 30)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewAPIHandler("key", "https://openai.example.com")
+			api.ContextTokens = 100
+			api.MaxTokens = 20
+
+			got := api.fitToContext("", tt.messages)
+			if len(got) != tt.want {
+				t.Fatalf("kept %d messages, want %d", len(got), tt.want)
+			}
+			if got[len(got)-1] != tt.messages[len(tt.messages)-1] {
+				t.Error("the latest message was dropped")
+			}
+			if tt.messages[0].Role == "system" && got[0] != system {
+				t.Error("the system prompt was dropped")
+			}
+		})
+	}
+}
+
+func TestContextTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		override int
+		model    string
+		want     int
+	}{
+		{"known model", 0, "gpt-4o-mini", 128000},
+		{"unknown model", 0, "custom-model", defaultContextTokens},
+		{"override", 4000, "gpt-4o-mini", 4000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewAPIHandler("key", "https://openai.example.com")
+			api.ContextTokens = tt.override
+			if got := api.contextTokens(tt.model); got != tt.want {
+				t.Errorf("contextTokens(%q) = %d, want %d", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenEstimatorCachesEstimates(t *testing.T) {
+	e := newTokenEstimator()
+	message := turn("user", 30)
+	for i := 0; i < 3; i++ {
+		if got := e.estimate(message); got != utils.EstimateMessageTokens(message) {
+			t.Fatalf("estimate = %d, want %d", got, utils.EstimateMessageTokens(message))
+		}
+	}
+	if len(e.cache) != 1 {
+		t.Errorf("cache holds %d entries, want 1", len(e.cache))
+	}
+}
+
+func TestOverBudgetRequestIsTrimmedBeforeTheCall(t *testing.T) {
+	// The server rejects requests over the context window, like OpenAI does
+	var received []types.OpenAIMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []types.OpenAIMessage `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		received = body.Messages
+		total := 0
+		for _, m := range body.Messages {
+			total += utils.EstimateMessageTokens(m)
+		}
+		if total > 80 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"maximum context length exceeded"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Try a jig."},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	api := NewAPIHandler("key", server.URL)
+	api.ContextTokens = 100
+	api.MaxTokens = 20
+	messages := []types.OpenAIMessage{turn("system", 20), turn("user", 40), turn("assistant", 45), turn("user", 20)}
+
+	answer, err := api.CompleteWithModel(context.Background(), "", messages)
+	if err != nil {
+		t.Fatalf("CompleteWithModel failed: %v", err)
+	}
+	if answer != "Try a jig." {
+		t.Errorf("answer = %q", answer)
+	}
+	if len(received) != 2 || received[0].Role != "system" || received[1] != messages[3] {
+		t.Errorf("sent %d messages, want the system prompt and the latest question", len(received))
+	}
+}
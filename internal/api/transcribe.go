@@ -0,0 +1,81 @@
+// internal/api/transcribe.go
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// DefaultTranscriptionModel is the OpenAI model used to transcribe voice messages
+const DefaultTranscriptionModel = "whisper-1"
+
+// TranscribeAudio sends audio to OpenAI's transcription endpoint and returns the recognized text.
+// Telegram voice notes are OGG/Opus, which the endpoint accepts as-is. Cancelling ctx aborts the request.
+func (api *APIHandler) TranscribeAudio(ctx context.Context, data []byte) (string, error) {
+	if err := ValidateEndpoint(api.OpenAIEndpoint); err != nil {
+		return "", err
+	}
+	fullEndpoint := fmt.Sprintf("%s/audio/transcriptions", strings.TrimRight(api.OpenAIEndpoint, "/"))
+
+	model := api.TranscriptionModel
+	if model == "" {
+		model = DefaultTranscriptionModel
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("model", model); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	file, err := form.CreateFormFile("file", "voice.ogg")
+	if err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, api.Deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fullEndpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+api.OpenAIKey)
+
+	resp, err := api.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making transcription request to OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading transcription response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &types.APIError{Service: "OpenAI transcription", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return "", fmt.Errorf("error unmarshalling transcription response: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
@@ -0,0 +1,65 @@
+// internal/api/transcribe_test.go
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestTranscribeAudio(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		status    int
+		body      string
+		want      string
+		wantModel string
+		wantErr   bool
+	}{
+		{"default model", "", http.StatusOK, `{"text":" Best bait for bass? "}`, "Best bait for bass?", DefaultTranscriptionModel, false},
+		{"configured model", "gpt-4o-transcribe", http.StatusOK, `{"text":"Best bait for bass?"}`, "Best bait for bass?", "gpt-4o-transcribe", false},
+		{"server error", "", http.StatusInternalServerError, `{"error":"boom"}`, "", DefaultTranscriptionModel, true},
+		{"malformed response", "", http.StatusOK, `not json`, "", DefaultTranscriptionModel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotModel string
+			var gotAudio []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotModel = r.FormValue("model")
+				if file, _, err := r.FormFile("file"); err == nil {
+					gotAudio, _ = io.ReadAll(file)
+					file.Close()
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(server.Close)
+			handler := NewAPIHandler("key", server.URL)
+			handler.TranscriptionModel = tt.model
+
+			got, err := handler.TranscribeAudio(context.Background(), []byte("OggS voice"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranscribeAudio error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TranscribeAudio = %q, want %q", got, tt.want)
+			}
+			if gotPath != "/audio/transcriptions" || gotModel != tt.wantModel || string(gotAudio) != "OggS voice" {
+				t.Errorf("sent path %q, model %q, audio %q", gotPath, gotModel, gotAudio)
+			}
+			var apiErr *types.APIError
+			if tt.status != http.StatusOK && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.status) {
+				t.Errorf("error = %v, want an APIError with status %d", err, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// internal/app/answer_cache_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestAnswerCacheKey(t *testing.T) {
+	system := types.OpenAIMessage{Role: "system", Content: "You are ReelTalkBot."}
+	question := types.OpenAIMessage{Role: "user", Content: "Best bait for bass?"}
+	base := []types.OpenAIMessage{system, question}
+
+	tests := []struct {
+		name     string
+		messages []types.OpenAIMessage
+		wantSame bool
+	}{
+		{"identical conversation", []types.OpenAIMessage{system, question}, true},
+		{"trivially different phrasing", []types.OpenAIMessage{system, {Role: "user", Content: "  best BAIT for bass "}}, true},
+		{"different question", []types.OpenAIMessage{system, {Role: "user", Content: "Best bait for trout?"}}, false},
+		{"earlier turns", []types.OpenAIMessage{system, {Role: "user", Content: "I'm on Lake Erie."}, {Role: "assistant", Content: "Nice!"}, question}, false},
+		{"different system prompt", []types.OpenAIMessage{{Role: "system", Content: "Always respond in Spanish."}, question}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := answerCacheKey(tt.messages) == answerCacheKey(base); same != tt.wantSame {
+				t.Errorf("key matches the base conversation's = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestAnswerCacheMissesOnDifferentContext(t *testing.T) {
+	a := newTestApp(t)
+	a.AnswerCache = cache.NewCache()
+	question := types.OpenAIMessage{Role: "user", Content: "What depth should I fish?"}
+	conversations := [][]types.OpenAIMessage{
+		{{Role: "system", Content: "You are ReelTalkBot."}, {Role: "user", Content: "I'm ice fishing for walleye."}, {Role: "assistant", Content: "Great."}, question},
+		{{Role: "system", Content: "You are ReelTalkBot."}, {Role: "user", Content: "I'm trolling for salmon."}, {Role: "assistant", Content: "Great."}, question},
+		{{Role: "system", Content: "You are ReelTalkBot."}, {Role: "user", Content: "I'm ice fishing for walleye."}, {Role: "assistant", Content: "Great."}, question},
+	}
+	wantModels := []string{"fake-model", "fake-model", cachedAnswerModel}
+
+	for i, messages := range conversations {
+		if _, model, err := a.queryOpenAI(context.Background(), 0, messages); err != nil || model != wantModels[i] {
+			t.Errorf("query %d answered by %q (error %v), want %q", i, model, err, wantModels[i])
+		}
+	}
+	if got := a.llm.callCount(); got != 2 {
+		t.Errorf("OpenAI was called %d times, want 2", got)
+	}
+}
+
+func TestAnswerCacheTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wait      time.Duration
+		wantCalls int
+	}{
+		{"hit within the TTL", time.Hour, 0, 1},
+		{"expired answer is asked again", 10 * time.Millisecond, 20 * time.Millisecond, 2},
+		{"zero TTL never expires", 0, 20 * time.Millisecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.AnswerCache = cache.NewCache()
+			a.AnswerCacheTTL = tt.ttl
+			messages := []types.OpenAIMessage{{Role: "system", Content: "You are ReelTalkBot."}, {Role: "user", Content: "Best bait for bass?"}}
+
+			if _, _, err := a.queryOpenAI(context.Background(), 0, messages); err != nil {
+				t.Fatalf("first query error = %v", err)
+			}
+			time.Sleep(tt.wait)
+			answer, _, err := a.queryOpenAI(context.Background(), 0, messages)
+			if err != nil {
+				t.Fatalf("second query error = %v", err)
+			}
+			if answer != "Answer to: Best bait for bass?" {
+				t.Errorf("answer = %q", answer)
+			}
+			if got := a.llm.callCount(); got != tt.wantCalls {
+				t.Errorf("OpenAI was called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// internal/app/app_test.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/conversation"
+	s3client "ReelTalkBot-Go/internal/s3"
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/usage"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// fakeLLM is an LLM provider that answers from a function instead of calling OpenAI.
+type fakeLLM struct {
+	mutex  sync.Mutex
+	answer func(messages []types.OpenAIMessage) (string, error) // Defaults to echoing the question
+	calls  [][]types.OpenAIMessage
+	models []string
+}
+
+func (f *fakeLLM) Complete(ctx context.Context, messages []types.OpenAIMessage) (string, error) {
+	return f.CompleteWithModel(ctx, "", messages)
+}
+
+func (f *fakeLLM) CompleteWithModel(ctx context.Context, model string, messages []types.OpenAIMessage) (string, error) {
+	f.mutex.Lock()
+	f.calls = append(f.calls, append([]types.OpenAIMessage(nil), messages...))
+	f.models = append(f.models, model)
+	answer := f.answer
+	f.mutex.Unlock()
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if answer == nil {
+		return "Answer to: " + messages[len(messages)-1].Content, nil
+	}
+	return answer(messages)
+}
+
+func (f *fakeLLM) ModelName() string {
+	return "fake-model"
+}
+
+// callCount returns the number of completions requested.
+func (f *fakeLLM) callCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.calls)
+}
+
+// lastCall returns the messages of the latest completion request.
+func (f *fakeLLM) lastCall() []types.OpenAIMessage {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.calls) == 0 {
+		return nil
+	}
+	return f.calls[len(f.calls)-1]
+}
+
+// streamingLLM is a fakeLLM that streams its answer as a single delta.
+type streamingLLM struct {
+	*fakeLLM
+}
+
+func (f streamingLLM) CompleteStream(ctx context.Context, model string, messages []types.OpenAIMessage, onDelta func(delta string)) (string, error) {
+	answer, err := f.CompleteWithModel(ctx, model, messages)
+	if err == nil {
+		onDelta(answer)
+	}
+	return answer, err
+}
+
+// telegramCall is a Bot API request captured by fakeTelegram.
+type telegramCall struct {
+	Method  string
+	Payload map[string]interface{}
+}
+
+// fakeTelegram stands in for the Telegram Bot API and records every request.
+type fakeTelegram struct {
+	mutex   sync.Mutex
+	calls   []telegramCall
+	nextID  int
+	respond func(method string, payload map[string]interface{}) (int, string) // Optional override of the reply
+}
+
+func (f *fakeTelegram) RoundTrip(r *http.Request) (*http.Response, error) {
+	// Like a real transport, a request whose context is already done is never sent
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	payload := map[string]interface{}{}
+	if r.Body != nil {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+	}
+
+	f.mutex.Lock()
+	f.calls = append(f.calls, telegramCall{Method: method, Payload: payload})
+	f.nextID++
+	id := f.nextID
+	respond := f.respond
+	f.mutex.Unlock()
+
+	status, body := http.StatusOK, `{"ok":true,"result":true}`
+	if method == "sendMessage" {
+		body = fmt.Sprintf(`{"ok":true,"result":{"message_id":%d}}`, 1000+id)
+	}
+	if respond != nil {
+		if s, b := respond(method, payload); s != 0 {
+			status, body = s, b
+		}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    r,
+	}, nil
+}
+
+// sent returns the requests made to a Bot API method.
+func (f *fakeTelegram) sent(method string) []telegramCall {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var calls []telegramCall
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// texts returns the text of every message sent with sendMessage.
+func (f *fakeTelegram) texts() []string {
+	var texts []string
+	for _, call := range f.sent("sendMessage") {
+		text, _ := call.Payload["text"].(string)
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// fakeS3 is an in-memory S3 bucket.
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	getErr  error // Returned by GetObject when set
+	puts    int
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string][]byte)}
+}
+
+func (f *fakeS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	data, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.objects[aws.StringValue(input.Key)] = data
+	f.puts++
+	return &s3.PutObjectOutput{}, nil
+}
+
+// keys returns the keys of the stored objects.
+func (f *fakeS3) keys() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// object returns a stored object's contents.
+func (f *fakeS3) object(key string) ([]byte, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	data, ok := f.objects[key]
+	return data, ok
+}
+
+// testApp bundles an App with the fakes standing in for its external services.
+type testApp struct {
+	*App
+	llm      *fakeLLM
+	telegram *fakeTelegram
+	store    *fakeS3
+}
+
+// newTestApp returns an App with default settings whose OpenAI, Telegram, and S3 calls go to fakes.
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	llm := &fakeLLM{}
+	telegram := &fakeTelegram{}
+	store := newFakeS3()
+	a := &App{
+		TelegramToken:        "test-token",
+		Cache:                cache.NewCache(),
+		HTTPClient:           &http.Client{Transport: telegram},
+		S3Client:             store,
+		Logger:               s3client.NewS3Logger(store, "test-bucket", "", 1000, 0),
+		UsageCache:           usage.NewDefaultUsageCache(),
+		NoLimitUsers:         make(map[int]struct{}),
+		startedAt:            time.Now(),
+		ConversationContexts: conversation.NewConversationCache(),
+		LLM:                  llm,
+		promptMap:            make(map[string]string),
+		ContentFilterMessage: defaultContentFilterMessage,
+		chatLanguages:        make(map[int64]string),
+		chatPrompts:          make(map[int64]string),
+		businessRoutes:       make(map[string]businessRoute),
+		CitationsEnabled:     true,
+		CitationTemplate:     defaultCitationTemplate,
+		SplitReplyMode:       splitReplyFirst,
+		rateLimitNotices:     make(map[int64]time.Time),
+		now:                  time.Now,
+		SystemPrompt:         defaultSystemPrompt,
+		callbackPresses:      make(map[string]time.Time),
+		replyMessages:        cache.NewCache(),
+	}
+	t.Cleanup(func() {
+		a.Logger.Close()
+		a.ConversationContexts.Close()
+	})
+	return &testApp{App: a, llm: llm, telegram: telegram, store: store}
+}
+
+// usedMessages returns how many messages the user has been charged in the current window.
+func (a *testApp) usedMessages(userID int) int {
+	remaining, _ := a.UsageCache.RemainingMessages(userID)
+	return usage.DefaultLimit - remaining
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
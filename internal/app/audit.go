@@ -0,0 +1,83 @@
+// internal/app/audit.go
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// auditKeyPrefix keeps admin audit records apart from the usage logs.
+const auditKeyPrefix = "audit/"
+
+// maxAuditArgsLength caps the characters of the arguments stored in an audit record.
+const maxAuditArgsLength = 200
+
+// secretPattern matches values that look like API keys or tokens so they are never written to the audit log.
+var secretPattern = regexp.MustCompile(`(?i)\b(sk-[a-z0-9_-]{8,}|\d{6,}:[a-z0-9_-]{30,}|[a-z0-9_-]{32,})\b`)
+
+// auditRecord describes a single admin command invocation.
+type auditRecord struct {
+	Time     string `json:"time"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	ChatID   int64  `json:"chat_id"`
+	Command  string `json:"command"`
+	Args     string `json:"args"`
+	Outcome  string `json:"outcome"`
+}
+
+// auditAdminCommand writes an admin command invocation to its own S3 object under audit/, namespaced by
+// BOT_INSTANCE_ID like the usage logs. Each record gets a unique key, so concurrent admin actions never overwrite each other.
+func (a *App) auditAdminCommand(message *types.TelegramMessage, userID int, username, command, args, outcome string) {
+	if !a.AdminAuditEnabled || a.S3BucketName == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	record := auditRecord{
+		Time:     now.Format(time.RFC3339),
+		UserID:   userID,
+		Username: username,
+		ChatID:   message.Chat.ID,
+		Command:  strings.SplitN(command, "@", 2)[0],
+		Args:     sanitizeAuditArgs(args),
+		Outcome:  outcome,
+	}
+	body, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Failed to marshal audit record: %v", err)
+		return
+	}
+
+	key := a.namespacedKey(fmt.Sprintf("%s%s/%d-%d-%d.json", auditKeyPrefix, now.Format("2006/01/02"), now.UnixNano(), userID, message.MessageID))
+	_, err = a.S3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(a.S3BucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		log.Printf("Failed to write audit record %s: %v", key, err)
+	}
+}
+
+// sanitizeAuditArgs redacts secret-looking values and truncates long arguments to maxAuditArgsLength
+// characters, counted in runes so a multi-byte character is never split.
+func sanitizeAuditArgs(args string) string {
+	args = secretPattern.ReplaceAllString(strings.TrimSpace(args), "[REDACTED]")
+	if runes := []rune(args); len(runes) > maxAuditArgsLength {
+		args = string(runes[:maxAuditArgsLength]) + "…"
+	}
+	return args
+}
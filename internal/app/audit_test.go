@@ -0,0 +1,89 @@
+// internal/app/audit_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestSanitizeAuditArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"plain", "  Spanish ", "Spanish"},
+		{"OpenAI key", "set sk-abcdef1234567890", "set [REDACTED]"},
+		{"bot token", "123456789:AAabcdefghijklmnopqrstuvwxyz0123456", "[REDACTED]"},
+		{"long ASCII", strings.Repeat("a ", 150), strings.Repeat("a ", 100)[:maxAuditArgsLength] + "…"},
+		{"long multi-byte", strings.Repeat("é", 250), strings.Repeat("é", maxAuditArgsLength) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeAuditArgs(tt.args)
+			if got != tt.want {
+				t.Errorf("sanitizeAuditArgs() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("sanitizeAuditArgs() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestAdminCommandIsAudited(t *testing.T) {
+	tests := []struct {
+		name       string
+		instanceID string
+		wantPrefix string
+	}{
+		{"single instance", "", "audit/"},
+		{"namespaced by instance", "bot2", "bot2:audit/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.AdminAuditEnabled = true
+			a.S3BucketName = "test-bucket"
+			a.InstanceID = tt.instanceID
+			message := &types.TelegramMessage{
+				MessageID: 10,
+				Text:      "/language Spanish",
+				Chat:      types.TelegramChat{ID: 1, Type: "private"},
+				From:      types.TelegramUser{ID: 7},
+			}
+
+			if _, err := a.HandleCommand(context.Background(), message, 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand() error = %v", err)
+			}
+
+			var auditKeys []string
+			for _, key := range a.store.keys() {
+				if strings.Contains(key, "audit/") {
+					auditKeys = append(auditKeys, key)
+				}
+			}
+			if len(auditKeys) != 1 {
+				t.Fatalf("wrote audit records %q, want one", auditKeys)
+			}
+			if !strings.HasPrefix(auditKeys[0], tt.wantPrefix) {
+				t.Errorf("audit key %q does not start with %q", auditKeys[0], tt.wantPrefix)
+			}
+			data, _ := a.store.object(auditKeys[0])
+			var record auditRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				t.Fatalf("audit record %s is not JSON: %v", data, err)
+			}
+			want := auditRecord{Time: record.Time, UserID: 7, Username: "angler", ChatID: 1, Command: "/language", Args: "Spanish", Outcome: "ok"}
+			if record != want || record.Time == "" {
+				t.Errorf("audit record = %+v, want %+v", record, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// internal/app/autodelete.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// sentMessageResponse is the part of Telegram's sendMessage response carrying the sent message.
+type sentMessageResponse struct {
+	OK     bool                  `json:"ok"`
+	Result types.TelegramMessage `json:"result"`
+}
+
+// parseAutoDeleteChats parses comma-separated chatID=TTL pairs, e.g. "-1001234=10m,5678=0".
+// A TTL of 0 disables auto-delete for that chat even when AUTO_DELETE_TTL is set.
+func parseAutoDeleteChats(raw string) map[int64]time.Duration {
+	ttls := make(map[int64]time.Duration)
+	for _, pair := range strings.Split(raw, ",") {
+		idRaw, ttlRaw, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		chatID, err := strconv.ParseInt(strings.TrimSpace(idRaw), 10, 64)
+		if err != nil {
+			continue
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(ttlRaw))
+		if err != nil || ttl < 0 {
+			continue
+		}
+		ttls[chatID] = ttl
+	}
+	return ttls
+}
+
+// autoDeleteTTL returns how long the bot's replies in a chat are kept, or 0 to keep them.
+// The admin chat only uses an explicit per-chat TTL so alerts aren't lost to the global setting.
+func (a *App) autoDeleteTTL(chatID int64) time.Duration {
+	if ttl, ok := a.AutoDeleteChats[chatID]; ok {
+		return ttl
+	}
+	if chatID == a.AdminChatID {
+		return 0
+	}
+	return a.AutoDeleteTTL
+}
+
+// scheduleAutoDelete deletes a message the bot sent after the chat's TTL.
+func (a *App) scheduleAutoDelete(chatID int64, messageID int) {
+	ttl := a.autoDeleteTTL(chatID)
+	if ttl <= 0 {
+		return
+	}
+	if messageID == 0 {
+		log.Printf("Could not schedule auto-delete in chat %d: missing message ID in sendMessage response", chatID)
+		return
+	}
+
+	time.AfterFunc(ttl, func() {
+		if err := a.deleteMessage(chatID, messageID); err != nil {
+			log.Printf("Failed to auto-delete message %d in chat %d: %v", messageID, chatID, err)
+		}
+	})
+}
+
+// deleteMessage deletes a message sent by the bot. Messages that are already gone are not treated as errors.
+func (a *App) deleteMessage(chatID int64, messageID int) error {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/deleteMessage", a.TelegramToken)
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		// Telegram answers 400 when a user or admin already deleted the message
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(bodyBytes), "message to delete not found") {
+			return nil
+		}
+		return fmt.Errorf("unexpected status: %s - %s", resp.Status, string(bodyBytes))
+	}
+	return nil
+}
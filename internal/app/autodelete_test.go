@@ -0,0 +1,102 @@
+// internal/app/autodelete_test.go
+
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAutoDeleteChats(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[int64]time.Duration
+	}{
+		{"empty", "", map[int64]time.Duration{}},
+		{"pairs", "-1001234=10m, 5678=0", map[int64]time.Duration{-1001234: 10 * time.Minute, 5678: 0}},
+		{"invalid entries skipped", "abc=1m,42=soon,43=-1m,44", map[int64]time.Duration{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAutoDeleteChats(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAutoDeleteChats(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDeleteTTL(t *testing.T) {
+	a := newTestApp(t)
+	a.AutoDeleteTTL = time.Hour
+	a.AdminChatID = 99
+	a.AutoDeleteChats = map[int64]time.Duration{5: time.Minute, 6: 0}
+
+	tests := []struct {
+		name   string
+		chatID int64
+		want   time.Duration
+	}{
+		{"global TTL", 1, time.Hour},
+		{"per-chat override", 5, time.Minute},
+		{"per-chat opt-out", 6, 0},
+		{"admin chat kept", 99, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.autoDeleteTTL(tt.chatID); got != tt.want {
+				t.Errorf("autoDeleteTTL(%d) = %v, want %v", tt.chatID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentMessageIsDeletedAfterTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		deleteResp func(method string, payload map[string]interface{}) (int, string)
+		wantDelete bool
+	}{
+		{"deleted after the TTL", 50 * time.Millisecond, nil, true},
+		{"already deleted by a user", 50 * time.Millisecond, func(method string, payload map[string]interface{}) (int, string) {
+			if method == "deleteMessage" {
+				return http.StatusBadRequest, `{"ok":false,"description":"Bad Request: message to delete not found"}`
+			}
+			return 0, ""
+		}, true},
+		{"disabled", 0, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.AutoDeleteTTL = tt.ttl
+			a.telegram.respond = tt.deleteResp
+
+			if err := a.SendMessage(42, "Tight lines!", 0); err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+			if deletes := a.telegram.sent("deleteMessage"); len(deletes) != 0 {
+				t.Fatalf("message deleted before the TTL: %v", deletes)
+			}
+			if !tt.wantDelete {
+				time.Sleep(100 * time.Millisecond)
+				if deletes := a.telegram.sent("deleteMessage"); len(deletes) != 0 {
+					t.Fatalf("message deleted with auto-delete disabled: %v", deletes)
+				}
+				return
+			}
+
+			waitFor(t, "deleteMessage", func() bool { return len(a.telegram.sent("deleteMessage")) == 1 })
+			payload := a.telegram.sent("deleteMessage")[0].Payload
+			if payload["chat_id"] != float64(42) || payload["message_id"] != float64(1001) {
+				t.Errorf("deleteMessage payload = %v, want chat 42 message 1001", payload)
+			}
+			if err := a.deleteMessage(42, 1001); err != nil {
+				t.Errorf("deleteMessage returned %v", err)
+			}
+		})
+	}
+}
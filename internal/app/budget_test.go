@@ -0,0 +1,71 @@
+// internal/app/budget_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/budget"
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestFallbackAnswersAreNotCached(t *testing.T) {
+	tests := []struct {
+		name      string
+		degraded  bool
+		wantModel string
+		wantCalls int
+	}{
+		{"primary model answers are reused", false, "fake-model", 1},
+		{"fallback model answers are not", true, "cheap-model", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.AnswerCache = cache.NewCache()
+			a.Budget = budget.NewTracker(0.01, time.Hour, nil, nil)
+			a.BudgetFallbackModel = "cheap-model"
+			if tt.degraded {
+				a.Budget.Record("gpt-4o", types.OpenAIUsage{CompletionTokens: 1000})
+			}
+			messages := []types.OpenAIMessage{{Role: "user", Content: "Best bait for bass?"}}
+
+			_, model, err := a.queryOpenAI(context.Background(), 0, messages)
+			if err != nil {
+				t.Fatalf("queryOpenAI() error = %v", err)
+			}
+			if model != tt.wantModel {
+				t.Errorf("answered by %q, want %q", model, tt.wantModel)
+			}
+			if _, _, err := a.queryOpenAI(context.Background(), 0, messages); err != nil {
+				t.Fatalf("second queryOpenAI() error = %v", err)
+			}
+			if got := a.llm.callCount(); got != tt.wantCalls {
+				t.Errorf("OpenAI was called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachedAnswersServedWhileKBOnly(t *testing.T) {
+	a := newTestApp(t)
+	a.AnswerCache = cache.NewCache()
+	a.Budget = budget.NewTracker(0.01, time.Hour, nil, nil)
+	messages := []types.OpenAIMessage{{Role: "user", Content: "Best bait for bass?"}}
+
+	if _, _, err := a.queryOpenAI(context.Background(), 0, messages); err != nil {
+		t.Fatalf("queryOpenAI() error = %v", err)
+	}
+	a.Budget.Record("gpt-4o", types.OpenAIUsage{CompletionTokens: 1000})
+
+	if _, model, err := a.queryOpenAI(context.Background(), 0, messages); err != nil || model != cachedAnswerModel {
+		t.Errorf("queryOpenAI() = model %q, error %v; want the cached answer", model, err)
+	}
+	other := []types.OpenAIMessage{{Role: "user", Content: "Best bait for trout?"}}
+	if _, _, err := a.queryOpenAI(context.Background(), 0, other); err != budget.ErrBudgetExceeded {
+		t.Errorf("uncached question error = %v, want %v", err, budget.ErrBudgetExceeded)
+	}
+}
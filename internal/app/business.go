@@ -0,0 +1,80 @@
+// internal/app/business.go
+
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// businessRouteTTL bounds how long a business message can be replied to through its connection.
+const businessRouteTTL = time.Hour
+
+// businessRoute records the business connection a message arrived through.
+type businessRoute struct {
+	connectionID string
+	seen         time.Time
+}
+
+// businessRouteKey identifies a message within a chat.
+func businessRouteKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+// trackBusinessUpdate records business connection IDs from business updates so replies can echo them.
+// Updates from bots that aren't connected to a business account carry no business fields and are ignored.
+func (a *App) trackBusinessUpdate(update *types.TelegramUpdate) {
+	if conn := update.BusinessConnection; conn != nil {
+		log.Printf("Business connection %s for user %d enabled=%t can_reply=%t", conn.ID, conn.User.ID, conn.IsEnabled, conn.CanReply)
+		if !conn.IsEnabled || !conn.CanReply {
+			a.forgetBusinessConnection(conn.ID)
+		}
+		return
+	}
+
+	message := update.BusinessMessage
+	if message == nil {
+		message = update.EditedBusinessMessage
+	}
+	if message == nil || message.BusinessConnectionID == "" {
+		return
+	}
+
+	a.businessMutex.Lock()
+	defer a.businessMutex.Unlock()
+
+	// Prune stale routes so the map stays bounded
+	for key, route := range a.businessRoutes {
+		if time.Since(route.seen) > businessRouteTTL {
+			delete(a.businessRoutes, key)
+		}
+	}
+	a.businessRoutes[businessRouteKey(message.Chat.ID, message.MessageID)] = businessRoute{
+		connectionID: message.BusinessConnectionID,
+		seen:         time.Now(),
+	}
+}
+
+// forgetBusinessConnection drops all routes for a disabled business connection.
+func (a *App) forgetBusinessConnection(connectionID string) {
+	a.businessMutex.Lock()
+	defer a.businessMutex.Unlock()
+	for key, route := range a.businessRoutes {
+		if route.connectionID == connectionID {
+			delete(a.businessRoutes, key)
+		}
+	}
+}
+
+// businessConnectionID returns the business connection to reply through for a message, or an empty string.
+func (a *App) businessConnectionID(chatID int64, replyToMessageID int) string {
+	if replyToMessageID == 0 {
+		return ""
+	}
+	a.businessMutex.Lock()
+	defer a.businessMutex.Unlock()
+	return a.businessRoutes[businessRouteKey(chatID, replyToMessageID)].connectionID
+}
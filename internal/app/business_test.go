@@ -0,0 +1,96 @@
+// internal/app/business_test.go
+
+package app
+
+import (
+	"testing"
+
+	"ReelTalkBot-Go/internal/telegram"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// businessMessage returns a customer's business message in a private business chat.
+func businessMessage(messageID int, text, connectionID string) *types.TelegramMessage {
+	return &types.TelegramMessage{
+		MessageID:            messageID,
+		Text:                 text,
+		Chat:                 types.TelegramChat{ID: 7, Type: "private"},
+		From:                 types.TelegramUser{ID: 7},
+		BusinessConnectionID: connectionID,
+	}
+}
+
+func TestBusinessRepliesUseTheConnection(t *testing.T) {
+	tests := []struct {
+		name           string
+		updates        []*types.TelegramUpdate
+		wantReplies    int
+		wantConnection string
+	}{
+		{
+			name:           "business message",
+			updates:        []*types.TelegramUpdate{{UpdateID: 1, BusinessMessage: businessMessage(10, "Best bait for bass?", "conn-1")}},
+			wantReplies:    1,
+			wantConnection: "conn-1",
+		},
+		{
+			name:           "edited business message",
+			updates:        []*types.TelegramUpdate{{UpdateID: 1, EditedBusinessMessage: businessMessage(10, "Best bait for trout?", "conn-2")}},
+			wantReplies:    1,
+			wantConnection: "conn-2",
+		},
+		{
+			name:           "ordinary message",
+			updates:        []*types.TelegramUpdate{{UpdateID: 1, Message: businessMessage(10, "Best bait for bass?", "")}},
+			wantReplies:    1,
+			wantConnection: "",
+		},
+		{
+			name: "owner's outgoing message",
+			updates: []*types.TelegramUpdate{{UpdateID: 1, BusinessMessage: &types.TelegramMessage{
+				MessageID:            10,
+				Text:                 "I'll check and get back to you.",
+				Chat:                 types.TelegramChat{ID: 7, Type: "private"},
+				From:                 types.TelegramUser{ID: 99},
+				BusinessConnectionID: "conn-1",
+			}}},
+			wantReplies: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.TelegramHandler = telegram.NewTelegramHandler(a.App)
+
+			for _, update := range tt.updates {
+				a.HandleUpdate(update)
+			}
+
+			replies := a.telegram.sent("sendMessage")
+			if len(replies) != tt.wantReplies {
+				t.Fatalf("sent %d replies, want %d", len(replies), tt.wantReplies)
+			}
+			for _, reply := range replies {
+				got, _ := reply.Payload["business_connection_id"].(string)
+				if got != tt.wantConnection {
+					t.Errorf("reply business_connection_id = %q, want %q", got, tt.wantConnection)
+				}
+			}
+		})
+	}
+}
+
+func TestDisabledBusinessConnectionIsForgotten(t *testing.T) {
+	a := newTestApp(t)
+	a.trackBusinessUpdate(&types.TelegramUpdate{BusinessMessage: businessMessage(10, "Hi", "conn-1")})
+	a.trackBusinessUpdate(&types.TelegramUpdate{BusinessMessage: businessMessage(11, "Hi", "conn-2")})
+
+	a.trackBusinessUpdate(&types.TelegramUpdate{BusinessConnection: &types.TelegramBusinessConnection{ID: "conn-1", IsEnabled: false}})
+
+	if got := a.businessConnectionID(7, 10); got != "" {
+		t.Errorf("disabled connection still routes replies: %q", got)
+	}
+	if got := a.businessConnectionID(7, 11); got != "conn-2" {
+		t.Errorf("other connection = %q, want %q", got, "conn-2")
+	}
+}
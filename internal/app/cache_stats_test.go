@@ -0,0 +1,43 @@
+// internal/app/cache_stats_test.go
+
+package app
+
+import (
+	"testing"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/metrics"
+)
+
+func TestCacheStatsLabels(t *testing.T) {
+	a := newTestApp(t)
+	a.AnswerCache = cache.NewCache()
+	a.AnswerCache.Set("bass", "worms")
+	a.AnswerCache.Get("bass")
+	a.AnswerCache.Get("trout")
+	a.Cache.Get("missing")
+
+	stats := a.cacheStats()
+	tests := []struct {
+		name string
+		want metrics.CacheStats
+	}{
+		{"answer", metrics.CacheStats{Hits: 1, Misses: 1}},
+		{"general", metrics.CacheStats{Misses: 1}},
+		{"replies", metrics.CacheStats{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := stats[tt.name]
+			if !ok {
+				t.Fatalf("no stats for cache %q", tt.name)
+			}
+			if got != tt.want {
+				t.Errorf("stats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(stats) != len(a.namedCaches()) {
+		t.Errorf("reported %d caches, want the %d from namedCaches", len(stats), len(a.namedCaches()))
+	}
+}
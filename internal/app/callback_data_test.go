@@ -0,0 +1,52 @@
+// internal/app/callback_data_test.go
+
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallbackButton(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"short", "prompt_1", false},
+		{"at the limit", strings.Repeat("x", maxCallbackDataBytes), false},
+		{"over the limit", strings.Repeat("x", maxCallbackDataBytes+1), true},
+		{"limit counts bytes, not characters", strings.Repeat("é", maxCallbackDataBytes/2+1), true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			button, err := callbackButton("Tap", tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("callbackButton error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (button["text"] != "Tap" || button["callback_data"] != tt.data) {
+				t.Errorf("button = %v", button)
+			}
+		})
+	}
+}
+
+func TestGeneratedCallbackDataFits(t *testing.T) {
+	longText := strings.Repeat("How deep should I fish for walleye in late summer? ", 10)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"example prompt", examplePromptCallbackID(999)},
+		{"helpful vote", helpfulCallbackPrefix + kbCandidateID(longText, longText)},
+		{"follow-up", newFollowUps(3).add(longText)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := callbackButton("Tap", tt.data); err != nil {
+				t.Errorf("callback_data %q does not fit: %v", tt.data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// internal/app/callback_debounce_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestRepeatedCallbacksProcessOnce(t *testing.T) {
+	type tap struct {
+		userID int
+		data   string
+		after  time.Duration // Pause before the tap
+	}
+	tests := []struct {
+		name      string
+		debounce  time.Duration
+		taps      []tap
+		wantCalls int
+	}{
+		{"rapid identical taps", time.Minute, []tap{{7, "prompt_1", 0}, {7, "prompt_1", 0}, {7, "prompt_1", 0}}, 1},
+		{"different buttons", time.Minute, []tap{{7, "prompt_1", 0}, {7, "prompt_2", 0}}, 2},
+		{"different users", time.Minute, []tap{{7, "prompt_1", 0}, {8, "prompt_1", 0}}, 2},
+		{"tap after the window", 20 * time.Millisecond, []tap{{7, "prompt_1", 0}, {7, "prompt_1", 40 * time.Millisecond}}, 2},
+		{"debounce disabled", 0, []tap{{7, "prompt_1", 0}, {7, "prompt_1", 0}, {7, "prompt_1", 0}}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.CallbackDebounce = tt.debounce
+			a.promptMap["prompt_1"] = "Best bait for bass?"
+			a.promptMap["prompt_2"] = "Best bait for trout?"
+
+			for i, tap := range tt.taps {
+				time.Sleep(tap.after)
+				err := a.HandleCallbackQuery(context.Background(), &types.TelegramCallbackQuery{
+					ID:      "cb",
+					From:    types.TelegramUser{ID: tap.userID},
+					Message: &types.TelegramMessage{MessageID: 20 + i, Chat: types.TelegramChat{ID: -100}},
+					Data:    tap.data,
+				})
+				if err != nil {
+					t.Fatalf("HandleCallbackQuery() error = %v", err)
+				}
+			}
+
+			if got := a.llm.callCount(); got != tt.wantCalls {
+				t.Errorf("processed %d questions, want %d", got, tt.wantCalls)
+			}
+			if got := len(a.telegram.sent("answerCallbackQuery")); got != len(tt.taps) {
+				t.Errorf("acknowledged %d taps, want all %d", got, len(tt.taps))
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// internal/app/chat_prompts.go
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// chatPromptsKey is the S3 object holding the per-chat system prompt additions, before the bot instance prefix.
+const chatPromptsKey = "state/chat_prompts.json"
+
+// defaultChatPromptMaxLength bounds a chat's system prompt addition when CHAT_PROMPT_MAX_LENGTH is unset.
+const defaultChatPromptMaxLength = 500
+
+// chatPrompt returns the system prompt addition set for a chat, or an empty string if none is set.
+func (a *App) chatPrompt(chatID int64) string {
+	a.chatSettingsMutex.RLock()
+	defer a.chatSettingsMutex.RUnlock()
+	return a.chatPrompts[chatID]
+}
+
+// setChatPrompt sets the system prompt addition for a chat, or clears it when prompt is empty,
+// and saves the additions to S3 so they survive restarts.
+func (a *App) setChatPrompt(chatID int64, prompt string) error {
+	a.chatSettingsMutex.Lock()
+	if prompt == "" {
+		delete(a.chatPrompts, chatID)
+	} else {
+		a.chatPrompts[chatID] = prompt
+	}
+	body, err := json.Marshal(a.chatPrompts)
+	a.chatSettingsMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat prompts: %w", err)
+	}
+
+	if a.S3Client == nil || a.S3BucketName == "" {
+		return nil // Kept in memory only
+	}
+	_, err = a.S3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(a.S3BucketName),
+		Key:         aws.String(a.namespacedKey(chatPromptsKey)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save chat prompts: %w", err)
+	}
+	return nil
+}
+
+// loadChatPrompts reads the saved per-chat system prompt additions from S3. A missing object is not an error.
+func (a *App) loadChatPrompts() error {
+	key := a.namespacedKey(chatPromptsKey)
+	resp, err := a.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(a.S3BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	prompts := make(map[int64]string)
+	if err := json.Unmarshal(body, &prompts); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+
+	a.chatSettingsMutex.Lock()
+	defer a.chatSettingsMutex.Unlock()
+	a.chatPrompts = prompts
+	log.Printf("Loaded system prompt additions for %d chats", len(prompts))
+	return nil
+}
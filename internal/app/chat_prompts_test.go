@@ -0,0 +1,120 @@
+// internal/app/chat_prompts_test.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestChatPromptAppliesToItsChatOnly(t *testing.T) {
+	const addition = "Focus on saltwater fishing from piers."
+	tests := []struct {
+		name   string
+		chatID int64
+		want   bool
+	}{
+		{"chat with the addition", -100, true},
+		{"other chat", -200, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if err := a.setChatPrompt(-100, addition); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.processMessage(context.Background(), tt.chatID, 7, "angler", "Best bait?", 1, types.MessageMeta{}); err != nil {
+				t.Fatal(err)
+			}
+			system := a.llm.lastCall()[0]
+			if got := strings.Contains(system.Content, addition); got != tt.want {
+				t.Errorf("system prompt contains the addition = %v, want %v: %q", got, tt.want, system.Content)
+			}
+		})
+	}
+}
+
+func TestChatPromptsKeyIsNamespaced(t *testing.T) {
+	tests := []struct {
+		name       string
+		instanceID string
+		wantKey    string
+	}{
+		{"single instance", "", "state/chat_prompts.json"},
+		{"named instance", "bot-a", "bot-a:state/chat_prompts.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.S3BucketName = "test-bucket"
+			a.InstanceID = tt.instanceID
+			if err := a.setChatPrompt(-100, "Piers only."); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := a.store.object(tt.wantKey); !ok {
+				t.Fatalf("chat prompts not saved under %s; keys %v", tt.wantKey, a.store.keys())
+			}
+
+			restored := newTestApp(t)
+			restored.S3BucketName = "test-bucket"
+			restored.InstanceID = tt.instanceID
+			restored.S3Client = a.store
+			if err := restored.loadChatPrompts(); err != nil {
+				t.Fatal(err)
+			}
+			if got := restored.chatPrompt(-100); got != "Piers only." {
+				t.Errorf("restored chat prompt = %q", got)
+			}
+		})
+	}
+}
+
+func TestChatPromptCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string // The sender's getChatMember status
+		existing   string
+		text       string
+		want       string
+		wantPrompt string
+	}{
+		{"admin sets", "administrator", "", "/chatprompt Piers only.", "Prompt addition saved.", "Piers only."},
+		{"creator sets", "creator", "", "/chatprompt Piers only.", "Prompt addition saved.", "Piers only."},
+		{"member denied", "member", "Boats only.", "/chatprompt Piers only.", "Only chat administrators", "Boats only."},
+		{"off removes", "administrator", "Boats only.", "/chatprompt off", "Prompt addition removed.", ""},
+		{"too long", "administrator", "", "/chatprompt " + strings.Repeat("x", 41), "too long", ""},
+		{"shows the addition", "member", "Boats only.", "/chatprompt", "Boats only.", "Boats only."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ChatPromptMaxLength = 40
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method == "getChatMember" {
+					return http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"status":%q}}`, tt.status)
+				}
+				return 0, ""
+			}
+			if tt.existing != "" {
+				if err := a.setChatPrompt(1, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.text), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand error = %v", err)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || !strings.Contains(texts[0], tt.want) {
+				t.Errorf("replied %q, want a reply containing %q", texts, tt.want)
+			}
+			if got := a.chatPrompt(1); got != tt.wantPrompt {
+				t.Errorf("chat prompt = %q, want %q", got, tt.wantPrompt)
+			}
+		})
+	}
+}
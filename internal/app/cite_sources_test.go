@@ -0,0 +1,50 @@
+// internal/app/cite_sources_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestCiteSourcesInstruction(t *testing.T) {
+	agencies := map[string]string{"DEC": "https://dec.ny.gov/regulations", "DNR": "https://dnr.example.gov"}
+	tests := []struct {
+		name           string
+		enabled        bool
+		linkEnrichment bool
+		wantCite       bool
+		wantSites      bool
+	}{
+		{"enabled", true, false, true, false},
+		{"enabled with link enrichment", true, true, true, true},
+		{"disabled", false, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.CiteSourcesEnabled = tt.enabled
+			a.LinkEnrichment = tt.linkEnrichment
+			a.AgencyLinks = agencies
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "What is the bass season?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			sent := a.llm.lastCall()
+			if len(sent) == 0 || sent[0].Role != "system" {
+				t.Fatalf("sent %v, want a leading system message", sent)
+			}
+			system := sent[0].Content
+			if got := strings.Contains(system, citeSourcesInstruction); got != tt.wantCite {
+				t.Errorf("instruction present = %v, want %v", got, tt.wantCite)
+			}
+			wantSites := "Known official sites: DEC (https://dec.ny.gov/regulations), DNR (https://dnr.example.gov)."
+			if got := strings.Contains(system, wantSites); got != tt.wantSites {
+				t.Errorf("official sites present = %v, want %v in %q", got, tt.wantSites, system)
+			}
+		})
+	}
+}
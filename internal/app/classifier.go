@@ -0,0 +1,110 @@
+// internal/app/classifier.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// defaultClassifierModel is the small model used to tag questions when CLASSIFIER_MODEL is unset.
+const defaultClassifierModel = "gpt-4o-mini"
+
+// questionTags holds the taxonomy detected for a question, used for KB lookups and the usage log.
+type questionTags struct {
+	BodyOfWater string
+	FishSpecies string
+	WaterType   string
+	Category    string // Primary category used for the KB query
+	Categories  string // Comma-separated categories recorded in the usage log
+}
+
+// classifierReply is the JSON the classifier model is asked to return.
+type classifierReply struct {
+	Category    string `json:"category"`
+	FishSpecies string `json:"fish_species"`
+	BodyOfWater string `json:"body_of_water"`
+}
+
+// tagQuestion tags a question using keyword matching, refined by the model classifier when enabled.
+// The keyword tags are kept whenever the classifier is disabled, fails, or returns nothing usable.
+func (a *App) tagQuestion(ctx context.Context, question string, keywords []string) questionTags {
+	tags := questionTags{Categories: utils.DetermineCategories(keywords)}
+	tags.BodyOfWater, tags.FishSpecies, tags.WaterType, tags.Category = utils.IdentifyTaxonomyCategories(question)
+
+	if !a.ClassifierEnabled || (a.Budget != nil && a.Budget.Exceeded()) {
+		return tags
+	}
+
+	reply, err := a.classifyWithModel(ctx, question)
+	if err != nil {
+		log.Printf("Question classifier failed, using keyword tags: %v", err)
+		return tags
+	}
+	if category := matchKnown(reply.Category, categoryNames()); category != "" {
+		tags.Category = category
+		tags.Categories = category
+	}
+	if species := matchKnown(reply.FishSpecies, utils.FishSpeciesKeywords); species != "" {
+		tags.FishSpecies = species
+	}
+	if bodyOfWater := matchKnown(reply.BodyOfWater, utils.BodyOfWaterKeywords); bodyOfWater != "" {
+		tags.BodyOfWater = bodyOfWater
+	}
+	return tags
+}
+
+// classifyWithModel asks the classifier model to pick a known category, species, and body of water.
+func (a *App) classifyWithModel(ctx context.Context, question string) (classifierReply, error) {
+	prompt := fmt.Sprintf(
+		"Classify the fishing question. Reply with JSON only: {\"category\":\"\",\"fish_species\":\"\",\"body_of_water\":\"\"}. "+
+			"Use exactly one value from each list or an empty string.\nCategories: %s\nSpecies: %s\nBodies of water: %s",
+		strings.Join(categoryNames(), "; "), strings.Join(utils.FishSpeciesKeywords, "; "), strings.Join(utils.BodyOfWaterKeywords, "; "))
+
+	response, err := a.LLM.CompleteWithModel(ctx, a.ClassifierModel, []types.OpenAIMessage{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: question},
+	})
+	if err != nil {
+		return classifierReply{}, err
+	}
+
+	// Models sometimes wrap JSON in a code fence
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+	response = strings.TrimSuffix(response, "```")
+
+	var reply classifierReply
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &reply); err != nil {
+		return classifierReply{}, fmt.Errorf("unexpected classifier response %q: %w", response, err)
+	}
+	return reply, nil
+}
+
+// categoryNames returns the known question categories in a stable order.
+func categoryNames() []string {
+	names := make([]string, 0, len(utils.CategoryKeywords))
+	for name := range utils.CategoryKeywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchKnown returns the known value equal to candidate ignoring case, or "" when there is none.
+func matchKnown(candidate string, known []string) string {
+	candidate = strings.TrimSpace(candidate)
+	for _, value := range known {
+		if candidate != "" && strings.EqualFold(candidate, value) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// internal/app/classifier_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// classifierStub answers classifier requests with reply and everything else like the default fakeLLM.
+func classifierStub(reply string, err error) func(messages []types.OpenAIMessage) (string, error) {
+	return func(messages []types.OpenAIMessage) (string, error) {
+		if strings.HasPrefix(messages[0].Content, "Classify the fishing question") {
+			return reply, err
+		}
+		return "Answer to: " + messages[len(messages)-1].Content, nil
+	}
+}
+
+func TestTagQuestion(t *testing.T) {
+	const question = "When should I fish for chromers near the big lake?"
+	keywordTags := questionTags{FishSpecies: "steelhead", Categories: "Uncategorized"}
+	tests := []struct {
+		name    string
+		enabled bool
+		reply   string
+		err     error
+		want    questionTags
+	}{
+		{"disabled uses keywords", false, `{"category":"Timing"}`, nil, keywordTags},
+		{"classifier tags", true, `{"category":"timing","fish_species":"Steelhead","body_of_water":"Lake Ontario"}`, nil,
+			questionTags{Category: "Timing", Categories: "Timing", FishSpecies: "steelhead", BodyOfWater: "lake ontario"}},
+		{"code fence", true, "```json\n{\"category\":\"Timing\"}\n```", nil,
+			questionTags{Category: "Timing", Categories: "Timing", FishSpecies: "steelhead"}},
+		{"unknown values keep keywords", true, `{"category":"Weather","fish_species":"walleye"}`, nil, keywordTags},
+		{"unparsable reply keeps keywords", true, "Timing", nil, keywordTags},
+		{"error keeps keywords", true, "", errors.New("openai down"), keywordTags},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ClassifierEnabled = tt.enabled
+			a.ClassifierModel = defaultClassifierModel
+			a.llm.answer = classifierStub(tt.reply, tt.err)
+
+			got := a.tagQuestion(context.Background(), question, utils.ExtractKeywords(question))
+			if got != tt.want {
+				t.Errorf("tagQuestion() = %+v, want %+v", got, tt.want)
+			}
+			if tt.enabled && (len(a.llm.models) != 1 || a.llm.models[0] != defaultClassifierModel) {
+				t.Errorf("classifier used models %v, want [%s]", a.llm.models, defaultClassifierModel)
+			}
+		})
+	}
+}
+
+func TestClassifierFeedsKnowledgeBaseQuery(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		params []types.QueryParameters
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p types.QueryParameters
+		json.NewDecoder(r.Body).Decode(&p)
+		mutex.Lock()
+		params = append(params, p)
+		mutex.Unlock()
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	a := newTestApp(t)
+	a.ClassifierEnabled = true
+	a.ClassifierModel = defaultClassifierModel
+	a.KnowledgeBaseActive = true
+	a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient(server.URL, "key")
+	a.llm.answer = classifierStub(`{"category":"Timing","fish_species":"steelhead","body_of_water":"lake ontario"}`, nil)
+
+	question := "When should I fish for chromers near the big lake?"
+	if err := a.processMessage(context.Background(), 1, 7, "angler", question, 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	want := types.QueryParameters{BodyOfWater: "lake ontario", FishSpecies: "steelhead", Category: "Timing", Query: question}
+	if len(params) != 1 || params[0] != want {
+		t.Errorf("KB queried with %+v, want [%+v]", params, want)
+	}
+}
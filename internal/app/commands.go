@@ -0,0 +1,125 @@
+// internal/app/commands.go
+
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commandInfo describes a bot command for help output.
+type commandInfo struct {
+	Name        string // Command name without the leading slash
+	Usage       string // Arguments shown after the command name
+	Description string // What the command does
+	Example     string // Example invocation; empty when the command takes no arguments
+	AdminOnly   bool   // Indicates if only admins can run the command
+}
+
+// commandRegistry lists the commands users can ask about with /help <command>.
+var commandRegistry = []commandInfo{
+	{
+		Name:        "learn",
+		Usage:       "[Category]: [SubCategory]: [Your Information]",
+		Description: "Train the bot's Knowledge Base with new information.",
+		Example:     "/learn Techniques: Fly Fishing: Information about choosing the right fly fishing gear.",
+	},
+	{
+		Name:        "rate",
+		Usage:       "[KB Number] [Helpful/Not Helpful]",
+		Description: "Provide feedback on Knowledge Base articles to help improve accuracy.",
+		Example:     "/rate 123 Helpful",
+	},
+	{
+		Name:        "retry",
+		Description: "Ask your last question again, e.g. after an answer was cut short.",
+	},
+	{
+		Name:        "human",
+		Usage:       "[Your Question]",
+		Description: "Ask a human guide when the bot can't help.",
+		Example:     "/human Is the Salmon River fly zone open this week?",
+	},
+	{
+		Name:        "forget",
+		Description: "Clear your conversation history to start a fresh topic. Other members of a group keep theirs.",
+	},
+	{
+		Name:        "chatprompt",
+		Usage:       "[Instructions|off]",
+		Description: "Show or set extra instructions answers in this chat follow, e.g. the group's focus. Only chat administrators can change them.",
+		Example:     "/chatprompt Focus on saltwater fishing from piers and jetties.",
+	},
+	{
+		Name:        "stats",
+		Description: "Show how many messages you have left before the rate limit and when the next one frees up.",
+	},
+	{
+		Name:        "language",
+		Usage:       "[Language|off]",
+		Description: "Show or set the language answers use in this chat. Only chat administrators can change it.",
+		Example:     "/language Spanish",
+	},
+	{
+		Name:        "selftest",
+		Description: "Check that OpenAI, the Knowledge Base, and S3 are reachable.",
+		AdminOnly:   true,
+	},
+	{
+		Name:        "proposals",
+		Usage:       "[approve|reject] [ID]",
+		Description: "List answers users voted into the Knowledge Base, or approve or reject one.",
+		Example:     "/proposals approve 3f2a9c1e8b7d6a50",
+		AdminOnly:   true,
+	},
+	{
+		Name:        "help",
+		Usage:       "[command]",
+		Description: "Show how to use the bot, or details for a single command.",
+		Example:     "/help rate",
+	},
+}
+
+// lookupCommand finds a command by name, ignoring a leading slash, a bot mention, and letter case.
+func lookupCommand(name string) (commandInfo, bool) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+	name = strings.SplitN(name, "@", 2)[0]
+	for _, info := range commandRegistry {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return commandInfo{}, false
+}
+
+// commandHelp returns focused help for a single command, or a list of known commands when it is unknown.
+func commandHelp(name string) string {
+	info, ok := lookupCommand(name)
+	if !ok {
+		names := make([]string, 0, len(commandRegistry))
+		for _, known := range commandRegistry {
+			names = append(names, "/"+known.Name)
+		}
+		return fmt.Sprintf("I don't know a command called %s.\nAvailable commands: %s\n\nSend /help for the full guide.",
+			escapeInlineCode(name), strings.Join(names, ", "))
+	}
+
+	var help strings.Builder
+	fmt.Fprintf(&help, "**/%s", info.Name)
+	if info.Usage != "" {
+		fmt.Fprintf(&help, " %s", info.Usage)
+	}
+	fmt.Fprintf(&help, "**\n%s", info.Description)
+	if info.AdminOnly {
+		help.WriteString("\n_Admins only._")
+	}
+	if info.Example != "" {
+		fmt.Fprintf(&help, "\n\n**Example:** `%s`", info.Example)
+	}
+	return help.String()
+}
+
+// escapeInlineCode wraps user text in backticks so Markdown in it is shown literally.
+func escapeInlineCode(text string) string {
+	return "`" + strings.ReplaceAll(text, "`", "'") + "`"
+}
@@ -0,0 +1,249 @@
+// internal/app/commands_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// commandMessage returns a group message from user 7 with the given command text.
+func commandMessage(text string) *types.TelegramMessage {
+	return &types.TelegramMessage{
+		MessageID: 10,
+		Text:      text,
+		Chat:      types.TelegramChat{ID: 1, Type: "group"},
+		From:      types.TelegramUser{ID: 7},
+	}
+}
+
+func TestCommandsForOtherBots(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		botUsername string
+		ignoreOther bool
+		want        string // Prefix of the single reply; empty means the command is ignored
+	}{
+		{"bare command", "/help", "", true, "*ReelTalkBot Help*"},
+		{"addressed to us", "/help@ReelTalkBot", "", true, "*ReelTalkBot Help*"},
+		{"addressed to us in another case", "/help@reeltalkbot", "", true, "*ReelTalkBot Help*"},
+		{"addressed to our configured username", "/help@FishingHelperBot", "@FishingHelperBot", true, "*ReelTalkBot Help*"},
+		{"addressed to the default username under another", "/help@ReelTalkBot", "StagingBot", true, ""},
+		{"addressed to another bot", "/help@SomeoneElse", "", true, ""},
+		{"another bot, ignoring disabled", "/help@SomeoneElse", "", false, "Unknown command."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.BotUsername = tt.botUsername
+			a.IgnoreOtherBotCommands = tt.ignoreOther
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.text), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand(%q) error = %v", tt.text, err)
+			}
+			texts := a.telegram.texts()
+			if tt.want == "" {
+				if len(texts) != 0 {
+					t.Errorf("HandleCommand(%q) replied %q, want it ignored", tt.text, texts)
+				}
+				return
+			}
+			if len(texts) != 1 || !strings.HasPrefix(texts[0], tt.want) {
+				t.Errorf("HandleCommand(%q) replied %q, want one reply starting with %q", tt.text, texts, tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnAndRateAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		enabled bool
+		access  string
+		admin   bool
+		want    string // Prefix of the reply
+	}{
+		{"learn disabled", "/learn", false, accessPublic, true, "Knowledge base training is currently disabled."},
+		{"learn public", "/learn", true, accessPublic, false, "Please provide the training data."},
+		{"learn admin-only, admin", "/learn", true, accessAdmin, true, "Please provide the training data."},
+		{"learn admin-only, user", "/learn", true, accessAdmin, false, "You are not authorized to train the knowledge base."},
+		{"rate disabled", "/rate", false, accessPublic, true, "Knowledge base rating is currently disabled."},
+		{"rate public", "/rate", true, accessPublic, false, "Please provide the KB number and your rating."},
+		{"rate admin-only, admin", "/rate", true, accessAdmin, true, "Please provide the KB number and your rating."},
+		{"rate admin-only, user", "/rate", true, accessAdmin, false, "You are not authorized to rate knowledge base articles."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient("http://kb.invalid", "key")
+			a.TrainingEnabled, a.TrainingAccess = tt.enabled, tt.access
+			a.RatingEnabled, a.RatingAccess = tt.enabled, tt.access
+			// Each flag only gates its own command
+			if tt.command == "/learn" {
+				a.RatingEnabled, a.RatingAccess = false, accessAdmin
+			} else {
+				a.TrainingEnabled, a.TrainingAccess = false, accessAdmin
+			}
+			if tt.admin {
+				a.NoLimitUsers[7] = struct{}{}
+			}
+
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.command), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand(%q) error = %v", tt.command, err)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || !strings.HasPrefix(texts[0], tt.want) {
+				t.Errorf("HandleCommand(%q) replied %q, want one reply starting with %q", tt.command, texts, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAccess(t *testing.T) {
+	tests := []struct {
+		raw, defaultValue, want string
+	}{
+		{"", accessAdmin, accessAdmin},
+		{"public", accessAdmin, accessPublic},
+		{" ADMIN ", accessPublic, accessAdmin},
+		{"everyone", accessPublic, accessPublic},
+	}
+	for _, tt := range tests {
+		if got := parseAccess(tt.raw, tt.defaultValue); got != tt.want {
+			t.Errorf("parseAccess(%q, %q) = %q, want %q", tt.raw, tt.defaultValue, got, tt.want)
+		}
+	}
+}
+
+func TestHumanEscalation(t *testing.T) {
+	const adminChatID = -500
+	tests := []struct {
+		name        string
+		text        string
+		adminChatID int64
+		adminFails  bool
+		wantRelay   bool
+		wantReply   string
+	}{
+		{"relayed to the admin chat", "/human Where can I launch a kayak?", adminChatID, false, true, "Thanks! I've passed your question to a human guide"},
+		{"no admin chat", "/human Where can I launch a kayak?", 0, false, false, "Sorry, human support isn't available right now."},
+		{"no question", "/human  ", adminChatID, false, false, "Please include your question."},
+		{"admin chat unreachable", "/human Where can I launch a kayak?", adminChatID, true, true, "Sorry, I couldn't reach a human guide."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.AdminChatID = tt.adminChatID
+			if tt.adminFails {
+				a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+					if payload["chat_id"] == float64(adminChatID) {
+						return http.StatusForbidden, `{"ok":false,"description":"bot was kicked"}`
+					}
+					return 0, ""
+				}
+			}
+
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.text), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand(%q) error = %v", tt.text, err)
+			}
+
+			var relays, replies []string
+			for _, call := range a.telegram.sent("sendMessage") {
+				text, _ := call.Payload["text"].(string)
+				if call.Payload["chat_id"] == float64(adminChatID) {
+					relays = append(relays, text)
+				} else {
+					replies = append(replies, text)
+				}
+			}
+			if tt.wantRelay {
+				if len(relays) == 0 || !strings.Contains(relays[0], "Where can I launch a kayak?") || !strings.Contains(relays[0], "angler (ID 7)") {
+					t.Errorf("relayed %q, want the question and the user", relays)
+				}
+			} else if len(relays) != 0 {
+				t.Errorf("relayed %q, want nothing", relays)
+			}
+			if len(replies) != 1 || !strings.HasPrefix(replies[0], tt.wantReply) {
+				t.Errorf("replied %q, want one reply starting with %q", replies, tt.wantReply)
+			}
+		})
+	}
+}
+
+func TestHelpResetsContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		reset       bool
+		command     string
+		wantHistory bool
+	}{
+		{"reset enabled", true, "/help", false},
+		{"reset enabled, /start", true, "/start", false},
+		{"reset disabled", false, "/help", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ResetContextOnHelp = tt.reset
+			if err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+			if err := a.processMessage(context.Background(), 1, 8, "other", "Best bait for trout?", 11, types.MessageMeta{}); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.command), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand(%q) error = %v", tt.command, err)
+			}
+
+			if _, ok := a.ConversationContexts.Get(a.conversationKey(7)); ok != tt.wantHistory {
+				t.Errorf("user 7 still has history = %v, want %v", ok, tt.wantHistory)
+			}
+			if _, ok := a.ConversationContexts.Get(a.conversationKey(8)); !ok {
+				t.Error("another user's history was cleared")
+			}
+		})
+	}
+}
+
+func TestHelpForCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		want     []string // Substrings of the single reply
+		dontWant string
+	}{
+		{"rate", "/help rate", []string{"/rate [KB Number] [Helpful/Not Helpful]", "Provide feedback on Knowledge Base articles", "/rate 123 Helpful"}, "ReelTalkBot Help"},
+		{"slash and case ignored", "/help /LEARN", []string{"/learn [Category]: [SubCategory]: [Your Information]"}, "ReelTalkBot Help"},
+		{"admin command", "/help selftest", []string{"Check that OpenAI", "Admins only."}, "ReelTalkBot Help"},
+		{"unknown command", "/help fly", []string{"I don't know a command called `fly`.", "Available commands: /learn, /rate"}, "ReelTalkBot Help"},
+		{"bare help keeps the overview", "/help", []string{"ReelTalkBot Help"}, "I don't know a command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if _, err := a.HandleCommand(context.Background(), commandMessage(tt.command), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand(%q) error = %v", tt.command, err)
+			}
+
+			texts := a.telegram.texts()
+			if len(texts) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(texts))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(texts[0], want) {
+					t.Errorf("reply %q does not contain %q", texts[0], want)
+				}
+			}
+			if strings.Contains(texts[0], tt.dontWant) {
+				t.Errorf("reply %q contains %q", texts[0], tt.dontWant)
+			}
+		})
+	}
+}
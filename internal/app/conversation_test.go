@@ -0,0 +1,146 @@
+// internal/app/conversation_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// testConversation returns a system prompt followed by turns user/assistant messages of size bytes each.
+func testConversation(turns, size int) []types.OpenAIMessage {
+	messages := []types.OpenAIMessage{{Role: "system", Content: "You are ReelTalkBot."}}
+	for i := 0; i < turns; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, types.OpenAIMessage{Role: role, Content: string(rune('a'+i)) + strings.Repeat(".", size-1)})
+	}
+	return messages
+}
+
+func TestSaveConversationTrimsToByteCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxBytes  int
+		turns     int
+		size      int
+		wantTurns int // Turns kept after the system prompt
+	}{
+		{"under the cap", 4096, 4, 100, 4},
+		{"cap disabled", 0, 6, 1000, 6},
+		{"oversized history", 1024, 6, 300, 2},
+		{"single oversized turn", 128, 1, 500, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ConversationMaxBytes = tt.maxBytes
+			messages := testConversation(tt.turns, tt.size)
+
+			a.saveConversation("user_7", messages)
+
+			stored, _ := a.ConversationContexts.Get("user_7")
+			if tt.maxBytes > 0 && tt.wantTurns > 0 && len(stored) > tt.maxBytes {
+				t.Errorf("stored %d bytes, want at most %d", len(stored), tt.maxBytes)
+			}
+			kept := a.loadConversation("user_7")
+			if len(kept) != tt.wantTurns+1 {
+				t.Fatalf("kept %d messages, want the system prompt and %d turns", len(kept), tt.wantTurns)
+			}
+			if kept[0] != messages[0] {
+				t.Errorf("first message = %+v, want the system prompt", kept[0])
+			}
+			// The newest turns survive
+			for i, message := range kept[1:] {
+				if want := messages[len(messages)-tt.wantTurns+i]; message != want {
+					t.Errorf("kept turn %d = %q, want %q", i, message.Content[:1], want.Content[:1])
+				}
+			}
+		})
+	}
+}
+
+func TestKeysAreNamespacedByInstance(t *testing.T) {
+	tests := []struct {
+		name             string
+		instanceID       string
+		wantConversation string
+		wantUpdate       string
+	}{
+		{"no instance", "", "user_7", "update_42"},
+		{"instance set", "bass-bot", "bass-bot:user_7", "bass-bot:update_42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.InstanceID = tt.instanceID
+			if got := a.conversationKey(7); got != tt.wantConversation {
+				t.Errorf("conversationKey(7) = %q, want %q", got, tt.wantConversation)
+			}
+			if got := a.updateKey(42); got != tt.wantUpdate {
+				t.Errorf("updateKey(42) = %q, want %q", got, tt.wantUpdate)
+			}
+		})
+	}
+}
+
+func TestInstancesKeepSeparateConversations(t *testing.T) {
+	a := newTestApp(t)
+	a.InstanceID = "bass-bot"
+	if err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if _, ok := a.ConversationContexts.Get("bass-bot:user_7"); !ok {
+		t.Error("conversation was not stored under the namespaced key")
+	}
+	if _, ok := a.ConversationContexts.Get("user_7"); ok {
+		t.Error("conversation was stored under the bare key another instance would use")
+	}
+}
+
+func TestCorruptConversationIsDiscarded(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  string
+		wantLen int // Messages sent to OpenAI for the next question
+	}{
+		{"truncated JSON", `[{"role":"user","content":"Best bait`, 2},
+		{"wrong shape", `{"role":"user"}`, 2},
+		{"valid history", `[{"role":"system","content":"You are ReelTalkBot."},{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]`, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			key := a.conversationKey(7)
+			a.ConversationContexts.Set(key, tt.stored)
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			if got := len(a.llm.lastCall()); got != tt.wantLen {
+				t.Errorf("sent %d messages to OpenAI, want %d", got, tt.wantLen)
+			}
+			// The history saved after the answer is readable again
+			if got := a.loadConversation(key); len(got) != tt.wantLen+1 {
+				t.Errorf("stored %d messages after the answer, want %d", len(got), tt.wantLen+1)
+			}
+		})
+	}
+}
+
+func TestLoadConversationDeletesCorruptHistory(t *testing.T) {
+	a := newTestApp(t)
+	a.ConversationContexts.Set("user_7", "not json")
+
+	if messages := a.loadConversation("user_7"); messages != nil {
+		t.Errorf("loadConversation = %v, want nil", messages)
+	}
+	if _, found := a.ConversationContexts.Get("user_7"); found {
+		t.Error("corrupt history was kept")
+	}
+}
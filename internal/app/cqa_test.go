@@ -0,0 +1,50 @@
+// internal/app/cqa_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/cqa"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestCQAAnswersBeforeOpenAI(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantText     string
+		wantLLMCalls int
+	}{
+		{"confident answer is used", http.StatusOK, `{"answers":[{"answer":"Use a chartreuse spinnerbait.","confidenceScore":0.92}]}`, "Use a chartreuse spinnerbait.", 0},
+		{"blank answer falls through", http.StatusOK, `{"answers":[{"answer":"","confidenceScore":0.92}]}`, "Answer to: Best bait for bass?", 1},
+		{"unconfident answer falls through", http.StatusOK, `{"answers":[{"answer":"Use worms.","confidenceScore":0.1}]}`, "Answer to: Best bait for bass?", 1},
+		{"CQA failure falls through", http.StatusInternalServerError, ``, "Answer to: Best bait for bass?", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(server.Close)
+			a := newTestApp(t)
+			a.CQAClient = cqa.NewCQAClient(server.URL, "key", 0.5)
+
+			if err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+			if got := a.llm.callCount(); got != tt.wantLLMCalls {
+				t.Errorf("OpenAI was called %d times, want %d", got, tt.wantLLMCalls)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || !strings.Contains(texts[0], tt.wantText) {
+				t.Errorf("sent %q, want one reply containing %q", texts, tt.wantText)
+			}
+		})
+	}
+}
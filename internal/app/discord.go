@@ -0,0 +1,49 @@
+// internal/app/discord.go
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// ProcessDiscordMessage answers a question asked through the Discord adapter and returns the reply text.
+// Discord IDs are numeric snowflakes; they are rate limited apart from Telegram IDs, which they may collide with.
+func (a *App) ProcessDiscordMessage(channelID, userID, username, userQuestion string) (string, error) {
+	chatID, err := strconv.ParseInt(channelID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid Discord channel ID %q: %w", channelID, err)
+	}
+	uid, err := strconv.Atoi(userID)
+	if err != nil {
+		return "", fmt.Errorf("invalid Discord user ID %q: %w", userID, err)
+	}
+	return a.answerPlainText("discord", chatID, uid, "discord:"+username, userQuestion)
+}
+
+// answerPlainText answers a question from an adapter that sends replies itself, such as Discord or WhatsApp,
+// and returns the reply text. The question goes through the same pipeline as a Telegram message, so it shares
+// the rate-limit settings, quiet hours, CQA, Knowledge Base, and S3 logging, while its usage, in-flight cap, and
+// conversation history are kept apart from Telegram ones under the platform's name.
+func (a *App) answerPlainText(platform string, chatID int64, uid int, username, userQuestion string) (string, error) {
+	ctx, cancel := a.updateContext()
+	defer cancel()
+
+	ch := &textChannel{app: a, platform: platform}
+	err := a.handleQuestion(ctx, ch, chatID, uid, username, userQuestion, 0, types.MessageMeta{}, 1)
+	// Rate limits and other turned-away questions still leave a notice to send back
+	if reply := ch.text(); reply != "" {
+		if err != nil {
+			log.Printf("Answering %s user %d: %v", platform, uid, err)
+		}
+		return reply, nil
+	}
+	if err == nil {
+		err = errors.New("no reply was produced")
+	}
+	return "", err
+}
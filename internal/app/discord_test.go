@@ -0,0 +1,168 @@
+// internal/app/discord_test.go
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/usage"
+)
+
+func TestProcessDiscordMessage(t *testing.T) {
+	const channelID, userID = "900", "42"
+
+	tests := []struct {
+		name       string
+		setup      func(a *testApp)
+		wantReply  string // Substring the reply must contain
+		wantLLM    bool   // Whether OpenAI is asked
+		wantUsage  int    // Messages charged to the user
+		wantSystem string // Substring the system prompt must contain
+	}{
+		{
+			name:      "answered by OpenAI",
+			wantReply: "Answer to: Best bait for bass?",
+			wantLLM:   true,
+			wantUsage: 1,
+		},
+		{
+			name: "answered from the Knowledge Base",
+			setup: func(a *testApp) {
+				kb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(`[{"kb_number":7,"question_template":"Best bait for bass?","answer":"Soft plastic worms."}]`))
+				}))
+				t.Cleanup(kb.Close)
+				a.KnowledgeBaseActive = true
+				a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient(kb.URL, "key")
+			},
+			wantReply: "Soft plastic worms.",
+			wantUsage: 1,
+		},
+		{
+			name:       "per-chat prompt applies",
+			setup:      func(a *testApp) { a.chatPrompts[900] = "Focus on Lake Erie." },
+			wantReply:  "Answer to: Best bait for bass?",
+			wantLLM:    true,
+			wantUsage:  1,
+			wantSystem: "Focus on Lake Erie.",
+		},
+		{
+			name: "user limit reached",
+			setup: func(a *testApp) {
+				for i := 0; i < usage.DefaultLimit; i++ {
+					a.platformLimits("discord").usage.AddUsage(42)
+				}
+			},
+			wantReply: "We restrict to",
+			wantUsage: usage.DefaultLimit,
+		},
+		{
+			name: "chat limit reached",
+			setup: func(a *testApp) {
+				a.UsageCache.SetChatLimit(1, time.Hour)
+				a.platformLimits("discord").usage.AddChatUsage(900)
+			},
+			wantReply: "This chat has reached its shared limit",
+		},
+		{
+			name: "Telegram user with the same ID doesn't share the limit",
+			setup: func(a *testApp) {
+				for i := 0; i < usage.DefaultLimit; i++ {
+					a.UsageCache.AddUsage(42)
+				}
+			},
+			wantReply: "Answer to: Best bait for bass?",
+			wantLLM:   true,
+			wantUsage: 1,
+		},
+		{
+			name: "Telegram no-limit ID grants no exemption",
+			setup: func(a *testApp) {
+				a.NoLimitUsers[42] = struct{}{}
+				for i := 0; i < usage.DefaultLimit; i++ {
+					a.platformLimits("discord").usage.AddUsage(42)
+				}
+			},
+			wantReply: "We restrict to",
+			wantUsage: usage.DefaultLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if tt.setup != nil {
+				tt.setup(a)
+			}
+
+			reply, err := a.ProcessDiscordMessage(channelID, userID, "angler", "Best bait for bass?")
+			if err != nil {
+				t.Fatalf("ProcessDiscordMessage() error = %v", err)
+			}
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if got := a.llm.callCount() > 0; got != tt.wantLLM {
+				t.Errorf("asked OpenAI = %v, want %v", got, tt.wantLLM)
+			}
+			remaining, _ := a.platformLimits("discord").usage.RemainingMessages(42)
+			if used := usage.DefaultLimit - remaining; used != tt.wantUsage {
+				t.Errorf("charged %d Discord messages, want %d", used, tt.wantUsage)
+			}
+			if sent := a.telegram.sent("sendMessage"); len(sent) != 0 {
+				t.Errorf("sent %d Telegram messages for a Discord question", len(sent))
+			}
+			if tt.wantSystem != "" {
+				if system := a.llm.lastCall()[0].Content; !strings.Contains(system, tt.wantSystem) {
+					t.Errorf("system prompt %q is missing %q", system, tt.wantSystem)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscordConversationKeptApart(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.ProcessDiscordMessage("900", "42", "angler", "Best bait for bass?"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.ConversationContexts.Get(a.conversationKey(42)); ok {
+		t.Error("Discord question was stored in the Telegram conversation")
+	}
+	if _, ok := a.ConversationContexts.Get("discord_42"); !ok {
+		t.Error("Discord conversation was not stored under discord_42")
+	}
+}
+
+func TestAdapterUsersAreLimitedPerPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		ask      func(a *testApp) (string, error)
+		platform string
+	}{
+		{"WhatsApp", func(a *testApp) (string, error) {
+			return a.ProcessWhatsAppMessage("42", "Best bait for bass?")
+		}, "whatsapp"},
+		{"Discord", func(a *testApp) (string, error) {
+			return a.ProcessDiscordMessage("900", "42", "angler", "Best bait for bass?")
+		}, "discord"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if _, err := tt.ask(a); err != nil {
+				t.Fatal(err)
+			}
+			if used := a.usedMessages(42); used != 0 {
+				t.Errorf("charged Telegram user 42 %d messages for a %s question", used, tt.name)
+			}
+			if remaining, _ := a.platformLimits(tt.platform).usage.RemainingMessages(42); remaining != usage.DefaultLimit-1 {
+				t.Errorf("%s user has %d messages left, want %d", tt.name, remaining, usage.DefaultLimit-1)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// internal/app/duplicate_answers_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestCollapseRepeatedAnswers(t *testing.T) {
+	type question struct {
+		chatID int64
+		userID int
+	}
+	tests := []struct {
+		name      string
+		enabled   bool
+		stream    bool
+		questions []question
+		wantNotes []bool // Whether each answer is collapsed into duplicateAnswerNote
+	}{
+		{"same user twice", true, false, []question{{1, 7}, {1, 7}}, []bool{false, true}},
+		{"different users in one chat", true, false, []question{{-100, 7}, {-100, 8}}, []bool{false, false}},
+		{"same user in different chats", true, false, []question{{1, 7}, {2, 7}}, []bool{false, false}},
+		{"another user in between", true, false, []question{{-100, 7}, {-100, 8}, {-100, 7}}, []bool{false, false, true}},
+		{"streamed answer", true, true, []question{{1, 7}, {1, 7}}, []bool{false, true}},
+		{"disabled", false, false, []question{{1, 7}, {1, 7}}, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if tt.enabled {
+				a.CollapseDuplicates = true
+				a.lastAnswers = cache.NewCache()
+			}
+			if tt.stream {
+				a.StreamResponses = true
+				a.LLM = streamingLLM{a.llm}
+			}
+
+			for i, q := range tt.questions {
+				if err := a.processMessage(context.Background(), q.chatID, q.userID, "angler", "Best bait for bass?", 10+i, types.MessageMeta{}); err != nil {
+					t.Fatalf("question %d: %v", i+1, err)
+				}
+				final := lastAnswerText(a)
+				if got := strings.HasPrefix(final, duplicateAnswerNote); got != tt.wantNotes[i] {
+					t.Errorf("answer %d = %q, collapsed = %v, want %v", i+1, final, got, tt.wantNotes[i])
+				}
+			}
+			if tt.stream && len(a.telegram.sent("editMessageText")) != len(tt.questions) {
+				t.Errorf("finished %d streamed answers, want %d", len(a.telegram.sent("editMessageText")), len(tt.questions))
+			}
+		})
+	}
+}
+
+// lastAnswerText returns the text of the latest answer, whether sent as a message or edited into a streamed placeholder.
+func lastAnswerText(a *testApp) string {
+	a.telegram.mutex.Lock()
+	defer a.telegram.mutex.Unlock()
+	for i := len(a.telegram.calls) - 1; i >= 0; i-- {
+		call := a.telegram.calls[i]
+		if call.Method == "sendMessage" || call.Method == "editMessageText" {
+			text, _ := call.Payload["text"].(string)
+			return text
+		}
+	}
+	return ""
+}
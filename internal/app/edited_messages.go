@@ -0,0 +1,95 @@
+// internal/app/edited_messages.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// defaultEditGraceWindow is how long after a message is answered its first edit is re-answered without
+// charging the rate limit again.
+const defaultEditGraceWindow = 10 * time.Minute
+
+// chargedMessageKey identifies a message whose answer was charged to the rate limit.
+func chargedMessageKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("charged:%d:%d", chatID, messageID)
+}
+
+// markCharged records that the message was charged to the rate limit, so one edit within EditGraceWindow is free.
+func (a *App) markCharged(chatID int64, messageID int) {
+	if a.chargedMessages == nil || messageID == 0 {
+		return
+	}
+	a.chargedMessages.SetWithTTL(chargedMessageKey(chatID, messageID), "1", a.EditGraceWindow)
+}
+
+// useFreeEdit reports whether an edited message was charged to the rate limit within EditGraceWindow,
+// so fixing a typo refreshes the answer without using another message. The charge is used up, so only
+// one re-answer is free; later edits are charged like new messages.
+func (a *App) useFreeEdit(chatID int64, messageID int, edited bool) bool {
+	if !edited || a.chargedMessages == nil {
+		return false
+	}
+	_, charged := a.chargedMessages.Take(chargedMessageKey(chatID, messageID))
+	return charged
+}
+
+// replyMapTTL is how long the bot's reply to each message is remembered; Telegram only lets bots edit
+// their messages for 48 hours.
+const replyMapTTL = 48 * time.Hour
+
+// replyKey identifies the bot's reply to a user's message.
+func replyKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("reply:%d:%d", chatID, messageID)
+}
+
+// rememberReply records the bot's reply to a user's message, so an edit of the message can update the reply.
+func (a *App) rememberReply(chatID int64, messageID, replyID int) {
+	if a.replyMessages == nil || messageID == 0 || replyID == 0 {
+		return
+	}
+	a.replyMessages.SetWithTTL(replyKey(chatID, messageID), strconv.Itoa(replyID), replyMapTTL)
+}
+
+// replyTo returns the ID of the bot's reply to a user's message, or 0 when none is remembered.
+func (a *App) replyTo(chatID int64, messageID int) int {
+	if a.replyMessages == nil {
+		return 0
+	}
+	value, found := a.replyMessages.Get(replyKey(chatID, messageID))
+	if !found {
+		return 0
+	}
+	replyID, _ := strconv.Atoi(value)
+	return replyID
+}
+
+// editReply replaces the bot's earlier reply to an edited message with a new single-message answer.
+// It reports false when there is no reply to edit, the answer needs several messages, or the edit fails,
+// so the caller sends the answer as a new message instead.
+func (a *App) editReply(ctx context.Context, chatID int64, messageID int, text, keyboard string) bool {
+	replyID := a.replyTo(chatID, messageID)
+	if replyID == 0 || len(utils.SplitMessage(text, utils.TelegramMessageLimit)) > 1 {
+		return false
+	}
+	// Business replies can only be edited through their connection
+	if a.businessConnectionID(chatID, messageID) != "" {
+		return false
+	}
+	err := a.editMessageText(ctx, chatID, replyID, text, "Markdown", keyboard)
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return true // The edit didn't change the answer
+	}
+	if err != nil {
+		log.Printf("Failed to edit reply %d to edited message %d, sending a new answer: %v", replyID, messageID, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+// internal/app/edited_messages_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestEditedMessageRateLimitCharge(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    time.Duration
+		edits     []bool // Whether each delivery of message 10 is an edit
+		wantUsage []int  // Messages charged after each delivery
+	}{
+		{"new message is charged", 10 * time.Minute, []bool{false}, []int{1}},
+		{"first edit is free", 10 * time.Minute, []bool{false, true}, []int{1, 1}},
+		{"second edit is charged", 10 * time.Minute, []bool{false, true, true}, []int{1, 1, 2}},
+		{"edit after a charged edit is free again", 10 * time.Minute, []bool{false, true, true, true}, []int{1, 1, 2, 2}},
+		{"grace window disabled", 0, []bool{false, true}, []int{1, 2}},
+		{"edit of an unanswered message is charged", 10 * time.Minute, []bool{true}, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.EditGraceWindow = tt.window
+			if tt.window > 0 {
+				a.chargedMessages = cache.NewCache()
+			}
+			for i, edited := range tt.edits {
+				err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{Edited: edited})
+				if err != nil {
+					t.Fatalf("delivery %d: %v", i+1, err)
+				}
+				if got := a.usedMessages(7); got != tt.wantUsage[i] {
+					t.Errorf("after delivery %d charged %d messages, want %d", i+1, got, tt.wantUsage[i])
+				}
+			}
+			if got := a.llm.callCount(); got != len(tt.edits) {
+				t.Errorf("answered %d times, want %d", got, len(tt.edits))
+			}
+		})
+	}
+}
+
+func TestEditedMessageRespectsRateLimit(t *testing.T) {
+	a := newTestApp(t)
+	a.EditGraceWindow = 10 * time.Minute
+	a.chargedMessages = cache.NewCache()
+
+	// Use up the limit with one question and its repeated edits
+	ctx := context.Background()
+	if err := a.processMessage(ctx, 1, 7, "angler", "Best bait?", 10, types.MessageMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	for a.usedMessages(7) < 10 {
+		if err := a.processMessage(ctx, 1, 7, "angler", "Best bait?", 10, types.MessageMeta{Edited: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := a.llm.callCount()
+
+	// The last charged edit leaves at most one free re-answer; every edit after that hits the limit
+	a.processMessage(ctx, 1, 7, "angler", "Best bait?", 10, types.MessageMeta{Edited: true})
+	a.processMessage(ctx, 1, 7, "angler", "Best bait?", 10, types.MessageMeta{Edited: true})
+	if got := a.llm.callCount() - calls; got > 1 {
+		t.Errorf("answered %d edits beyond the rate limit, want at most the one free re-answer", got)
+	}
+}
+
+func TestEditedQuestionUpdatesTheAnswer(t *testing.T) {
+	tests := []struct {
+		name       string
+		edited     bool
+		answer     string // Answer to the edited question
+		editStatus int    // Status of the editMessageText request; 0 succeeds
+		editBody   string
+		wantEdit   bool // Whether the earlier reply is edited
+		wantSent   int  // New messages sent for the edited question
+	}{
+		{"edit replaces the reply", true, "Use a spinnerbait.", 0, "", true, 0},
+		{"unchanged answer", true, "Use a jig.", http.StatusBadRequest, `{"ok":false,"description":"Bad Request: message is not modified"}`, true, 0},
+		{"failed edit sends a new answer", true, "Use a spinnerbait.", http.StatusBadRequest, `{"ok":false,"description":"Bad Request: message can't be edited"}`, true, 1},
+		{"long answer sent in parts", true, longAnswer(), 0, "", false, 2},
+		{"new question gets a new answer", false, "Use a spinnerbait.", 0, "", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.CitationsEnabled = false
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method == "editMessageText" {
+					return tt.editStatus, tt.editBody
+				}
+				return 0, ""
+			}
+			ctx := context.Background()
+			a.llm.answer = func([]types.OpenAIMessage) (string, error) { return "Use a jig.", nil }
+			if err := a.processMessage(ctx, 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatal(err)
+			}
+			firstReply := a.replyTo(1, 10)
+			if firstReply == 0 {
+				t.Fatal("the reply to the question was not remembered")
+			}
+
+			a.llm.answer = func([]types.OpenAIMessage) (string, error) { return tt.answer, nil }
+			if err := a.processMessage(ctx, 1, 7, "angler", "Best lure for bass?", 10, types.MessageMeta{Edited: tt.edited}); err != nil {
+				t.Fatal(err)
+			}
+
+			edits := a.telegram.sent("editMessageText")
+			if (len(edits) == 1) != tt.wantEdit {
+				t.Fatalf("edited %d messages, want an edit: %v", len(edits), tt.wantEdit)
+			}
+			if tt.wantEdit {
+				if got := edits[0].Payload["message_id"]; got != float64(firstReply) {
+					t.Errorf("edited message %v, want the earlier reply %d", got, firstReply)
+				}
+				if got, _ := edits[0].Payload["text"].(string); !strings.Contains(got, tt.answer) {
+					t.Errorf("edited the reply to %q, want the new answer", got)
+				}
+			}
+			if got := len(a.telegram.texts()) - 1; got != tt.wantSent {
+				t.Errorf("sent %d new messages for the edited question, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
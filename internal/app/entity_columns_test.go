@@ -0,0 +1,46 @@
+// internal/app/entity_columns_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestEntityColumnsAreLogged(t *testing.T) {
+	const question = "Trolling for stripers on Lake Ontario with a spoon?"
+	tests := []struct {
+		name    string
+		enabled bool
+		want    map[string]string
+	}{
+		{"enabled", true, map[string]string{
+			"species":   "striped bass",
+			"location":  "lake ontario",
+			"technique": "trolling",
+			"gear":      "spoon",
+		}},
+		{"disabled", false, map[string]string{"species": "", "location": "", "technique": "", "gear": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.EntityExtraction = tt.enabled
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", question, 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			rows := loggedRows(t, a)
+			if len(rows) != 1 {
+				t.Fatalf("logged %d records, want 1", len(rows))
+			}
+			for column, want := range tt.want {
+				if got := rows[0][column]; got != want {
+					t.Errorf("logged %s %q, want %q", column, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// internal/app/example_prompts_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ReelTalkBot-Go/internal/prompts"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// helpButtons sends /help and returns the buttons of the keyboard attached to the reply.
+func helpButtons(t *testing.T, a *testApp) []map[string]string {
+	t.Helper()
+	if _, err := a.HandleCommand(context.Background(), commandMessage("/help"), 7, "angler"); err != nil {
+		t.Fatalf("HandleCommand(/help) error = %v", err)
+	}
+	sent := a.telegram.sent("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	raw, _ := sent[0].Payload["reply_markup"].(string)
+	var keyboard struct {
+		InlineKeyboard [][]map[string]string `json:"inline_keyboard"`
+	}
+	if err := json.Unmarshal([]byte(raw), &keyboard); err != nil {
+		t.Fatalf("reply_markup %q is not a keyboard: %v", raw, err)
+	}
+	var buttons []map[string]string
+	for _, row := range keyboard.InlineKeyboard {
+		buttons = append(buttons, row...)
+	}
+	return buttons
+}
+
+func TestExamplePromptButtons(t *testing.T) {
+	tests := []struct {
+		name      string
+		available int
+		limit     int
+		want      int
+	}{
+		{"fewer than the limit", 2, 3, 2},
+		{"capped by the limit", 5, 3, 3},
+		{"none allowed", 4, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			examples := make([]prompts.ExamplePrompt, tt.available)
+			for i := range examples {
+				examples[i] = prompts.ExamplePrompt{Label: fmt.Sprintf("Example %d", i+1), Prompt: fmt.Sprintf("Question %d?", i+1)}
+			}
+			data, _ := json.Marshal(examples)
+			path := filepath.Join(t.TempDir(), "prompts.json")
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			a := newTestApp(t)
+			a.loadExamplePrompts(path, tt.limit)
+			buttons := helpButtons(t, a)
+
+			if len(buttons) != tt.want {
+				t.Fatalf("keyboard has %d buttons, want %d", len(buttons), tt.want)
+			}
+			for i, button := range buttons {
+				if want := examples[i].Label; button["text"] != want {
+					t.Errorf("button %d text = %q, want %q", i, button["text"], want)
+				}
+				if got := a.promptMap[button["callback_data"]]; got != examples[i].Prompt {
+					t.Errorf("button %d callback %q maps to %q, want %q", i, button["callback_data"], got, examples[i].Prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestExamplePromptButtonAsksItsQuestion(t *testing.T) {
+	a := newTestApp(t)
+	a.loadExamplePrompts("", 3)
+	buttons := helpButtons(t, a)
+	if len(buttons) == 0 {
+		t.Fatal("keyboard has no buttons")
+	}
+
+	err := a.HandleCallbackQuery(context.Background(), &types.TelegramCallbackQuery{
+		ID:      "cb-1",
+		From:    types.TelegramUser{ID: 7},
+		Message: &types.TelegramMessage{MessageID: 20, Chat: types.TelegramChat{ID: 1}},
+		Data:    buttons[0]["callback_data"],
+	})
+	if err != nil {
+		t.Fatalf("HandleCallbackQuery() error = %v", err)
+	}
+	call := a.llm.lastCall()
+	if want := a.examplePrompts[0].Prompt; len(call) == 0 || call[len(call)-1].Content != want {
+		t.Errorf("asked OpenAI %+v, want the example prompt %q", call, want)
+	}
+}
@@ -0,0 +1,83 @@
+// internal/app/final_message_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// helpFooter ends every prepared message.
+const helpFooter = "\n\nNeed Help? Type /help to see how to use this bot effectively."
+
+func TestCitations(t *testing.T) {
+	entries := []types.KnowledgeEntryResponse{
+		{KBNumber: 12, Category: "Techniques", SubCategory: "Fly Fishing", BodyOfWater: "Salmon River"},
+		{KBNumber: 34, Category: "Gear", SubCategory: "Rods", BodyOfWater: "Lake Erie"},
+	}
+	tests := []struct {
+		name     string
+		enabled  bool
+		template string
+		entries  []types.KnowledgeEntryResponse
+		want     string
+	}{
+		{"default template, one source", true, defaultCitationTemplate, entries[:1],
+			"Answer\n\n**KB Number:** 12\n**Category:** Techniques\n**Taxonomy:** Fly Fishing" + helpFooter},
+		{"custom template, several sources", true, "Source #{kb_number} ({body_of_water})", entries,
+			"Answer\n\nSource #12 (Salmon River)\n\nSource #34 (Lake Erie)" + helpFooter},
+		{"no sources", true, defaultCitationTemplate, nil, "Answer" + helpFooter},
+		{"citations disabled", false, defaultCitationTemplate, entries, "Answer" + helpFooter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.CitationsEnabled = tt.enabled
+			a.CitationTemplate = tt.template
+
+			if got := a.PrepareFinalMessage(SourceKnowledgeBase, "Answer", tt.entries); got != tt.want {
+				t.Errorf("PrepareFinalMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		source  AnswerSource
+		want    string
+	}{
+		{"knowledge base", true, SourceKnowledgeBase, "✅ Verified answer\n\nAnswer" + helpFooter},
+		{"community answer", true, SourceCQA, "✅ Verified answer\n\nAnswer" + helpFooter},
+		{"openai", true, SourceOpenAI, "🤖 AI-generated\n\nAnswer" + helpFooter},
+		{"disabled", false, SourceOpenAI, "Answer" + helpFooter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.SourceTagsEnabled = tt.enabled
+
+			if got := a.PrepareFinalMessage(tt.source, "Answer", nil); got != tt.want {
+				t.Errorf("PrepareFinalMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIAnswerIsTaggedAIGenerated(t *testing.T) {
+	a := newTestApp(t)
+	a.SourceTagsEnabled = true
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for perch?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	texts := a.telegram.texts()
+	if len(texts) != 1 || !strings.HasPrefix(texts[0], "🤖 AI-generated") {
+		t.Errorf("sent %q, want one answer tagged as AI-generated", texts)
+	}
+}
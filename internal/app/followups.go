@@ -0,0 +1,154 @@
+// internal/app/followups.go
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// followUpCallbackPrefix marks the callback_data of suggested follow-up question buttons.
+const followUpCallbackPrefix = "followup_"
+
+// Limits on follow-up suggestions
+const (
+	defaultFollowUpCount = 3   // Suggestions offered when FOLLOW_UP_COUNT is unset
+	maxFollowUpCount     = 5   // Upper bound on FOLLOW_UP_COUNT
+	maxFollowUpLength    = 120 // Suggestions longer than this are dropped, since they make unwieldy buttons
+	maxFollowUpEntries   = 1000
+)
+
+// followUps remembers the suggested questions behind follow-up buttons, since callback_data is limited
+// to 64 bytes. The oldest suggestions are forgotten once maxFollowUpEntries are held.
+type followUps struct {
+	count     int // Maximum suggestions offered per answer
+	mutex     sync.Mutex
+	questions map[string]string
+	order     []string // IDs in insertion order, oldest first
+}
+
+// newFollowUps initializes follow-up tracking offering up to count suggestions per answer.
+func newFollowUps(count int) *followUps {
+	if count < 1 {
+		count = 1
+	}
+	if count > maxFollowUpCount {
+		count = maxFollowUpCount
+	}
+	return &followUps{
+		count:     count,
+		questions: make(map[string]string),
+	}
+}
+
+// add stores a suggested question and returns the callback_data of its button.
+func (f *followUps) add(question string) string {
+	hash := sha256.Sum256([]byte(question))
+	id := hex.EncodeToString(hash[:8])
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.questions[id]; !ok {
+		f.questions[id] = question
+		f.order = append(f.order, id)
+		for len(f.order) > maxFollowUpEntries {
+			delete(f.questions, f.order[0])
+			f.order = f.order[1:]
+		}
+	}
+	return followUpCallbackPrefix + id
+}
+
+// question returns the suggested question behind a follow-up button's callback_data.
+func (f *followUps) question(data string) (string, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	question, ok := f.questions[strings.TrimPrefix(data, followUpCallbackPrefix)]
+	return question, ok
+}
+
+// followUpRows returns one keyboard row per suggested follow-up question for an answer, or nil when
+// follow-ups are disabled, the spending cap is reached, or no suggestions could be generated.
+func (a *App) followUpRows(ctx context.Context, question, answer string) [][]map[string]string {
+	if a.followUps == nil || (a.Budget != nil && a.Budget.Exceeded()) {
+		return nil
+	}
+
+	suggestions, err := a.suggestFollowUps(ctx, question, answer)
+	if err != nil {
+		log.Printf("Failed to suggest follow-up questions: %v", err)
+		return nil
+	}
+
+	var rows [][]map[string]string
+	for _, suggestion := range suggestions {
+		button, err := callbackButton(suggestion, a.followUps.add(suggestion))
+		if err != nil {
+			log.Printf("Skipping follow-up button: %v", err)
+			continue
+		}
+		rows = append(rows, []map[string]string{button})
+	}
+	return rows
+}
+
+// suggestFollowUps asks the classifier model for short follow-up questions about the exchange, keeping
+// at most the configured number of usable suggestions.
+func (a *App) suggestFollowUps(ctx context.Context, question, answer string) ([]string, error) {
+	prompt := fmt.Sprintf(
+		"Suggest %d short follow-up questions the angler might ask next about this exchange. "+
+			"Reply with a JSON array of strings only, each under %d characters.", a.followUps.count, maxFollowUpLength)
+
+	response, err := a.LLM.CompleteWithModel(ctx, a.ClassifierModel, []types.OpenAIMessage{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: fmt.Sprintf("Question: %s\n\nAnswer: %s", question, utils.SummarizeToLength(answer, 2000))},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Models sometimes wrap JSON in a code fence
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+	response = strings.TrimSuffix(strings.TrimSpace(response), "```")
+
+	var raw []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse follow-up suggestions: %w", err)
+	}
+
+	var suggestions []string
+	for _, suggestion := range raw {
+		suggestion = strings.TrimSpace(suggestion)
+		if suggestion == "" || len(suggestion) > maxFollowUpLength {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == a.followUps.count {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+// inlineKeyboard marshals keyboard rows into reply_markup JSON, returning "" when there are no rows.
+func inlineKeyboard(rows [][]map[string]string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	keyboard, err := json.Marshal(map[string]interface{}{"inline_keyboard": rows})
+	if err != nil {
+		log.Printf("Failed to marshal inline keyboard: %v", err)
+		return ""
+	}
+	return string(keyboard)
+}
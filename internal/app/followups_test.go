@@ -0,0 +1,138 @@
+// internal/app/followups_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// followUpLLM answers follow-up suggestion requests with suggestions and echoes every other question.
+func followUpLLM(suggestions string) func([]types.OpenAIMessage) (string, error) {
+	return func(messages []types.OpenAIMessage) (string, error) {
+		if strings.HasPrefix(messages[0].Content, "Suggest ") {
+			return suggestions, nil
+		}
+		return "Answer to: " + messages[len(messages)-1].Content, nil
+	}
+}
+
+func TestSuggestFollowUps(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		response string
+		want     []string
+		wantErr  bool
+	}{
+		{"plain JSON", 3, `["What about at night?", "Which line?"]`, []string{"What about at night?", "Which line?"}, false},
+		{"code fence", 3, "```json\n[\"Which line?\"]\n```", []string{"Which line?"}, false},
+		{"capped at count", 2, `["One?", "Two?", "Three?"]`, []string{"One?", "Two?"}, false},
+		{"empty and over-long dropped", 3, `["", "  ", "` + strings.Repeat("x", maxFollowUpLength+1) + `", "Short?"]`, []string{"Short?"}, false},
+		{"not JSON", 3, "1. What about at night?", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.followUps = newFollowUps(tt.count)
+			a.llm.answer = followUpLLM(tt.response)
+
+			got, err := a.suggestFollowUps(context.Background(), "Best bass lure?", "Try a jig.")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("suggestFollowUps error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestFollowUps = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFollowUpsClampsCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{"raised to one", 0, 1},
+		{"within range", 3, 3},
+		{"capped", maxFollowUpCount + 1, maxFollowUpCount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newFollowUps(tt.count).count; got != tt.want {
+				t.Errorf("newFollowUps(%d).count = %d, want %d", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowUpButtonAsksItsQuestion(t *testing.T) {
+	a := newTestApp(t)
+	a.followUps = newFollowUps(2)
+	a.llm.answer = followUpLLM(`["What about at night?", "Which line should I use?"]`)
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	sent := a.telegram.sent("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	raw, _ := sent[0].Payload["reply_markup"].(string)
+	var keyboard struct {
+		InlineKeyboard [][]map[string]string `json:"inline_keyboard"`
+	}
+	if err := json.Unmarshal([]byte(raw), &keyboard); err != nil {
+		t.Fatalf("reply_markup %q is not a keyboard: %v", raw, err)
+	}
+	if len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("keyboard has %d rows, want one per suggestion", len(keyboard.InlineKeyboard))
+	}
+	button := keyboard.InlineKeyboard[1][0]
+	if button["text"] != "Which line should I use?" || !strings.HasPrefix(button["callback_data"], followUpCallbackPrefix) {
+		t.Fatalf("second button = %v", button)
+	}
+
+	err := a.HandleCallbackQuery(context.Background(), &types.TelegramCallbackQuery{
+		ID:      "cb-1",
+		From:    types.TelegramUser{ID: 7},
+		Message: &types.TelegramMessage{MessageID: 20, Chat: types.TelegramChat{ID: 1}},
+		Data:    button["callback_data"],
+	})
+	if err != nil {
+		t.Fatalf("HandleCallbackQuery() error = %v", err)
+	}
+	want := "Answer to: Which line should I use?" + helpFooter
+	if texts := a.telegram.texts(); len(texts) != 2 || texts[1] != want {
+		t.Errorf("sent %q, want the follow-up answered with %q", texts, want)
+	}
+}
+
+func TestFollowUpFailureKeepsTheAnswer(t *testing.T) {
+	a := newTestApp(t)
+	a.followUps = newFollowUps(2)
+	a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+		if strings.HasPrefix(messages[0].Content, "Suggest ") {
+			return "", errors.New("classifier unavailable")
+		}
+		return "Use a jig.", nil
+	}
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	sent := a.telegram.sent("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	if markup, ok := sent[0].Payload["reply_markup"]; ok {
+		t.Errorf("answer has reply_markup %v, want none", markup)
+	}
+}
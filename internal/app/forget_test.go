@@ -0,0 +1,45 @@
+// internal/app/forget_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestForgetClearsOnlyTheInvokingUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int
+		cleared int
+		kept    int
+	}{
+		{"first member forgets", 7, 7, 8},
+		{"second member forgets", 8, 8, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			for _, userID := range []int{7, 8} {
+				a.saveConversation(a.conversationKey(userID), testConversation(2, 20))
+			}
+
+			message := commandMessage("/forget")
+			message.From.ID = tt.userID
+			if _, err := a.HandleCommand(context.Background(), message, tt.userID, "angler"); err != nil {
+				t.Fatalf("HandleCommand error = %v", err)
+			}
+
+			if _, found := a.ConversationContexts.Get(a.conversationKey(tt.cleared)); found {
+				t.Errorf("user %d's conversation survived /forget", tt.cleared)
+			}
+			if got := len(a.loadConversation(a.conversationKey(tt.kept))); got != 3 {
+				t.Errorf("user %d kept %d messages, want 3", tt.kept, got)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || !strings.HasPrefix(texts[0], "Done! I've forgotten") {
+				t.Errorf("replied %q, want the confirmation", texts)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// internal/app/health_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+)
+
+// pingingLLM is a fakeLLM that also answers health pings.
+type pingingLLM struct {
+	*fakeLLM
+	err error
+}
+
+func (p pingingLLM) Ping(ctx context.Context) error {
+	return p.err
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		pingOpenAI  bool
+		pinger      bool
+		pingErr     error
+		kbDown      bool
+		wantOpenAI  string
+		wantKB      string
+		wantHealthy bool
+	}{
+		{"not pinged", false, true, nil, false, "unknown", "disabled", true},
+		{"ping succeeds", true, true, nil, false, "up", "disabled", true},
+		{"ping fails", true, true, errors.New("timeout"), false, "down", "disabled", true},
+		{"provider cannot be pinged", true, false, nil, false, "unknown", "disabled", true},
+		{"knowledge base down", false, false, nil, true, "unknown", "down", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if tt.pinger {
+				a.LLM = pingingLLM{fakeLLM: a.llm, err: tt.pingErr}
+			}
+			if tt.kbDown {
+				a.KnowledgeBaseActive = true
+				a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient("http://kb.invalid", "key")
+				a.isKnowledgeBaseDown.Store(true)
+			}
+
+			status, healthy := a.Health(tt.pingOpenAI)
+			if status.OpenAI != tt.wantOpenAI {
+				t.Errorf("OpenAI = %q, want %q", status.OpenAI, tt.wantOpenAI)
+			}
+			if status.KnowledgeBase != tt.wantKB {
+				t.Errorf("KnowledgeBase = %q, want %q", status.KnowledgeBase, tt.wantKB)
+			}
+			if healthy != tt.wantHealthy {
+				t.Errorf("healthy = %v, want %v", healthy, tt.wantHealthy)
+			}
+		})
+	}
+}
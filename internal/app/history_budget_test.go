@@ -0,0 +1,45 @@
+// internal/app/history_budget_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+func TestHistoryTokenBudgetTrimsOldestTurns(t *testing.T) {
+	questions := []string{"Best bait for bass?", "What about trout?", "And perch?"}
+	tests := []struct {
+		name      string
+		budget    func(a *testApp) int
+		wantCount int // Messages in the last OpenAI request
+	}{
+		{"unlimited keeps the conversation", func(*testApp) int { return 0 }, 6},
+		{"tight budget keeps the system prompt and question", func(a *testApp) int {
+			return utils.EstimateMessageTokens(types.OpenAIMessage{Role: "system", Content: a.systemPrompt(1)}) +
+				utils.EstimateMessageTokens(types.OpenAIMessage{Role: "user", Content: questions[2]})
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.HistoryTokenBudget = tt.budget(a)
+
+			for i, q := range questions {
+				if err := a.ProcessMessage(context.Background(), 1, 7, "angler", q, 10+i, types.MessageMeta{}); err != nil {
+					t.Fatalf("ProcessMessage failed: %v", err)
+				}
+			}
+			sent := a.llm.lastCall()
+			if len(sent) != tt.wantCount {
+				t.Fatalf("sent %d messages, want %d", len(sent), tt.wantCount)
+			}
+			if sent[0].Role != "system" || sent[len(sent)-1].Content != questions[2] {
+				t.Errorf("sent %v, want the system prompt first and the latest question last", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// internal/app/kb_cache.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// Default Knowledge Base cache settings
+const (
+	defaultKBCacheTTL        = time.Hour
+	defaultKBWarmupSize      = 50
+	maxKBWarmupSize          = 500 // Bounds the entries held in memory by a warmup
+	kbWarmupTimeout          = 30 * time.Second
+	kbCacheKeyPrefix         = "kb:"
+	kbCacheEvictionFrequency = 10 * time.Minute
+)
+
+// kbCacheKey returns the KB cache key for a question, normalized so trivially different phrasings share it.
+func kbCacheKey(question string) string {
+	return kbCacheKeyPrefix + normalizeQuestion(question)
+}
+
+// knowledgeEntries returns the KB entries for a question, from the KB cache when it holds them and otherwise
+// from the Knowledge Base, caching what it returns. Without a KB cache every question goes to the Knowledge Base.
+func (a *App) knowledgeEntries(ctx context.Context, kbClient *knowledgebase.KnowledgeBaseClient, params types.QueryParameters) ([]types.KnowledgeEntryResponse, error) {
+	if a.KBCache == nil {
+		return kbClient.GetKnowledgeEntries(ctx, params)
+	}
+
+	key := kbCacheKey(params.Query)
+	if cached, found := a.KBCache.Get(key); found {
+		var entries []types.KnowledgeEntryResponse
+		if err := json.Unmarshal([]byte(cached), &entries); err == nil {
+			return entries, nil
+		}
+	}
+
+	entries, err := kbClient.GetKnowledgeEntries(ctx, params)
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+	if entriesJSON, err := json.Marshal(entries); err == nil {
+		a.KBCache.SetWithTTL(key, string(entriesJSON), a.KBCacheTTL)
+	}
+	return entries, nil
+}
+
+// warmKBCache fills the KB cache with up to limit of the Knowledge Base's top entries, keyed by their
+// question templates, so the first users asking popular questions are answered without a KB round trip.
+// It returns the number of entries cached.
+func (a *App) warmKBCache(ctx context.Context, limit int) (int, error) {
+	if limit > maxKBWarmupSize {
+		limit = maxKBWarmupSize
+	}
+	entries, err := a.KnowledgeBaseClient.GetTopEntries(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prefer the best-rated entries in case the Knowledge Base returned more than limit
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].HelpfulRatings-entries[i].NotHelpfulRatings > entries[j].HelpfulRatings-entries[j].NotHelpfulRatings
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	cached := 0
+	for _, entry := range entries {
+		if normalizeQuestion(entry.QuestionTemplate) == "" {
+			continue
+		}
+		entryJSON, err := json.Marshal([]types.KnowledgeEntryResponse{entry})
+		if err != nil {
+			continue
+		}
+		a.KBCache.SetWithTTL(kbCacheKey(entry.QuestionTemplate), string(entryJSON), a.KBCacheTTL)
+		cached++
+	}
+	return cached, nil
+}
+
+// startKBWarmup warms the KB cache in the background so startup isn't delayed by the Knowledge Base.
+func (a *App) startKBWarmup(limit int) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), kbWarmupTimeout)
+		defer cancel()
+		cached, err := a.warmKBCache(ctx, limit)
+		if err != nil {
+			log.Printf("Failed to warm the Knowledge Base cache: %v", err)
+			return
+		}
+		log.Printf("Warmed the Knowledge Base cache with %d top entries", cached)
+	}()
+}
@@ -0,0 +1,145 @@
+// internal/app/kb_cache_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// newCountingKB returns a Knowledge Base client whose server answers queries with entries and /top with
+// top, and a counter of the queries it received.
+func newCountingKB(t *testing.T, entries, top []types.KnowledgeEntryResponse) (*knowledgebase.KnowledgeBaseClient, *int32) {
+	t.Helper()
+	var queries int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/top") {
+			json.NewEncoder(w).Encode(top)
+			return
+		}
+		atomic.AddInt32(&queries, 1)
+		json.NewEncoder(w).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+	return knowledgebase.NewKnowledgeBaseClient(server.URL, "key"), &queries
+}
+
+func TestKnowledgeEntriesAreCached(t *testing.T) {
+	bassTip := []types.KnowledgeEntryResponse{{KBNumber: 1, QuestionTemplate: "Best bass lure", Answer: "A jig."}}
+	tests := []struct {
+		name        string
+		cached      bool
+		entries     []types.KnowledgeEntryResponse
+		questions   []string
+		wantQueries int32
+	}{
+		{"repeat served from the cache", true, bassTip, []string{"Best bass lure?", "best  BASS lure"}, 1},
+		{"different questions", true, bassTip, []string{"Best bass lure?", "Best trout fly?"}, 2},
+		{"empty results are not cached", true, nil, []string{"Best bass lure?", "Best bass lure?"}, 2},
+		{"cache disabled", false, bassTip, []string{"Best bass lure?", "Best bass lure?"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			client, queries := newCountingKB(t, tt.entries, nil)
+			if tt.cached {
+				a.KBCache = cache.NewCache()
+				a.KBCacheTTL = time.Hour
+			}
+
+			for _, question := range tt.questions {
+				entries, err := a.knowledgeEntries(context.Background(), client, types.QueryParameters{Query: question})
+				if err != nil {
+					t.Fatalf("knowledgeEntries(%q) failed: %v", question, err)
+				}
+				if len(entries) != len(tt.entries) {
+					t.Errorf("knowledgeEntries(%q) returned %d entries, want %d", question, len(entries), len(tt.entries))
+				}
+			}
+			if got := atomic.LoadInt32(queries); got != tt.wantQueries {
+				t.Errorf("Knowledge Base was queried %d times, want %d", got, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestWarmKBCache(t *testing.T) {
+	top := []types.KnowledgeEntryResponse{
+		{KBNumber: 1, QuestionTemplate: "Best bass lure?", Answer: "A jig.", HelpfulRatings: 1},
+		{KBNumber: 2, QuestionTemplate: "Best trout fly", Answer: "A nymph.", HelpfulRatings: 9},
+		{KBNumber: 3, QuestionTemplate: "  ", Answer: "No question.", HelpfulRatings: 20},
+		{KBNumber: 4, QuestionTemplate: "Best crab bait", Answer: "Chicken necks.", HelpfulRatings: 5, NotHelpfulRatings: 5},
+	}
+	tests := []struct {
+		name       string
+		limit      int
+		wantCached int
+		wantKeys   []string // Questions found in the cache after warmup
+		wantMissed []string // Questions not found in the cache after warmup
+	}{
+		{"every usable entry", 10, 3, []string{"best bass lure", "Best trout fly?", "best crab bait"}, nil},
+		{"limited to the top entries", 2, 2, []string{"best bass lure", "best trout fly"}, []string{"best crab bait"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseClient, _ = newCountingKB(t, nil, top)
+			a.KBCache = cache.NewCache()
+			a.KBCacheTTL = time.Hour
+
+			cached, err := a.warmKBCache(context.Background(), tt.limit)
+			if err != nil {
+				t.Fatalf("warmKBCache failed: %v", err)
+			}
+			if cached != tt.wantCached {
+				t.Errorf("cached %d entries, want %d", cached, tt.wantCached)
+			}
+			for _, question := range tt.wantKeys {
+				if _, found := a.KBCache.Get(kbCacheKey(question)); !found {
+					t.Errorf("%q is not cached", question)
+				}
+			}
+			for _, question := range tt.wantMissed {
+				if _, found := a.KBCache.Get(kbCacheKey(question)); found {
+					t.Errorf("%q is cached, want it left out", question)
+				}
+			}
+		})
+	}
+}
+
+func TestWarmedEntriesAnswerWithoutAKnowledgeBaseQuery(t *testing.T) {
+	a := newTestApp(t)
+	top := []types.KnowledgeEntryResponse{{KBNumber: 1, QuestionTemplate: "Best bass lure", Answer: "A jig."}}
+	client, queries := newCountingKB(t, nil, top)
+	a.KnowledgeBaseActive = true
+	a.KnowledgeBaseClient = client
+	a.KBCache = cache.NewCache()
+	a.KBCacheTTL = time.Hour
+	if _, err := a.warmKBCache(context.Background(), defaultKBWarmupSize); err != nil {
+		t.Fatalf("warmKBCache failed: %v", err)
+	}
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if got := atomic.LoadInt32(queries); got != 0 {
+		t.Errorf("Knowledge Base was queried %d times, want 0", got)
+	}
+	if texts := a.telegram.texts(); len(texts) != 1 || !strings.Contains(texts[0], "A jig.") {
+		t.Errorf("sent %q, want the warmed entry's answer", texts)
+	}
+	if a.llm.callCount() != 0 {
+		t.Error("OpenAI was asked a question the warmed cache answers")
+	}
+}
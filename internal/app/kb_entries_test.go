@@ -0,0 +1,74 @@
+// internal/app/kb_entries_test.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// newTestKB returns a Knowledge Base client for a server that answers every query with entries.
+func newTestKB(t *testing.T, entries []types.KnowledgeEntryResponse) *knowledgebase.KnowledgeBaseClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+	return knowledgebase.NewKnowledgeBaseClient(server.URL, "key")
+}
+
+func TestAnswersIncludeUpToMaxKBEntries(t *testing.T) {
+	var entries []types.KnowledgeEntryResponse
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, types.KnowledgeEntryResponse{
+			KBNumber:         uint(i),
+			QuestionTemplate: fmt.Sprintf("Bass tip %d", i),
+			Answer:           fmt.Sprintf("Answer %d", i),
+		})
+	}
+
+	tests := []struct {
+		name        string
+		maxEntries  int
+		wantEntries int // The top entries included in the answer
+	}{
+		{"single entry", 1, 1},
+		{"default of three", 3, 3},
+		{"cap above the matches", 10, 5},
+		{"no cap", 0, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.KnowledgeBaseClient = newTestKB(t, entries)
+			a.MaxKBEntries = tt.maxEntries
+			a.CitationsEnabled = false
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass tips?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			texts := a.telegram.texts()
+			if len(texts) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(texts))
+			}
+			for _, entry := range entries {
+				want := int(entry.KBNumber) <= tt.wantEntries
+				if got := strings.Contains(texts[0], entry.QuestionTemplate); got != want {
+					t.Errorf("answer includes %q = %v, want %v", entry.QuestionTemplate, got, want)
+				}
+			}
+			if calls := a.llm.callCount(); calls != 0 {
+				t.Errorf("OpenAI was called %d times for a KB answer", calls)
+			}
+		})
+	}
+}
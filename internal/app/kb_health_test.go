@@ -0,0 +1,63 @@
+// internal/app/kb_health_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestKnowledgeBaseFailureMarksDown(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		timeout  time.Duration // Bounds the update; 0 leaves it unbounded
+		wantDown bool
+	}{
+		{
+			name:     "server error",
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			wantDown: true,
+		},
+		{
+			name: "update cancelled while waiting",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			timeout:  50 * time.Millisecond,
+			wantDown: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := httptest.NewServer(tt.handler)
+			t.Cleanup(kb.Close)
+
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient(kb.URL, "key")
+			a.KnowledgeBaseClient.MaxRetries = 0
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			a.processMessage(ctx, 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{})
+
+			if down := !a.KnowledgeBaseStatus(); down != tt.wantDown {
+				t.Errorf("KB marked down = %v, want %v", down, tt.wantDown)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// internal/app/kb_match_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestWeakKBMatchesFallBackToOpenAI(t *testing.T) {
+	entries := []types.KnowledgeEntryResponse{
+		{KBNumber: 1, QuestionTemplate: "How do I tie a palomar knot", Answer: "Double the line."},
+		{KBNumber: 2, QuestionTemplate: "Best bait for largemouth bass", Answer: "Plastic worms."},
+	}
+	tests := []struct {
+		name       string
+		threshold  float64
+		question   string
+		included   []string // Entry answers in the reply
+		excluded   []string // Entry answers left out of the reply
+		wantOpenAI bool
+	}{
+		{"threshold disabled trusts every hit", 0, "Best bait for largemouth bass?", []string{"Double the line.", "Plastic worms."}, nil, false},
+		{"weak matches are skipped", 0.5, "Best bait for largemouth bass?", []string{"Plastic worms."}, []string{"Double the line."}, false},
+		{"no strong match asks OpenAI", 0.5, "When do walleye spawn?", nil, []string{"Double the line.", "Plastic worms."}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.KnowledgeBaseClient = newTestKB(t, entries)
+			a.KBMatchThreshold = tt.threshold
+			a.CitationsEnabled = false
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", tt.question, 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			if asked := a.llm.callCount() > 0; asked != tt.wantOpenAI {
+				t.Errorf("asked OpenAI = %v, want %v", asked, tt.wantOpenAI)
+			}
+			texts := a.telegram.texts()
+			if len(texts) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(texts))
+			}
+			for _, answer := range tt.included {
+				if !strings.Contains(texts[0], answer) {
+					t.Errorf("reply %q is missing %q", texts[0], answer)
+				}
+			}
+			for _, answer := range tt.excluded {
+				if strings.Contains(texts[0], answer) {
+					t.Errorf("reply %q includes the weak match %q", texts[0], answer)
+				}
+			}
+		})
+	}
+}
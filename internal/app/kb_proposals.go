@@ -0,0 +1,258 @@
+// internal/app/kb_proposals.go
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// helpfulCallbackPrefix marks the callback_data of the 👍 button attached to OpenAI answers.
+const helpfulCallbackPrefix = "kbvote_"
+
+// maxKBCandidates bounds the OpenAI answers tracked for votes; the oldest unqueued ones are dropped first.
+const maxKBCandidates = 500
+
+// kbCandidate is an OpenAI answer users can vote into the Knowledge Base.
+type kbCandidate struct {
+	ID        string
+	Question  string
+	Answer    string
+	Category  string
+	Species   string
+	CreatedAt time.Time
+	voters    map[int]struct{}
+	queued    bool // Reached the vote threshold and awaits admin review
+}
+
+// kbProposals tracks votes on OpenAI answers and the review queue of answers proposed for the KB.
+type kbProposals struct {
+	threshold  int
+	mutex      sync.Mutex
+	candidates map[string]*kbCandidate
+}
+
+// newKBProposals initializes proposal tracking that queues an answer once threshold users found it helpful.
+func newKBProposals(threshold int) *kbProposals {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &kbProposals{
+		threshold:  threshold,
+		candidates: make(map[string]*kbCandidate),
+	}
+}
+
+// kbCandidateID identifies an answer to a question, so the same answer to the same question shares its votes.
+func kbCandidateID(question, answer string) string {
+	hash := sha256.Sum256([]byte(normalizeQuestion(question) + "\n" + answer))
+	return hex.EncodeToString(hash[:8])
+}
+
+// track registers an OpenAI answer for voting and returns the keyboard row offering the 👍 button, or nil
+// if the button can't be built.
+func (p *kbProposals) track(question, answer string, tags questionTags) []map[string]string {
+	id := kbCandidateID(question, answer)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.candidates[id]; !ok {
+		p.candidates[id] = &kbCandidate{
+			ID:        id,
+			Question:  question,
+			Answer:    answer,
+			Category:  tags.Category,
+			Species:   tags.FishSpecies,
+			CreatedAt: time.Now(),
+			voters:    make(map[int]struct{}),
+		}
+		p.evictOldest()
+	}
+
+	button, err := callbackButton("👍 Helpful", helpfulCallbackPrefix+id)
+	if err != nil {
+		log.Printf("Skipping helpful button: %v", err)
+		return nil
+	}
+	return []map[string]string{button}
+}
+
+// evictOldest drops the oldest candidates still collecting votes while there are too many. Callers must hold mutex.
+func (p *kbProposals) evictOldest() {
+	for len(p.candidates) > maxKBCandidates {
+		var oldest *kbCandidate
+		for _, c := range p.candidates {
+			if !c.queued && (oldest == nil || c.CreatedAt.Before(oldest.CreatedAt)) {
+				oldest = c
+			}
+		}
+		if oldest == nil {
+			return // Everything left awaits review
+		}
+		delete(p.candidates, oldest.ID)
+	}
+}
+
+// vote records a user's 👍 for an answer. It reports whether the answer is still known, and returns the
+// candidate when this vote made it reach the threshold and join the review queue.
+func (p *kbProposals) vote(id string, userID int) (known bool, queued *kbCandidate) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	c, ok := p.candidates[id]
+	if !ok {
+		return false, nil
+	}
+	c.voters[userID] = struct{}{}
+	if !c.queued && len(c.voters) >= p.threshold {
+		c.queued = true
+		return true, c
+	}
+	return true, nil
+}
+
+// pending returns the answers awaiting review, oldest first.
+func (p *kbProposals) pending() []kbCandidate {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var queued []kbCandidate
+	for _, c := range p.candidates {
+		if c.queued {
+			queued = append(queued, *c)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].CreatedAt.Before(queued[j].CreatedAt) })
+	return queued
+}
+
+// take removes a queued answer from the review queue and returns it.
+func (p *kbProposals) take(id string) (kbCandidate, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	c, ok := p.candidates[id]
+	if !ok || !c.queued {
+		return kbCandidate{}, false
+	}
+	delete(p.candidates, id)
+	return *c, true
+}
+
+// requeue puts an answer back in the review queue after a failed approval.
+func (p *kbProposals) requeue(c kbCandidate) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	c.queued = true
+	p.candidates[c.ID] = &c
+}
+
+// trainingData formats the answer in the /learn format expected by the KB training endpoint.
+func (c kbCandidate) trainingData() string {
+	category := c.Category
+	if category == "" {
+		category = "Uncategorized"
+	}
+	subCategory := c.Species
+	if subCategory == "" {
+		subCategory = "General"
+	}
+	return fmt.Sprintf("%s: %s: Q: %s A: %s", category, subCategory, c.Question, c.Answer)
+}
+
+// answerKeyboard returns the keyboard for an OpenAI answer: the 👍 button when KB proposals are enabled
+// and any suggested follow-up questions. It returns "" when neither applies.
+func (a *App) answerKeyboard(ctx context.Context, question, answer string, tags questionTags) string {
+	var rows [][]map[string]string
+	if a.kbProposals != nil {
+		if row := a.kbProposals.track(question, answer, tags); row != nil {
+			rows = append(rows, row)
+		}
+	}
+	return inlineKeyboard(append(rows, a.followUpRows(ctx, question, answer)...))
+}
+
+// handleHelpfulVote records a 👍 on an OpenAI answer and notifies the admin chat when the answer
+// reaches the vote threshold and is queued for review.
+func (a *App) handleHelpfulVote(callbackQuery *types.TelegramCallbackQuery) error {
+	a.acknowledgeCallback(callbackQuery.ID)
+	if a.kbProposals == nil {
+		return nil
+	}
+
+	id := strings.TrimPrefix(callbackQuery.Data, helpfulCallbackPrefix)
+	known, queued := a.kbProposals.vote(id, callbackQuery.From.ID)
+	if !known {
+		log.Printf("Ignoring vote for unknown or already reviewed answer %s", id)
+		return nil
+	}
+	if queued == nil {
+		return nil
+	}
+
+	log.Printf("Answer %s reached %d helpful votes and was queued for KB review", id, a.kbProposals.threshold)
+	if a.AdminChatID != 0 {
+		notice := fmt.Sprintf("📚 **KB proposal %s**\n\n**Question:** %s\n**Answer:** %s\n\nSend `/proposals approve %s` to add it to the Knowledge Base or `/proposals reject %s` to discard it.",
+			id, utils.EscapeMarkdown(queued.Question), utils.EscapeMarkdown(utils.SummarizeToLength(queued.Answer, 1000)), id, id)
+		if err := a.SendMessage(a.AdminChatID, notice, 0); err != nil {
+			log.Printf("Failed to send KB proposal to admin chat: %v", err)
+		}
+	}
+	return nil
+}
+
+// handleProposalsCommand lists queued KB proposals, or approves or rejects one. Approved answers are
+// sent to the KB training endpoint.
+func (a *App) handleProposalsCommand(ctx context.Context, args string) string {
+	if a.kbProposals == nil {
+		return "KB proposals are currently disabled."
+	}
+
+	action, id, _ := strings.Cut(strings.TrimSpace(args), " ")
+	action, id = strings.ToLower(action), strings.TrimSpace(id)
+	switch action {
+	case "":
+		queued := a.kbProposals.pending()
+		if len(queued) == 0 {
+			return "No answers are awaiting review."
+		}
+		var b strings.Builder
+		b.WriteString("📚 **Answers awaiting review**\n")
+		for _, c := range queued {
+			fmt.Fprintf(&b, "\n`%s` %s", c.ID, utils.EscapeMarkdown(utils.SummarizeToLength(c.Question, 100)))
+		}
+		b.WriteString("\n\nSend `/proposals approve ID` or `/proposals reject ID`.")
+		return b.String()
+
+	case "approve", "reject":
+		if id == "" {
+			return "Usage: /proposals [approve|reject] [ID]"
+		}
+		c, ok := a.kbProposals.take(id)
+		if !ok {
+			return fmt.Sprintf("No answer %s is awaiting review.", utils.EscapeMarkdown(id))
+		}
+		if action == "reject" {
+			return fmt.Sprintf("Discarded proposal %s.", c.ID)
+		}
+		if err := a.sendTrainingData(ctx, c.trainingData()); err != nil {
+			log.Printf("Failed to send KB proposal %s: %v", c.ID, err)
+			a.kbProposals.requeue(c)
+			return "Failed to add the answer to the Knowledge Base. It is still awaiting review."
+		}
+		return fmt.Sprintf("Added proposal %s to the Knowledge Base.", c.ID)
+
+	default:
+		return "Usage: /proposals [approve|reject] [ID]"
+	}
+}
@@ -0,0 +1,132 @@
+// internal/app/kb_proposals_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestKBProposalThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		voters     []int
+		wantQueued int // Index of the vote that queues the answer, or -1
+	}{
+		{"single vote threshold", 1, []int{7}, 0},
+		{"queued on the third voter", 3, []int{7, 8, 9}, 2},
+		{"repeat votes count once", 2, []int{7, 7, 7}, -1},
+		{"below threshold", 3, []int{7, 8}, -1},
+		{"queued only once", 2, []int{7, 8, 9}, 1},
+		{"threshold below one is raised to one", 0, []int{7}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newKBProposals(tt.threshold)
+			p.track("Best bait for bass?", "Try a plastic worm.", questionTags{})
+			id := kbCandidateID("Best bait for bass?", "Try a plastic worm.")
+
+			queuedAt := -1
+			for i, voter := range tt.voters {
+				known, queued := p.vote(id, voter)
+				if !known {
+					t.Fatalf("vote %d: answer unknown", i)
+				}
+				if queued != nil {
+					if queuedAt != -1 {
+						t.Fatalf("answer queued twice, at votes %d and %d", queuedAt, i)
+					}
+					queuedAt = i
+				}
+			}
+			if queuedAt != tt.wantQueued {
+				t.Errorf("queued at vote %d, want %d", queuedAt, tt.wantQueued)
+			}
+			want := 0
+			if tt.wantQueued >= 0 {
+				want = 1
+			}
+			if got := len(p.pending()); got != want {
+				t.Errorf("%d answers pending review, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestHelpfulVoteNotifiesAdminChat(t *testing.T) {
+	a := newTestApp(t)
+	a.kbProposals = newKBProposals(2)
+	a.AdminChatID = -100
+	a.kbProposals.track("Best bait for bass?", "Try a plastic worm.", questionTags{})
+	data := helpfulCallbackPrefix + kbCandidateID("Best bait for bass?", "Try a plastic worm.")
+
+	for _, voter := range []int{7, 8} {
+		if err := a.handleHelpfulVote(&types.TelegramCallbackQuery{ID: "cb", From: types.TelegramUser{ID: voter}, Data: data}); err != nil {
+			t.Fatalf("handleHelpfulVote error = %v", err)
+		}
+	}
+
+	sent := a.telegram.sent("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want one admin notice", len(sent))
+	}
+	if chatID, _ := sent[0].Payload["chat_id"].(float64); int64(chatID) != a.AdminChatID {
+		t.Errorf("notice sent to chat %v, want the admin chat", sent[0].Payload["chat_id"])
+	}
+	if got := len(a.telegram.sent("answerCallbackQuery")); got != 2 {
+		t.Errorf("acknowledged %d taps, want 2", got)
+	}
+}
+
+func TestProposalsCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		trainStatus int
+		wantReply   string
+		wantPending int
+		wantTrained int
+	}{
+		{"approve", "approve", http.StatusOK, "Added proposal", 0, 1},
+		{"reject", "reject", http.StatusOK, "Discarded proposal", 0, 0},
+		{"failed approval is requeued", "approve", http.StatusInternalServerError, "still awaiting review", 1, 1},
+		{"unknown action", "promote", http.StatusOK, "Usage:", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseURL = "https://kb.example.com/train"
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method == "train" {
+					return tt.trainStatus, `{}`
+				}
+				return 0, ""
+			}
+			a.kbProposals = newKBProposals(1)
+			a.kbProposals.track("Best bait for bass?", "Try a plastic worm.", questionTags{Category: "Bait"})
+			id := kbCandidateID("Best bait for bass?", "Try a plastic worm.")
+			a.kbProposals.vote(id, 7)
+
+			if reply := a.handleProposalsCommand(context.Background(), tt.action+" "+id); !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if got := len(a.kbProposals.pending()); got != tt.wantPending {
+				t.Errorf("%d answers pending review, want %d", got, tt.wantPending)
+			}
+			trained := a.telegram.sent("train")
+			if len(trained) != tt.wantTrained {
+				t.Fatalf("sent %d training requests, want %d", len(trained), tt.wantTrained)
+			}
+			if tt.wantTrained > 0 {
+				if data, _ := trained[0].Payload["data"].(string); data != "Bait: General: Q: Best bait for bass? A: Try a plastic worm." {
+					t.Errorf("training data = %q", data)
+				}
+			}
+		})
+	}
+}
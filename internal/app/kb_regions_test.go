@@ -0,0 +1,86 @@
+// internal/app/kb_regions_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestParseRegionEndpoints(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{"valid pairs", "East=https://kb-east.example.com/api, west=http://kb-west.example.com",
+			map[string]string{"east": "https://kb-east.example.com/api", "west": "http://kb-west.example.com"}},
+		{"missing scheme", "west=kb-west.example.com", map[string]string{}},
+		{"unsupported scheme", "west=ftp://kb-west.example.com", map[string]string{}},
+		{"unparsable URL", "west=https://kb west.example.com/%zz", map[string]string{}},
+		{"missing region", "=https://kb.example.com", map[string]string{}},
+		{"missing URL", "west", map[string]string{}},
+		{"bad entry among good", "west=nonsense,east=https://kb-east.example.com,",
+			map[string]string{"east": "https://kb-east.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRegionEndpoints(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRegionEndpoints(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKnowledgeBaseQueriesRouteByRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		want     string
+	}{
+		{"West Coast", "Best bait for steelhead on the Hoh River?", "west"},
+		{"East Coast", "Best bait for steelhead on the Salmon River?", "east"},
+		{"unknown region", "Best bait for bass?", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := make(map[string]*int32)
+			servers := make(map[string]string)
+			for _, name := range []string{"default", "east", "west"} {
+				count := new(int32)
+				hits[name] = count
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					atomic.AddInt32(count, 1)
+					w.Write([]byte(`[]`))
+				}))
+				t.Cleanup(server.Close)
+				servers[name] = server.URL
+			}
+
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient(servers["default"], "key")
+			a.KnowledgeBaseClient.RegionURLs = parseRegionEndpoints("east=" + servers["east"] + ",west=" + servers["west"])
+
+			if err := a.processMessage(context.Background(), 1, 7, "angler", tt.question, 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+			for name, count := range hits {
+				want := int32(0)
+				if name == tt.want {
+					want = 1
+				}
+				if got := atomic.LoadInt32(count); got != want {
+					t.Errorf("%s KB received %d queries, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// internal/app/learn_quota.go
+
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// learnQuota caps the /learn submissions each trainer may make per UTC day, independently of the message rate limit.
+type learnQuota struct {
+	limit         int
+	exemptNoLimit bool // NO_LIMIT_USERS are not capped
+	mutex         sync.Mutex
+	counts        map[int]learnCount
+}
+
+// learnCount is the number of submissions a trainer made on a given day.
+type learnCount struct {
+	day string // UTC date, e.g. "2024-05-01"
+	n   int
+}
+
+// newLearnQuota initializes a quota allowing limit submissions per trainer per day.
+func newLearnQuota(limit int, exemptNoLimit bool) *learnQuota {
+	return &learnQuota{
+		limit:         limit,
+		exemptNoLimit: exemptNoLimit,
+		counts:        make(map[int]learnCount),
+	}
+}
+
+// take records a submission for the user at now and reports whether it is within the day's limit.
+// Rejected submissions are not counted.
+func (q *learnQuota) take(userID int, now time.Time) bool {
+	day := now.UTC().Format("2006-01-02")
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	c := q.counts[userID]
+	if c.day != day {
+		c = learnCount{day: day}
+	}
+	if c.n >= q.limit {
+		return false
+	}
+	c.n++
+	q.counts[userID] = c
+
+	// Forget trainers whose count is from an earlier day
+	for id, other := range q.counts {
+		if other.day != day {
+			delete(q.counts, id)
+		}
+	}
+	return true
+}
+
+// refund gives back a submission that was counted but failed to reach the knowledge base.
+func (q *learnQuota) refund(userID int, now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if c := q.counts[userID]; c.day == day && c.n > 0 {
+		c.n--
+		q.counts[userID] = c
+	}
+}
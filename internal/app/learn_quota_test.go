@@ -0,0 +1,88 @@
+// internal/app/learn_quota_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLearnQuotaTake(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		limit  int
+		takes  []time.Time
+		refund bool // Refund the first submission before the last take
+		want   []bool
+	}{
+		{"within the limit", 2, []time.Time{day, day}, false, []bool{true, true}},
+		{"over the limit", 2, []time.Time{day, day, day}, false, []bool{true, true, false}},
+		{"next UTC day resets", 1, []time.Time{day, day, day.Add(12 * time.Hour)}, false, []bool{true, false, true}},
+		{"refund frees a submission", 1, []time.Time{day, day}, true, []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newLearnQuota(tt.limit, false)
+			for i, now := range tt.takes {
+				if tt.refund && i == len(tt.takes)-1 {
+					q.refund(7, now)
+				}
+				if got := q.take(7, now); got != tt.want[i] {
+					t.Errorf("take %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLearnDailyCap(t *testing.T) {
+	tests := []struct {
+		name        string
+		noLimitUser bool
+		exempt      bool
+		trainStatus int
+		wantTrained int
+	}{
+		{"capped after the limit", false, false, http.StatusOK, 2},
+		{"no-limit users are capped by default", true, false, http.StatusOK, 2},
+		{"exempt no-limit users", true, true, http.StatusOK, 3},
+		{"failed submissions are not counted", false, false, http.StatusInternalServerError, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.KnowledgeBaseActive = true
+			a.TrainingEnabled = true
+			a.TrainingAccess = accessPublic
+			a.KnowledgeBaseURL = "https://kb.example.com/train"
+			a.learnQuota = newLearnQuota(2, tt.exempt)
+			if tt.noLimitUser {
+				a.NoLimitUsers[7] = struct{}{}
+			}
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method == "train" {
+					return tt.trainStatus, `{}`
+				}
+				return 0, ""
+			}
+
+			for i := 0; i < 3; i++ {
+				if _, err := a.HandleCommand(context.Background(), commandMessage("/learn Techniques: Jigging: Lift and drop."), 7, "angler"); err != nil {
+					t.Fatalf("HandleCommand error = %v", err)
+				}
+			}
+
+			if got := len(a.telegram.sent("train")); got != tt.wantTrained {
+				t.Errorf("sent %d submissions to the KB, want %d", got, tt.wantTrained)
+			}
+			capped := strings.Contains(strings.Join(a.telegram.texts(), "\n"), "limit of 2 /learn submissions per day")
+			if want := tt.wantTrained < 3; capped != want {
+				t.Errorf("cap notice sent = %v, want %v", capped, want)
+			}
+		})
+	}
+}
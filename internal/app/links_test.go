@@ -0,0 +1,37 @@
+// internal/app/links_test.go
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/utils"
+)
+
+func TestEnrichLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		question string
+		answer   string
+		wantLink bool
+	}{
+		{"regulation question", true, "What are the rules on the Salmon River?", "The DEC sets a 1 fish limit.", true},
+		{"regulation answer", true, "Can I keep steelhead?", "The DEC bag limit is 1.", true},
+		{"not about regulations", true, "Best bait for steelhead?", "The DEC stocks egg-sucking leeches.", false},
+		{"enrichment disabled", false, "What are the rules on the Salmon River?", "The DEC sets a 1 fish limit.", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.LinkEnrichment = tt.enabled
+			a.AgencyLinks = utils.DefaultAgencyLinks
+
+			got := a.enrichLinks(tt.question, tt.answer)
+			if hasLink := strings.Contains(got, utils.DefaultAgencyLinks["DEC"]); hasLink != tt.wantLink {
+				t.Errorf("enrichLinks() = %q, want the DEC link: %v", got, tt.wantLink)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// internal/app/llm_provider_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestProcessMessageUsesTheLLMProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{"plain provider", false},
+		// A provider without CompleteStream is asked for the whole answer even when streaming is on
+		{"streaming on without stream support", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.StreamResponses = tt.stream
+			a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+				return "Provider says: use a jig.", nil
+			}
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+
+			call := a.llm.lastCall()
+			if len(call) < 2 || call[0].Role != "system" || call[len(call)-1].Content != "Best bass lure?" {
+				t.Fatalf("provider was asked %+v, want the system prompt and the question", call)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || texts[0] != "Provider says: use a jig."+helpFooter {
+				t.Errorf("sent %q, want the provider's answer", texts)
+			}
+			if len(a.telegram.sent("editMessageText")) != 0 {
+				t.Error("a non-streaming provider's answer was streamed")
+			}
+			rows := loggedRows(t, a)
+			if len(rows) != 1 || rows[0]["model"] != a.llm.ModelName() {
+				t.Errorf("logged %v, want one row answered by %q", rows, a.llm.ModelName())
+			}
+		})
+	}
+}
+
+func TestVoiceNeedsATranscribingProvider(t *testing.T) {
+	a := newTestApp(t)
+	a.VoiceMessages = true
+	serveVoiceFile(a)
+
+	message := &types.TelegramMessage{
+		MessageID: 10,
+		Chat:      types.TelegramChat{ID: 1},
+		From:      types.TelegramUser{ID: 7},
+		Voice:     &types.TelegramVoice{FileID: "abc", Duration: 3, FileSize: 1024},
+	}
+	transcript, err := a.TranscribeVoice(context.Background(), message)
+	if err == nil || transcript != "" {
+		t.Fatalf("TranscribeVoice() = %q, %v; want an error for a provider that can't transcribe", transcript, err)
+	}
+	if texts := a.telegram.texts(); len(texts) != 1 || !strings.Contains(texts[0], "can't understand voice messages") {
+		t.Errorf("sent %q, want the voice unavailable message", texts)
+	}
+}
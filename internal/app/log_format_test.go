@@ -0,0 +1,28 @@
+// internal/app/log_format_test.go
+
+package app
+
+import (
+	"testing"
+
+	s3client "ReelTalkBot-Go/internal/s3"
+)
+
+func TestParseLogFormat(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", s3client.LogFormatCSV},
+		{"csv", s3client.LogFormatCSV},
+		{" JSONL ", s3client.LogFormatJSONL},
+		{"parquet", s3client.LogFormatCSV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseLogFormat(tt.raw); got != tt.want {
+				t.Errorf("parseLogFormat(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// internal/app/logged_model_test.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/budget"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// loggedRows flushes the S3 logger and returns the CSV log's records keyed by column name.
+func loggedRows(t *testing.T, a *testApp) []map[string]string {
+	t.Helper()
+	a.Logger.Flush()
+	data, ok := a.store.object("logs/telegram_logs.csv")
+	if !ok {
+		t.Fatalf("no CSV log written; keys %v", a.store.keys())
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) == 0 {
+		t.Fatalf("CSV log is unreadable: %v", err)
+	}
+	var records []map[string]string
+	for _, row := range rows[1:] {
+		record := make(map[string]string)
+		for i, name := range rows[0] {
+			if i < len(row) {
+				record[name] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogRecordsServingModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		degraded  bool
+		wantModel string
+	}{
+		{"primary model", false, "fake-model"},
+		{"budget fallback model", true, "cheap-model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.Budget = budget.NewTracker(0.01, time.Hour, nil, nil)
+			a.BudgetFallbackModel = "cheap-model"
+			if tt.degraded {
+				a.Budget.Record("gpt-4o", types.OpenAIUsage{CompletionTokens: 1000})
+			}
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			rows := loggedRows(t, a)
+			if len(rows) != 1 {
+				t.Fatalf("logged %d records, want 1", len(rows))
+			}
+			if got := rows[0]["model"]; got != tt.wantModel {
+				t.Errorf("logged model %q, want %q", got, tt.wantModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// internal/app/markdown_fallback_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsMarkdownParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"parse error", errors.New("Bad Request: can't parse entities: Can't find end of the entity starting at byte offset 12"), true},
+		{"other bad request", errors.New("Bad Request: chat not found"), false},
+		{"no error", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMarkdownParseError(tt.err); got != tt.want {
+				t.Errorf("isMarkdownParseError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnparseableMarkdownIsResentAsPlainText(t *testing.T) {
+	const text = "Use a **jig** near 5*3 ft"
+	tests := []struct {
+		name   string
+		method string
+		send   func(a *testApp) error
+	}{
+		{"new message", "sendMessage", func(a *testApp) error {
+			_, err := a.sendMessageWithReply(context.Background(), 1, text, 10, nil, "")
+			return err
+		}},
+		{"edited message", "editMessageText", func(a *testApp) error {
+			return a.editMessageText(context.Background(), 1, 1001, text, "Markdown", "")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcomes := []struct {
+				name         string
+				rejectMarkup bool
+				wantCalls    int
+			}{
+				{"sanitized Markdown accepted", false, 1},
+				{"Markdown rejected", true, 2},
+			}
+			for _, tc := range outcomes {
+				t.Run(tc.name, func(t *testing.T) {
+					a := newTestApp(t)
+					a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+						if tc.rejectMarkup && method == tt.method && payload["parse_mode"] != nil {
+							return http.StatusBadRequest, `{"ok":false,"description":"Bad Request: can't parse entities: Can't find end of the entity"}`
+						}
+						return 0, ""
+					}
+
+					if err := tt.send(a); err != nil {
+						t.Fatalf("send failed: %v", err)
+					}
+					calls := a.telegram.sent(tt.method)
+					if len(calls) != tc.wantCalls {
+						t.Fatalf("made %d %s requests, want %d", len(calls), tt.method, tc.wantCalls)
+					}
+					if got := calls[0].Payload["text"]; got != `Use a *jig* near 5\*3 ft` {
+						t.Errorf("first request text = %q, want it sanitized", got)
+					}
+					if tc.rejectMarkup {
+						last := calls[len(calls)-1].Payload
+						if _, ok := last["parse_mode"]; ok || last["text"] != text {
+							t.Errorf("retry payload = %v, want the original text without parse_mode", last)
+						}
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// internal/app/message_batch.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// Default caps on a batch of messages answered as one question
+const (
+	defaultBatchMaxMessages = 5
+	defaultBatchMaxBytes    = 4096
+)
+
+// batchedMessage is one message held in a batch.
+type batchedMessage struct {
+	id   int
+	text string
+}
+
+// pendingBatch holds the messages a user sent in a chat since their batch window opened.
+type pendingBatch struct {
+	chatID    int64
+	userID    int
+	username  string
+	messages  []batchedMessage
+	bytes     int               // Total length of the messages' text
+	messageID int               // The first message, which the answer replies to
+	meta      types.MessageMeta // Quote details of the first message
+	timer     *time.Timer
+}
+
+// batchProcessor answers count messages combined into one question.
+type batchProcessor func(ctx context.Context, chatID int64, userID int, username, userQuestion string, messageID int, meta types.MessageMeta, count int) error
+
+// messageBatcher collects the messages each user sends within window of their first one and answers
+// them as a single question, so a thought split over several quick messages gets one answer.
+// A batch is answered early once it holds maxMessages messages or another message would take it past maxBytes.
+type messageBatcher struct {
+	window      time.Duration
+	maxMessages int
+	maxBytes    int
+	process     batchProcessor
+	newCtx      func() (context.Context, context.CancelFunc)
+
+	mutex   sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+// newMessageBatcher initializes a batcher that passes each combined question to process,
+// under a context from newCtx since the updates that delivered the messages have finished by then.
+func newMessageBatcher(window time.Duration, maxMessages, maxBytes int, process batchProcessor, newCtx func() (context.Context, context.CancelFunc)) *messageBatcher {
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+	return &messageBatcher{
+		window:      window,
+		maxMessages: maxMessages,
+		maxBytes:    maxBytes,
+		process:     process,
+		newCtx:      newCtx,
+		pending:     make(map[string]*pendingBatch),
+	}
+}
+
+// add queues a message, opening a new batch window if the user has none open in the chat. An edit of a
+// message still waiting in the batch replaces its text. A message that doesn't fit answers the open batch
+// right away and starts a new one.
+func (b *messageBatcher) add(chatID int64, userID int, username, text string, messageID int, meta types.MessageMeta) {
+	key := fmt.Sprintf("%d:%d", chatID, userID)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if batch, ok := b.pending[key]; ok {
+		if meta.Edited && batch.replace(messageID, text) {
+			return
+		}
+		if len(batch.messages) < b.maxMessages && (b.maxBytes <= 0 || batch.bytes+len(text) <= b.maxBytes) {
+			batch.messages = append(batch.messages, batchedMessage{id: messageID, text: text})
+			batch.bytes += len(text)
+			return
+		}
+		// The open batch is full, so answer it now; its timer finds it gone and does nothing
+		batch.timer.Stop()
+		delete(b.pending, key)
+		go b.answer(batch)
+	}
+
+	batch := &pendingBatch{
+		chatID:    chatID,
+		userID:    userID,
+		username:  username,
+		messages:  []batchedMessage{{id: messageID, text: text}},
+		bytes:     len(text),
+		messageID: messageID,
+		meta:      meta,
+	}
+	batch.timer = time.AfterFunc(b.window, func() { b.flush(key, batch) })
+	b.pending[key] = batch
+}
+
+// replace swaps the text of a message in the batch for its edited version, reporting whether it was found.
+func (p *pendingBatch) replace(messageID int, text string) bool {
+	for i := range p.messages {
+		if p.messages[i].id == messageID {
+			p.bytes += len(text) - len(p.messages[i].text)
+			p.messages[i].text = text
+			return true
+		}
+	}
+	return false
+}
+
+// flush answers a batch once its window has closed, unless it was already answered.
+func (b *messageBatcher) flush(key string, batch *pendingBatch) {
+	b.mutex.Lock()
+	if b.pending[key] != batch {
+		b.mutex.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.mutex.Unlock()
+
+	b.answer(batch)
+}
+
+// answer passes a batch's combined question to the processor.
+func (b *messageBatcher) answer(batch *pendingBatch) {
+	texts := make([]string, len(batch.messages))
+	for i, message := range batch.messages {
+		texts[i] = message.text
+	}
+	if len(texts) > 1 {
+		log.Printf("Answering %d messages from user %d in chat %d as one question", len(texts), batch.userID, batch.chatID)
+	}
+	ctx, cancel := b.newCtx()
+	defer cancel()
+	if err := b.process(ctx, batch.chatID, batch.userID, batch.username, strings.Join(texts, "\n"), batch.messageID, batch.meta, len(texts)); err != nil {
+		log.Printf("Error processing batched messages: %v", err)
+	}
+}
+
+// flushAll answers every open batch immediately, for shutdown.
+func (b *messageBatcher) flushAll() {
+	b.mutex.Lock()
+	batches := make([]*pendingBatch, 0, len(b.pending))
+	for key, batch := range b.pending {
+		batch.timer.Stop()
+		delete(b.pending, key) // A timer that already fired finds its batch gone
+		batches = append(batches, batch)
+	}
+	b.mutex.Unlock()
+
+	for _, batch := range batches {
+		b.answer(batch)
+	}
+}
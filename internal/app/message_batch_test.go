@@ -0,0 +1,105 @@
+// internal/app/message_batch_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// batchedMessageInput is a message sent to ProcessMessage while batching is enabled.
+type batchedMessageInput struct {
+	id     int
+	text   string
+	edited bool
+}
+
+func TestMessageBatching(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxMessages int
+		maxBytes    int
+		messages    []batchedMessageInput
+		wantCalls   []string // Questions sent to OpenAI, in order
+		wantCharged int
+	}{
+		{
+			name:        "two messages in the window make one call",
+			maxMessages: 5,
+			messages:    []batchedMessageInput{{id: 1, text: "Best bait for bass"}, {id: 2, text: "in a muddy pond?"}},
+			wantCalls:   []string{"Best bait for bass\nin a muddy pond?"},
+			wantCharged: 2,
+		},
+		{
+			name:        "message count cap starts a new batch",
+			maxMessages: 2,
+			messages:    []batchedMessageInput{{id: 1, text: "one"}, {id: 2, text: "two"}, {id: 3, text: "three"}},
+			wantCalls:   []string{"one\ntwo", "three"},
+			wantCharged: 3,
+		},
+		{
+			name:        "byte cap starts a new batch",
+			maxMessages: 5,
+			maxBytes:    10,
+			messages:    []batchedMessageInput{{id: 1, text: "12345"}, {id: 2, text: "67890"}, {id: 3, text: "x"}},
+			wantCalls:   []string{"12345\n67890", "x"},
+			wantCharged: 3,
+		},
+		{
+			name:        "edit replaces the pending message",
+			maxMessages: 5,
+			messages:    []batchedMessageInput{{id: 1, text: "Best biat?"}, {id: 1, text: "Best bait?", edited: true}},
+			wantCalls:   []string{"Best bait?"},
+			wantCharged: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.messageBatcher = newMessageBatcher(50*time.Millisecond, tt.maxMessages, tt.maxBytes, a.processMessages, func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			})
+			for _, m := range tt.messages {
+				if err := a.ProcessMessage(context.Background(), 1, 7, "angler", m.text, m.id, types.MessageMeta{Edited: m.edited}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			waitFor(t, "batched answers", func() bool { return a.llm.callCount() >= len(tt.wantCalls) })
+			time.Sleep(100 * time.Millisecond) // Let any unexpected extra batch arrive
+
+			a.llm.mutex.Lock()
+			calls := a.llm.calls
+			a.llm.mutex.Unlock()
+			if len(calls) != len(tt.wantCalls) {
+				t.Fatalf("made %d OpenAI calls, want %d", len(calls), len(tt.wantCalls))
+			}
+			got := map[string]bool{}
+			for _, call := range calls {
+				got[call[len(call)-1].Content] = true
+			}
+			for _, want := range tt.wantCalls {
+				if !got[want] {
+					t.Errorf("no OpenAI call asked %q; got %v", want, got)
+				}
+			}
+			if charged := a.usedMessages(7); charged != tt.wantCharged {
+				t.Errorf("charged %d messages, want %d", charged, tt.wantCharged)
+			}
+		})
+	}
+}
+
+func TestMessageBatcherFlushAll(t *testing.T) {
+	a := newTestApp(t)
+	a.messageBatcher = newMessageBatcher(time.Hour, 5, 0, a.processMessages, func() (context.Context, context.CancelFunc) {
+		return context.WithCancel(context.Background())
+	})
+	a.ProcessMessage(context.Background(), 1, 7, "angler", "Still there?", 1, types.MessageMeta{})
+	a.messageBatcher.flushAll()
+	if got := a.llm.callCount(); got != 1 {
+		t.Fatalf("flushAll answered %d batches, want 1", got)
+	}
+}
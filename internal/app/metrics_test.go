@@ -0,0 +1,112 @@
+// internal/app/metrics_test.go
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/metrics"
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/usage"
+)
+
+// scrapeMetrics returns the unlabelled values /metrics reports, keyed by series name.
+func scrapeMetrics(t *testing.T) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	values := make(map[string]float64)
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.HasPrefix(fields[0], "#") || strings.Contains(fields[0], "{") {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			t.Fatalf("unparseable metric line %q", scanner.Text())
+		}
+		values[fields[0]] = value
+	}
+	return values
+}
+
+func TestAnswersAreCounted(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(a *testApp)
+		want  map[string]float64 // Expected increase of each series
+	}{
+		{
+			"answered by OpenAI",
+			func(a *testApp) {},
+			map[string]float64{
+				"reeltalkbot_messages_processed_total":      1,
+				"reeltalkbot_openai_calls_total":            1,
+				"reeltalkbot_openai_response_seconds_count": 1,
+				"reeltalkbot_knowledge_base_hits_total":     0,
+				"reeltalkbot_errors_total":                  0,
+			},
+		},
+		{
+			"answered from the Knowledge Base",
+			func(a *testApp) {
+				a.KnowledgeBaseActive = true
+				a.KnowledgeBaseClient = newTestKB(t, []types.KnowledgeEntryResponse{
+					{KBNumber: 1, QuestionTemplate: "Best bass lure", Answer: "A jig."},
+				})
+			},
+			map[string]float64{
+				"reeltalkbot_messages_processed_total":  1,
+				"reeltalkbot_knowledge_base_hits_total": 1,
+				"reeltalkbot_openai_calls_total":        0,
+			},
+		},
+		{
+			"rate limited",
+			func(a *testApp) {
+				a.UsageCache = usage.NewUsageCache(1, time.Hour)
+				a.UsageCache.AddUsage(7)
+			},
+			map[string]float64{
+				"reeltalkbot_messages_processed_total": 1,
+				"reeltalkbot_rate_limited_total":       1,
+				"reeltalkbot_openai_calls_total":       0,
+			},
+		},
+		{
+			"OpenAI failure",
+			func(a *testApp) {
+				a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+					return "", &types.APIError{Service: "OpenAI", StatusCode: 400}
+				}
+			},
+			map[string]float64{
+				"reeltalkbot_messages_processed_total": 1,
+				"reeltalkbot_errors_total":             1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			tt.setup(a)
+
+			before := scrapeMetrics(t)
+			a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{})
+			after := scrapeMetrics(t)
+
+			for series, want := range tt.want {
+				if got := after[series] - before[series]; got != want {
+					t.Errorf("%s rose by %v, want %v", series, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// internal/app/openai_errors_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"ReelTalkBot-Go/internal/api"
+	"ReelTalkBot-Go/internal/budget"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestOpenAIErrorsAreExplained(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantText string
+	}{
+		{"content filter", api.ErrContentFiltered, defaultContentFilterMessage},
+		{"budget exceeded", budget.ErrBudgetExceeded, budgetExceededMessage},
+		{"OpenAI rate limit", &types.APIError{Service: "OpenAI", StatusCode: 429}, overCapacityMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.llm.answer = func([]types.OpenAIMessage) (string, error) { return "", tt.err }
+
+			if err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("processMessage() error = %v, want the failure handled", err)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || texts[0] != tt.wantText {
+				t.Errorf("sent %q, want %q", texts, tt.wantText)
+			}
+		})
+	}
+}
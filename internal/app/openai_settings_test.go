@@ -0,0 +1,55 @@
+// internal/app/openai_settings_test.go
+
+package app
+
+import (
+	"testing"
+
+	"ReelTalkBot-Go/internal/api"
+)
+
+func TestParseTemperature(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{"", api.DefaultTemperature},
+		{"0", 0},
+		{"0.2", 0.2},
+		{" 1.5 ", 1.5},
+		{"2", api.MaxTemperature},
+		{"2.1", api.DefaultTemperature},
+		{"-0.1", api.DefaultTemperature},
+		{"NaN", api.DefaultTemperature},
+		{"warm", api.DefaultTemperature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseTemperature(tt.raw); got != tt.want {
+				t.Errorf("parseTemperature(%q) = %g, want %g", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMaxTokens(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{"", api.DefaultMaxTokens},
+		{"1024", 1024},
+		{"16", api.MinMaxTokens},
+		{"16384", api.MaxMaxTokens},
+		{"15", api.DefaultMaxTokens},
+		{"16385", api.DefaultMaxTokens},
+		{"lots", api.DefaultMaxTokens},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseMaxTokens(tt.raw); got != tt.want {
+				t.Errorf("parseMaxTokens(%q) = %d, want %d", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// internal/app/process_retry_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"wrapped timeout", fmt.Errorf("OpenAI: %w", context.DeadlineExceeded), true},
+		{"truncated body", io.ErrUnexpectedEOF, true},
+		{"API rejection", &types.APIError{Service: "OpenAI", StatusCode: 400}, false},
+		{"delivery failure", &deliveryError{&net.OpError{Op: "write", Err: errors.New("broken pipe")}}, false},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransientFailuresAreRetried(t *testing.T) {
+	transient := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	tests := []struct {
+		name      string
+		retries   int
+		failures  []error // Returned by OpenAI on successive calls before it answers
+		wantCalls int
+		wantReply bool
+	}{
+		{"transient failure then success", 1, []error{transient}, 2, true},
+		{"retries disabled", 0, []error{transient}, 1, false},
+		{"retries exhausted", 1, []error{transient, transient}, 2, false},
+		{"permanent failure", 1, []error{&types.APIError{Service: "OpenAI", StatusCode: 400}}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ProcessRetries = tt.retries
+			calls := 0
+			a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+				calls++
+				if calls <= len(tt.failures) {
+					return "", tt.failures[calls-1]
+				}
+				return "Use a jig.", nil
+			}
+
+			err := a.processMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{})
+			if (err == nil) != tt.wantReply {
+				t.Errorf("processMessage() error = %v, want a reply: %v", err, tt.wantReply)
+			}
+			if got := a.llm.callCount(); got != tt.wantCalls {
+				t.Errorf("OpenAI was called %d times, want %d", got, tt.wantCalls)
+			}
+			replies := 0
+			for _, text := range a.telegram.texts() {
+				if strings.Contains(text, "Use a jig.") {
+					replies++
+				}
+			}
+			if want := map[bool]int{true: 1, false: 0}[tt.wantReply]; replies != want {
+				t.Errorf("sent the answer %d times, want %d", replies, want)
+			}
+			if got := a.usedMessages(7); got != 1 {
+				t.Errorf("charged %d messages, want 1", got)
+			}
+		})
+	}
+}
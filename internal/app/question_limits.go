@@ -0,0 +1,42 @@
+// internal/app/question_limits.go
+
+package app
+
+import "ReelTalkBot-Go/internal/usage"
+
+// questionLimits holds the rate limits, in-flight cap, and per-user locks applied to one platform's questions.
+// User and chat IDs are only unique within a platform, so each adapter has its own limits; otherwise a
+// WhatsApp number or Discord snowflake equal to a Telegram user ID would share that user's limits.
+type questionLimits struct {
+	usage    *usage.UsageCache
+	inflight *userInflight    // nil disables the in-flight cap
+	locks    *userLocks       // nil leaves messages unserialized
+	noLimit  map[int]struct{} // Users exempt from the per-user limits; nil for adapters
+}
+
+// telegramLimits returns the limits applied to Telegram questions.
+func (a *App) telegramLimits() *questionLimits {
+	return &questionLimits{usage: a.UsageCache, inflight: a.userInflight, locks: a.userLocks, noLimit: a.NoLimitUsers}
+}
+
+// platformLimits returns the limits applied to an adapter platform's questions, created on first use with
+// the same settings as Telegram's. NO_LIMIT_USERS lists Telegram IDs, so no adapter user is exempt.
+func (a *App) platformLimits(platform string) *questionLimits {
+	a.adapterLimitsMutex.Lock()
+	defer a.adapterLimitsMutex.Unlock()
+	if limits, ok := a.adapterLimits[platform]; ok {
+		return limits
+	}
+	limits := &questionLimits{usage: a.UsageCache.EmptyCopy()}
+	if a.userInflight != nil {
+		limits.inflight = newUserInflight(a.userInflight.limit)
+	}
+	if a.userLocks != nil {
+		limits.locks = newUserLocks()
+	}
+	if a.adapterLimits == nil {
+		a.adapterLimits = make(map[string]*questionLimits)
+	}
+	a.adapterLimits[platform] = limits
+	return limits
+}
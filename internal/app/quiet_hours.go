@@ -0,0 +1,71 @@
+// internal/app/quiet_hours.go
+
+package app
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultQuietHoursMessage is sent during quiet hours; {resume} is replaced with the local time answers resume.
+const defaultQuietHoursMessage = "ReelTalkBot is offline overnight to keep costs low. I'll be back at {resume}."
+
+// QuietHours is a daily window during which questions are not sent to OpenAI.
+type QuietHours struct {
+	StartHour int            // First quiet hour (0-23)
+	EndHour   int            // Hour answers resume (0-23); may be earlier than StartHour to wrap past midnight
+	Location  *time.Location // Time zone the hours are expressed in
+	Message   string         // Reply sent during quiet hours
+}
+
+// loadQuietHours reads QUIET_HOURS, QUIET_HOURS_TZ, and QUIET_HOURS_MESSAGE. It returns nil when quiet hours are off.
+func loadQuietHours() *QuietHours {
+	raw := os.Getenv("QUIET_HOURS")
+	if raw == "" {
+		return nil
+	}
+	start, end, ok := parseHourRange(raw)
+	if !ok || start == end {
+		log.Printf("Invalid QUIET_HOURS %q. Quiet hours are disabled.", raw)
+		return nil
+	}
+
+	location := time.UTC
+	if tz := strings.TrimSpace(os.Getenv("QUIET_HOURS_TZ")); tz != "" {
+		loaded, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Printf("Invalid QUIET_HOURS_TZ %q: %v. Using UTC.", tz, err)
+		} else {
+			location = loaded
+		}
+	}
+
+	message := os.Getenv("QUIET_HOURS_MESSAGE")
+	if message == "" {
+		message = defaultQuietHoursMessage
+	}
+
+	log.Printf("Quiet hours enabled from %02d:00 to %02d:00 %s", start, end, location)
+	return &QuietHours{StartHour: start, EndHour: end, Location: location, Message: message}
+}
+
+// Active reports whether now falls within the quiet hours.
+func (q *QuietHours) Active(now time.Time) bool {
+	hour := now.In(q.Location).Hour()
+	if q.StartHour < q.EndHour {
+		return hour >= q.StartHour && hour < q.EndHour
+	}
+	return hour >= q.StartHour || hour < q.EndHour
+}
+
+// Notice returns the quiet-hours reply with the resume time filled in.
+func (q *QuietHours) Notice(now time.Time) string {
+	local := now.In(q.Location)
+	resume := time.Date(local.Year(), local.Month(), local.Day(), q.EndHour, 0, 0, 0, q.Location)
+	if !resume.After(local) {
+		resume = resume.AddDate(0, 0, 1)
+	}
+	return strings.ReplaceAll(q.Message, "{resume}", resume.Format("3:04 PM MST"))
+}
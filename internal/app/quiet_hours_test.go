@@ -0,0 +1,125 @@
+// internal/app/quiet_hours_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		now   time.Time
+		want  bool
+	}{
+		{"inside overnight window", QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC}, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), true},
+		{"after midnight", QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC}, time.Date(2026, 10, 16, 5, 59, 0, 0, time.UTC), true},
+		{"resume hour is awake", QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC}, time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC), false},
+		{"daytime window", QuietHours{StartHour: 12, EndHour: 14, Location: time.UTC}, time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC), true},
+		{"outside daytime window", QuietHours{StartHour: 12, EndHour: 14, Location: time.UTC}, time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC), false},
+		{"hours are in the configured zone", QuietHours{StartHour: 22, EndHour: 6, Location: newYork}, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Active(tt.now); got != tt.want {
+				t.Errorf("Active(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursNotice(t *testing.T) {
+	quiet := QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC, Message: defaultQuietHoursMessage}
+	want := "ReelTalkBot is offline overnight to keep costs low. I'll be back at 6:00 AM UTC."
+	for _, now := range []time.Time{
+		time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+	} {
+		if got := quiet.Notice(now); got != want {
+			t.Errorf("Notice(%v) = %q, want %q", now, got, want)
+		}
+	}
+}
+
+func TestQuietHoursShortCircuitQuestions(t *testing.T) {
+	tests := []struct {
+		name       string
+		now        time.Time
+		noLimit    bool
+		wantOpenAI bool
+	}{
+		{"during quiet hours", time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), false, false},
+		{"outside quiet hours", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), false, true},
+		{"no-limit admin bypasses", time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.QuietHours = &QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC, Message: "Offline until {resume}"}
+			a.now = func() time.Time { return tt.now }
+			if tt.noLimit {
+				a.NoLimitUsers[7] = struct{}{}
+			}
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			if got := a.llm.callCount() == 1; got != tt.wantOpenAI {
+				t.Errorf("OpenAI queried = %v, want %v", got, tt.wantOpenAI)
+			}
+			texts := a.telegram.texts()
+			if !tt.wantOpenAI && (len(texts) != 1 || texts[0] != "Offline until 6:00 AM UTC") {
+				t.Errorf("sent %q, want the quiet hours notice", texts)
+			}
+		})
+	}
+}
+
+func TestLoadQuietHours(t *testing.T) {
+	tests := []struct {
+		name      string
+		hours     string
+		tz        string
+		wantNil   bool
+		wantStart int
+		wantEnd   int
+		wantZone  string
+	}{
+		{"unset", "", "", true, 0, 0, ""},
+		{"overnight", "22-6", "", false, 22, 6, "UTC"},
+		{"with time zone", "23-7", "America/Chicago", false, 23, 7, "America/Chicago"},
+		{"invalid time zone uses UTC", "22-6", "Mars/Olympus", false, 22, 6, "UTC"},
+		{"empty range", "6-6", "", true, 0, 0, ""},
+		{"garbage", "late", "", true, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("QUIET_HOURS", tt.hours)
+			t.Setenv("QUIET_HOURS_TZ", tt.tz)
+			t.Setenv("QUIET_HOURS_MESSAGE", "")
+
+			quiet := loadQuietHours()
+			if (quiet == nil) != tt.wantNil {
+				t.Fatalf("loadQuietHours() = %+v, want nil %v", quiet, tt.wantNil)
+			}
+			if quiet == nil {
+				return
+			}
+			if quiet.StartHour != tt.wantStart || quiet.EndHour != tt.wantEnd || quiet.Location.String() != tt.wantZone {
+				t.Errorf("loaded %d-%d %s, want %d-%d %s", quiet.StartHour, quiet.EndHour, quiet.Location, tt.wantStart, tt.wantEnd, tt.wantZone)
+			}
+			if quiet.Message != defaultQuietHoursMessage {
+				t.Errorf("message = %q, want the default", quiet.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// internal/app/quote_reply_test.go
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestQuoteReplies(t *testing.T) {
+	quoted := types.MessageMeta{
+		Quote:           &types.TelegramTextQuote{Text: "Creel limit: 2 per day", Position: 140},
+		QuotedMessageID: 55,
+	}
+	tests := []struct {
+		name           string
+		enabled        bool
+		meta           types.MessageMeta
+		wantParameters map[string]interface{}
+		wantReplyTo    interface{}
+	}{
+		{"quote sent with reply_parameters", true, quoted, map[string]interface{}{
+			"message_id":                  float64(55),
+			"quote":                       "Creel limit: 2 per day",
+			"quote_position":              float64(140),
+			"allow_sending_without_reply": true,
+		}, nil},
+		{"disabled replies to the message", false, quoted, nil, float64(10)},
+		{"no quote replies to the message", true, types.MessageMeta{}, nil, float64(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.QuoteReplies = tt.enabled
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Does this apply to perch?", 10, tt.meta); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			sent := a.telegram.sent("sendMessage")
+			if len(sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(sent))
+			}
+			payload := sent[0].Payload
+
+			parameters, _ := payload["reply_parameters"].(map[string]interface{})
+			if !reflect.DeepEqual(parameters, tt.wantParameters) {
+				t.Errorf("reply_parameters = %v, want %v", parameters, tt.wantParameters)
+			}
+			if payload["reply_to_message_id"] != tt.wantReplyTo {
+				t.Errorf("reply_to_message_id = %v, want %v", payload["reply_to_message_id"], tt.wantReplyTo)
+			}
+		})
+	}
+}
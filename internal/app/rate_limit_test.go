@@ -0,0 +1,115 @@
+// internal/app/rate_limit_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/usage"
+)
+
+func TestRateLimitNoticeCooldown(t *testing.T) {
+	tests := []struct {
+		name        string
+		chatID      int64
+		cooldown    time.Duration
+		users       []int // Each entry sends two messages; all but a user's first are over the limit
+		wantNotices int
+	}{
+		{"group, one user", -100, time.Minute, []int{7, 7}, 1},
+		{"group, several users", -100, time.Minute, []int{7, 8, 9}, 1},
+		{"group, cooldown disabled", -100, 0, []int{7, 8}, 2},
+		{"private chat", 7, time.Minute, []int{7, 7}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.UsageCache = usage.NewUsageCache(1, time.Hour)
+			a.RateLimitCooldown = tt.cooldown
+
+			messageID := 10
+			for _, userID := range tt.users {
+				for i := 0; i < 2; i++ {
+					messageID++
+					a.processMessage(context.Background(), tt.chatID, userID, "angler", "Best bait for bass?", messageID, types.MessageMeta{})
+				}
+			}
+
+			notices := 0
+			for _, text := range a.telegram.texts() {
+				if strings.HasPrefix(text, "Thanks for using ReelTalkBot. We restrict to") {
+					notices++
+				}
+			}
+			if notices != tt.wantNotices {
+				t.Errorf("sent %d rate-limit notices, want %d", notices, tt.wantNotices)
+			}
+		})
+	}
+}
+
+func TestRateLimitMessageMatchesConfiguredLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		duration time.Duration
+		want     string
+	}{
+		{"default", usage.DefaultLimit, usage.DefaultWindow, "We restrict to 10 messages per 10 minutes"},
+		{"custom", 2, 30 * time.Second, "We restrict to 2 messages per 30 seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.UsageCache = usage.NewUsageCache(tt.limit, tt.duration)
+
+			for i := 0; i <= tt.limit; i++ {
+				a.processMessage(context.Background(), 7, 7, "angler", "Best bait for bass?", 10+i, types.MessageMeta{})
+			}
+
+			texts := a.telegram.texts()
+			if len(texts) != tt.limit+1 || !strings.Contains(texts[tt.limit], tt.want) {
+				t.Errorf("last message = %q, want it to contain %q", texts[len(texts)-1], tt.want)
+			}
+			if a.llm.callCount() != tt.limit {
+				t.Errorf("queried OpenAI %d times, want %d", a.llm.callCount(), tt.limit)
+			}
+		})
+	}
+}
+
+func TestChatLimitAppliesToEveryone(t *testing.T) {
+	tests := []struct {
+		name    string
+		noLimit bool
+	}{
+		{"regular user", false},
+		{"no-limit user", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.UsageCache.SetChatLimit(2, time.Hour)
+			if tt.noLimit {
+				a.NoLimitUsers[9] = struct{}{}
+			}
+
+			// Two users use up the chat's shared limit without reaching their own
+			a.processMessage(context.Background(), -100, 7, "angler", "Best bait for bass?", 11, types.MessageMeta{})
+			a.processMessage(context.Background(), -100, 8, "angler", "Best bait for trout?", 12, types.MessageMeta{})
+			a.processMessage(context.Background(), -100, 9, "angler", "Best bait for perch?", 13, types.MessageMeta{})
+
+			if a.llm.callCount() != 2 {
+				t.Errorf("queried OpenAI %d times, want 2", a.llm.callCount())
+			}
+			texts := a.telegram.texts()
+			if len(texts) != 3 || !strings.Contains(texts[2], "This chat has reached its shared limit of 2 messages per 1 hour") {
+				t.Errorf("sent %q, want the chat limit notice last", texts)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// internal/app/reply_channel.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// replyChannel delivers the replies to one question, so the answer pipeline can serve Telegram as well as
+// adapters that send replies themselves, such as Discord and WhatsApp.
+type replyChannel interface {
+	// conversationKey returns the key the user's conversation history is kept under.
+	conversationKey(userID int) string
+	// notice sends a short message such as a rate-limit or busy notice.
+	notice(ctx context.Context, text string) error
+	// answer sends an answer, attaching keyboard where the platform supports inline keyboards.
+	answer(ctx context.Context, text, keyboard string) error
+	// interactive reports whether the platform supports inline keyboards and streamed answers.
+	interactive() bool
+	// limits returns the rate limits applied to the platform's users.
+	limits() *questionLimits
+}
+
+// telegramChannel replies to a Telegram message.
+type telegramChannel struct {
+	app       *App
+	chatID    int64
+	messageID int
+	meta      types.MessageMeta
+}
+
+func (c *telegramChannel) conversationKey(userID int) string {
+	return c.app.conversationKey(userID)
+}
+
+func (c *telegramChannel) notice(ctx context.Context, text string) error {
+	return c.app.sendMessage(ctx, c.chatID, text, c.messageID)
+}
+
+func (c *telegramChannel) answer(ctx context.Context, text, keyboard string) error {
+	return c.app.sendAnswer(ctx, c.chatID, text, c.messageID, c.meta, keyboard)
+}
+
+func (c *telegramChannel) interactive() bool {
+	return true
+}
+
+func (c *telegramChannel) limits() *questionLimits {
+	return c.app.telegramLimits()
+}
+
+// textChannel collects the replies for an adapter that returns them as plain text.
+type textChannel struct {
+	app      *App
+	platform string // Keeps the platform's conversations apart from Telegram ones, e.g. "discord"
+
+	mutex   sync.Mutex
+	replies []string
+}
+
+func (c *textChannel) conversationKey(userID int) string {
+	return c.app.namespacedKey(fmt.Sprintf("%s_%d", c.platform, userID))
+}
+
+func (c *textChannel) notice(ctx context.Context, text string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.replies = append(c.replies, text)
+	return nil
+}
+
+func (c *textChannel) answer(ctx context.Context, text, keyboard string) error {
+	return c.notice(ctx, text)
+}
+
+func (c *textChannel) interactive() bool {
+	return false
+}
+
+func (c *textChannel) limits() *questionLimits {
+	return c.app.platformLimits(c.platform)
+}
+
+// text returns the collected replies joined by blank lines.
+func (c *textChannel) text() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return strings.Join(c.replies, "\n\n")
+}
@@ -0,0 +1,60 @@
+// internal/app/retry.go
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// retryWindow is how long after a question is asked /retry can re-ask it.
+const retryWindow = time.Hour
+
+// askedQuestion is a charged question remembered so /retry can re-ask it as it was first sent.
+type askedQuestion struct {
+	Question  string            `json:"question"`
+	MessageID int               `json:"message_id"`
+	Meta      types.MessageMeta `json:"meta"`
+}
+
+// lastQuestionKey identifies a user's latest question in a chat.
+func lastQuestionKey(chatID int64, userID int) string {
+	return fmt.Sprintf("question:%d:%d", chatID, userID)
+}
+
+// rememberQuestion records the user's latest charged question in the chat for /retry.
+func (a *App) rememberQuestion(chatID int64, userID int, question string, messageID int, meta types.MessageMeta) {
+	if a.lastQuestions == nil || messageID == 0 {
+		return
+	}
+	data, err := json.Marshal(askedQuestion{Question: question, MessageID: messageID, Meta: meta})
+	if err != nil {
+		log.Printf("Failed to remember question for /retry: %v", err)
+		return
+	}
+	a.lastQuestions.SetWithTTL(lastQuestionKey(chatID, userID), string(data), retryWindow)
+}
+
+// retryLastQuestion re-asks the user's latest question in the chat with its original message and quote
+// details. The question was charged when first asked, so the retry is free; the record is used up, so
+// only one retry per question is. It reports false when there is no question to retry.
+func (a *App) retryLastQuestion(ctx context.Context, chatID int64, userID int, username string) (bool, error) {
+	if a.lastQuestions == nil {
+		return false, nil
+	}
+	data, found := a.lastQuestions.Take(lastQuestionKey(chatID, userID))
+	if !found {
+		return false, nil
+	}
+	var asked askedQuestion
+	if err := json.Unmarshal([]byte(data), &asked); err != nil {
+		return false, fmt.Errorf("failed to decode question for /retry: %w", err)
+	}
+	// A count of 0 tells the pipeline the question is already paid for
+	return true, a.processMessages(ctx, chatID, userID, username, asked.Question, asked.MessageID, asked.Meta, 0)
+}
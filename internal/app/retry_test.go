@@ -0,0 +1,96 @@
+// internal/app/retry_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/usage"
+)
+
+// retryCommand returns a /retry message from user 7 in chat 1.
+func retryCommand(messageID int) *types.TelegramMessage {
+	return &types.TelegramMessage{
+		MessageID: messageID,
+		Text:      "/retry",
+		Chat:      types.TelegramChat{ID: 1, Type: "private"},
+		From:      types.TelegramUser{ID: 7},
+	}
+}
+
+func TestRetryReasksLastQuestion(t *testing.T) {
+	tests := []struct {
+		name      string
+		asked     bool // Whether the user asked a question before /retry
+		atLimit   bool // Whether the question used the user's last message
+		retries   int
+		wantCalls int
+		wantUsage int
+	}{
+		{"nothing to retry", false, false, 1, 0, 0},
+		{"retry is free", true, false, 1, 2, 1},
+		{"retry works at the rate limit", true, true, 1, 2, usage.DefaultLimit},
+		{"only one free retry per question", true, false, 2, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.lastQuestions = cache.NewCache()
+			a.QuoteReplies = true
+			ctx := context.Background()
+
+			if tt.atLimit {
+				for i := 0; i < usage.DefaultLimit-1; i++ {
+					a.UsageCache.AddUsage(7)
+				}
+			}
+			meta := types.MessageMeta{
+				FirstName:       "Sam",
+				Quote:           &types.TelegramTextQuote{Text: "drop shot", Position: 4},
+				QuotedMessageID: 5,
+			}
+			if tt.asked {
+				if err := a.processMessage(ctx, 1, 7, "angler", "Best bait for bass?", 10, meta); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < tt.retries; i++ {
+				if _, err := a.HandleCommand(ctx, retryCommand(20+i), 7, "angler"); err != nil {
+					t.Fatalf("retry %d: %v", i+1, err)
+				}
+			}
+
+			if got := a.llm.callCount(); got != tt.wantCalls {
+				t.Fatalf("asked OpenAI %d times, want %d", got, tt.wantCalls)
+			}
+			if got := a.usedMessages(7); got != tt.wantUsage {
+				t.Errorf("charged %d messages, want %d", got, tt.wantUsage)
+			}
+			texts := a.telegram.texts()
+			if tt.wantCalls == 0 || tt.retries > 1 {
+				if last := texts[len(texts)-1]; !strings.Contains(last, "nothing to retry") {
+					t.Errorf("last reply = %q, want the nothing-to-retry message", last)
+				}
+			}
+			if tt.wantCalls < 2 {
+				return
+			}
+
+			// The retry re-asks the original question, not the /retry text, and keeps its quote
+			messages := a.llm.lastCall()
+			if got := messages[len(messages)-1].Content; got != "Best bait for bass?" {
+				t.Errorf("retried question = %q, want the original", got)
+			}
+			sends := a.telegram.sent("sendMessage")
+			retryAnswer := sends[1].Payload
+			params, _ := retryAnswer["reply_parameters"].(map[string]interface{})
+			if params == nil || params["quote"] != "drop shot" {
+				t.Errorf("retry answer reply_parameters = %v, want the original quote", retryAnswer["reply_parameters"])
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// internal/app/selftest.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// selfTestObjectKey is the S3 object used for the self-test write/read round-trip.
+const selfTestObjectKey = "selftest/probe.txt"
+
+// selfTestResult holds the outcome of a single self-test check.
+type selfTestResult struct {
+	Component string
+	Skipped   bool
+	Err       error
+	Latency   time.Duration
+}
+
+// RunSelfTest exercises OpenAI, the Knowledge Base, and S3 and returns a report for Telegram.
+// Cancelling ctx aborts the OpenAI and Knowledge Base checks.
+func (a *App) RunSelfTest(ctx context.Context) string {
+	results := []selfTestResult{
+		runSelfTestCheck("OpenAI", false, func() error { return a.selfTestOpenAI(ctx) }),
+		runSelfTestCheck("Knowledge Base", !a.KnowledgeBaseActive || a.KnowledgeBaseClient == nil, func() error { return a.probeKnowledgeBase(ctx) }),
+		runSelfTestCheck("S3", a.S3BucketName == "", a.selfTestS3),
+	}
+	return formatSelfTestReport(results)
+}
+
+// runSelfTestCheck times a single check, or marks it skipped when the component is not configured.
+func runSelfTestCheck(component string, skip bool, check func() error) selfTestResult {
+	if skip {
+		return selfTestResult{Component: component, Skipped: true}
+	}
+	start := time.Now()
+	err := check()
+	return selfTestResult{Component: component, Err: err, Latency: time.Since(start)}
+}
+
+// formatSelfTestReport renders self-test results as a Markdown message.
+func formatSelfTestReport(results []selfTestResult) string {
+	var sb strings.Builder
+	sb.WriteString("**Self-test results**\n")
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			sb.WriteString(fmt.Sprintf("\n⏭️ %s: skipped (not configured)", r.Component))
+		case r.Err != nil:
+			// Backticks would break the code span, so replace them
+			errText := strings.ReplaceAll(r.Err.Error(), "`", "'")
+			sb.WriteString(fmt.Sprintf("\n❌ %s: failed in %d ms\n`%s`", r.Component, r.Latency.Milliseconds(), errText))
+		default:
+			sb.WriteString(fmt.Sprintf("\n✅ %s: OK in %d ms", r.Component, r.Latency.Milliseconds()))
+		}
+	}
+	return sb.String()
+}
+
+// selfTestOpenAI sends a trivial prompt to OpenAI and verifies a non-empty answer comes back.
+func (a *App) selfTestOpenAI(ctx context.Context) error {
+	response, err := a.LLM.Complete(ctx, []types.OpenAIMessage{
+		{Role: "user", Content: "Reply with the single word OK."},
+	})
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(response) == "" {
+		return fmt.Errorf("OpenAI returned an empty response")
+	}
+	return nil
+}
+
+// selfTestS3 writes a probe object to the log bucket and reads it back.
+func (a *App) selfTestS3() error {
+	probe := fmt.Sprintf("selftest %d", time.Now().UnixNano())
+
+	_, err := a.S3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(a.S3BucketName),
+		Key:    aws.String(selfTestObjectKey),
+		Body:   bytes.NewReader([]byte(probe)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write probe object: %w", err)
+	}
+
+	resp, err := a.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(a.S3BucketName),
+		Key:    aws.String(selfTestObjectKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read probe object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read probe object body: %w", err)
+	}
+	if string(body) != probe {
+		return fmt.Errorf("probe object content mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// internal/app/selftest_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/knowledgebase"
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   func([]types.OpenAIMessage) (string, error)
+		kbStatus int // 0 leaves the Knowledge Base unconfigured
+		s3Err    error
+		noBucket bool
+		want     []string
+	}{
+		{
+			name:     "all pass",
+			kbStatus: http.StatusOK,
+			want:     []string{"✅ OpenAI: OK", "✅ Knowledge Base: OK", "✅ S3: OK"},
+		},
+		{
+			name:     "OpenAI fails",
+			answer:   func([]types.OpenAIMessage) (string, error) { return "", errors.New("invalid `key`") },
+			kbStatus: http.StatusOK,
+			want:     []string{"❌ OpenAI: failed", "'key'", "✅ Knowledge Base: OK", "✅ S3: OK"},
+		},
+		{
+			name:     "OpenAI answers blank",
+			answer:   func([]types.OpenAIMessage) (string, error) { return " ", nil },
+			kbStatus: http.StatusOK,
+			want:     []string{"❌ OpenAI: failed", "OpenAI returned an empty response"},
+		},
+		{
+			name:     "Knowledge Base fails",
+			kbStatus: http.StatusBadRequest,
+			want:     []string{"✅ OpenAI: OK", "❌ Knowledge Base: failed", "✅ S3: OK"},
+		},
+		{
+			name:  "S3 read fails",
+			s3Err: errors.New("access denied"),
+			want:  []string{"⏭️ Knowledge Base: skipped", "❌ S3: failed", "failed to read probe object: access denied"},
+		},
+		{
+			name:     "nothing optional configured",
+			noBucket: true,
+			want:     []string{"✅ OpenAI: OK", "⏭️ Knowledge Base: skipped", "⏭️ S3: skipped"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.llm.answer = tt.answer
+			if tt.kbStatus != 0 {
+				kb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.kbStatus)
+					w.Write([]byte(`[]`))
+				}))
+				t.Cleanup(kb.Close)
+				a.KnowledgeBaseActive = true
+				a.KnowledgeBaseClient = knowledgebase.NewKnowledgeBaseClient(kb.URL, "key")
+			}
+			if !tt.noBucket {
+				a.S3BucketName = "test-bucket"
+			}
+			a.store.getErr = tt.s3Err
+
+			report := a.RunSelfTest(context.Background())
+			for _, want := range tt.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report %q does not contain %q", report, want)
+				}
+			}
+		})
+	}
+}
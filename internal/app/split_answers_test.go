@@ -0,0 +1,125 @@
+// internal/app/split_answers_test.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// longAnswer returns an answer of several paragraphs that needs more than one Telegram message.
+func longAnswer() string {
+	paragraph := strings.Repeat("Fish the seams below the riffle. ", 60)
+	return strings.Join([]string{paragraph, paragraph, paragraph}, "\n\n")
+}
+
+func TestLongAnswersAreSentInParts(t *testing.T) {
+	a := newTestApp(t)
+	a.llm.answer = func([]types.OpenAIMessage) (string, error) { return longAnswer(), nil }
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "How do I fish a riffle?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	sent := a.telegram.sent("sendMessage")
+	if len(sent) < 2 {
+		t.Fatalf("sent %d messages, want the answer split over several", len(sent))
+	}
+	total := 0
+	for i, call := range sent {
+		text, _ := call.Payload["text"].(string)
+		if len(text) > utils.TelegramMessageLimit {
+			t.Errorf("part %d is %d bytes, over Telegram's limit", i, len(text))
+		}
+		total += strings.Count(text, "Fish the seams")
+
+		_, replies := call.Payload["reply_to_message_id"]
+		if replies != (i == 0) {
+			t.Errorf("part %d replies to the question = %v, want only the first part to", i, replies)
+		}
+	}
+	if total != 180 {
+		t.Errorf("parts hold %d sentences, want all 180", total)
+	}
+	if !strings.HasSuffix(a.telegram.texts()[len(sent)-1], helpFooter) {
+		t.Error("the last part does not end with the help footer")
+	}
+}
+
+func TestParseSplitReplyMode(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", splitReplyFirst},
+		{"first", splitReplyFirst},
+		{" ALL ", splitReplyAll},
+		{"Thread", splitReplyThread},
+		{"every", splitReplyFirst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseSplitReplyMode(tt.raw); got != tt.want {
+				t.Errorf("parseSplitReplyMode(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitReplyModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want func(part int) int // Message the part replies to; 0 means none
+	}{
+		{splitReplyFirst, func(part int) int {
+			if part == 0 {
+				return 10
+			}
+			return 0
+		}},
+		{splitReplyAll, func(int) int { return 10 }},
+		{splitReplyThread, func(part int) int {
+			if part == 0 {
+				return 10
+			}
+			return 500 + part - 1 // The part sent before it
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			a := newTestApp(t)
+			a.SplitReplyMode = tt.mode
+			a.llm.answer = func([]types.OpenAIMessage) (string, error) { return longAnswer(), nil }
+			sentCount := 0
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method != "sendMessage" {
+					return 0, ""
+				}
+				id := 500 + sentCount
+				sentCount++
+				return http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"message_id":%d}}`, id)
+			}
+
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "How do I fish a riffle?", 10, types.MessageMeta{}); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+
+			sent := a.telegram.sent("sendMessage")
+			if len(sent) < 2 {
+				t.Fatalf("sent %d messages, want the answer split over several", len(sent))
+			}
+			for i, call := range sent {
+				replyTo, _ := call.Payload["reply_to_message_id"].(float64)
+				if int(replyTo) != tt.want(i) {
+					t.Errorf("part %d replies to %v, want %d", i, replyTo, tt.want(i))
+				}
+			}
+		})
+	}
+}
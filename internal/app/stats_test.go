@@ -0,0 +1,56 @@
+// internal/app/stats_test.go
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/usage"
+)
+
+func TestStatsCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		used        int
+		noLimitUser bool
+		want        []string
+		wantAbsent  []string
+	}{
+		{"fresh user", 0, false, []string{"You have 3 messages left.", "The limit is 3 messages per 1 hour."}, []string{"frees up"}},
+		{"after some questions", 2, false, []string{"You have 1 messages left.", "Your next message slot frees up in 59 minutes"}, nil},
+		{"no-limit user", 2, true, []string{"You have unlimited messages."}, []string{"left"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.UsageCache = usage.NewUsageCache(3, time.Hour)
+			if tt.noLimitUser {
+				a.NoLimitUsers[7] = struct{}{}
+			}
+			for i := 0; i < tt.used; i++ {
+				a.UsageCache.AddUsage(7)
+			}
+
+			if _, err := a.HandleCommand(context.Background(), commandMessage("/stats"), 7, "angler"); err != nil {
+				t.Fatalf("HandleCommand error = %v", err)
+			}
+			texts := a.telegram.texts()
+			if len(texts) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(texts))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(texts[0], want) {
+					t.Errorf("reply %q is missing %q", texts[0], want)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(texts[0], absent) {
+					t.Errorf("reply %q should not mention %q", texts[0], absent)
+				}
+			}
+		})
+	}
+}
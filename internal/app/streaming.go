@@ -0,0 +1,213 @@
+// internal/app/streaming.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// defaultStreamEditInterval keeps edits of a streamed answer well under Telegram's per-chat edit limits.
+const defaultStreamEditInterval = 1500 * time.Millisecond
+
+// telegramCallTimeout caps a single Bot API request made by callTelegram.
+const telegramCallTimeout = 10 * time.Second
+
+// streamingCursor is shown at the end of an answer that is still being written.
+const streamingCursor = " …"
+
+// streamedReply shows an OpenAI answer as it streams in: it posts a placeholder message with the first
+// content and edits it as more arrives, at most once per interval.
+type streamedReply struct {
+	app      *App
+	ctx      context.Context // The update being answered; cancelling it abandons the placeholder's updates
+	chatID   int64
+	replyTo  int
+	interval time.Duration
+
+	mutex     sync.Mutex
+	text      strings.Builder
+	messageID int       // The placeholder message; 0 until it has been sent
+	lastEdit  time.Time // When the placeholder was last sent or edited
+	failed    bool      // Sending or editing failed, so no further updates are attempted
+}
+
+// newStreamedReply prepares to stream an answer to chatID as a reply to the user's message.
+// The placeholder is sent and edited under ctx, since onDelta is called without one.
+func (a *App) newStreamedReply(ctx context.Context, chatID int64, replyTo int) *streamedReply {
+	return &streamedReply{
+		app:      a,
+		ctx:      ctx,
+		chatID:   chatID,
+		replyTo:  replyTo,
+		interval: a.StreamEditInterval,
+	}
+}
+
+// onDelta appends streamed content and updates the placeholder when the edit interval has passed.
+func (s *streamedReply) onDelta(delta string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.text.WriteString(delta)
+	if s.failed || time.Since(s.lastEdit) < s.interval {
+		return
+	}
+
+	// Partial Markdown is often unbalanced, so the draft is shown as plain text until the answer is complete
+	draft := strings.TrimSpace(s.text.String())
+	if draft == "" {
+		return
+	}
+	if limit := utils.TelegramMessageLimit - len(streamingCursor); len(draft) > limit {
+		draft = utils.SplitMessage(draft, limit)[0]
+	}
+	draft += streamingCursor
+
+	var err error
+	if s.messageID == 0 {
+		s.messageID, err = s.app.sendDraft(s.ctx, s.chatID, draft, s.replyTo)
+		if err == nil && s.messageID == 0 {
+			err = fmt.Errorf("telegram did not return the placeholder message ID")
+		}
+	} else {
+		err = s.app.editMessageText(s.ctx, s.chatID, s.messageID, draft, "", "")
+	}
+	if err != nil {
+		log.Printf("Failed to update streamed answer, sending it when complete instead: %v", err)
+		s.failed = true
+	}
+	s.lastEdit = time.Now()
+}
+
+// placeholder returns the ID of the placeholder message, or 0 when none was sent.
+func (s *streamedReply) placeholder() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.messageID
+}
+
+// finish replaces the placeholder with the final, formatted answer. Parts beyond the first Telegram
+// message are sent as new messages, with the keyboard on the last part. The requests are abandoned when ctx is cancelled.
+func (s *streamedReply) finish(ctx context.Context, text, keyboard string) error {
+	chunks := utils.SplitMessage(text, utils.TelegramMessageLimit)
+	firstKeyboard := ""
+	if len(chunks) == 1 {
+		firstKeyboard = keyboard
+	}
+	if err := s.app.editMessageText(ctx, s.chatID, s.placeholder(), chunks[0], "Markdown", firstKeyboard); err != nil {
+		return err
+	}
+
+	previousID := s.placeholder()
+	for i, chunk := range chunks[1:] {
+		replyTo := 0
+		if s.app.SplitReplyMode == splitReplyThread {
+			replyTo = previousID
+		} else if s.app.SplitReplyMode == splitReplyAll {
+			replyTo = s.replyTo
+		}
+		chunkKeyboard := ""
+		if i == len(chunks)-2 {
+			chunkKeyboard = keyboard
+		}
+		sentID, err := s.app.sendMessageWithReply(ctx, s.chatID, chunk, replyTo, nil, chunkKeyboard)
+		if err != nil {
+			return err
+		}
+		previousID = sentID
+	}
+	return nil
+}
+
+// sendDraft sends a plain-text message as a reply and returns its ID.
+func (a *App) sendDraft(ctx context.Context, chatID int64, text string, replyToMessageID int) (int, error) {
+	payload := map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if replyToMessageID != 0 {
+		payload["reply_to_message_id"] = replyToMessageID
+	}
+
+	var sent sentMessageResponse
+	if err := a.callTelegram(ctx, "sendMessage", payload, &sent); err != nil {
+		return 0, err
+	}
+	a.scheduleAutoDelete(chatID, sent.Result.MessageID)
+	return sent.Result.MessageID, nil
+}
+
+// editMessageText replaces the text of a message the bot sent. An empty parseMode sends plain text,
+// and a non-empty keyboard is attached as the message's reply_markup. Markdown text is sanitized first and
+// sent again as plain text if Telegram still can't parse it.
+func (a *App) editMessageText(ctx context.Context, chatID int64, messageID int, text, parseMode, keyboard string) error {
+	payload := map[string]interface{}{
+		"chat_id":                  chatID,
+		"message_id":               messageID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if parseMode == "Markdown" {
+		payload["text"] = utils.SanitizeMarkdown(text)
+	}
+	if parseMode != "" {
+		payload["parse_mode"] = parseMode
+	}
+	if keyboard != "" {
+		payload["reply_markup"] = keyboard
+	}
+	err := a.callTelegram(ctx, "editMessageText", payload, nil)
+	if isMarkdownParseError(err) {
+		log.Printf("Telegram could not parse the edited message's Markdown, sending it as plain text: %v", err)
+		delete(payload, "parse_mode")
+		payload["text"] = text
+		err = a.callTelegram(ctx, "editMessageText", payload, nil)
+	}
+	return err
+}
+
+// callTelegram posts a payload to a Telegram Bot API method and decodes the response into result when non-nil.
+// The request is abandoned when ctx is cancelled or after telegramCallTimeout.
+func (a *App) callTelegram(ctx context.Context, method string, payload map[string]interface{}, result interface{}) error {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, telegramCallTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", a.TelegramToken, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status: %s - %s", resp.Status, string(bodyBytes))
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
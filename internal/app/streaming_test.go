@@ -0,0 +1,58 @@
+// internal/app/streaming_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStreamedReplyHonorsContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		cancel     bool
+		wantErr    error
+		wantEdited bool
+	}{
+		{"finished while the update is live", false, nil, true},
+		{"abandoned once the update is cancelled", true, context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream := a.newStreamedReply(ctx, 1, 10)
+			stream.onDelta("Use a drop shot")
+			if stream.placeholder() == 0 {
+				t.Fatal("no placeholder was sent")
+			}
+			if tt.cancel {
+				cancel()
+			}
+
+			err := stream.finish(ctx, "Use a drop shot rig.", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("finish() error = %v, want %v", err, tt.wantErr)
+			}
+			if edited := len(a.telegram.sent("editMessageText")) > 0; edited != tt.wantEdited {
+				t.Errorf("edited the placeholder = %v, want %v", edited, tt.wantEdited)
+			}
+		})
+	}
+}
+
+func TestCallTelegramHonorsContext(t *testing.T) {
+	a := newTestApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := a.callTelegram(ctx, "sendMessage", map[string]interface{}{"chat_id": 1, "text": "hi"}, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("callTelegram() error = %v, want context.Canceled", err)
+	}
+	if sent := a.telegram.sent("sendMessage"); len(sent) != 0 {
+		t.Errorf("sent %d messages after the update was cancelled", len(sent))
+	}
+}
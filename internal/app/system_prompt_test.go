@@ -0,0 +1,63 @@
+// internal/app/system_prompt_test.go
+
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestLoadSystemPrompt(t *testing.T) {
+	dir := t.TempDir()
+	promptFile := filepath.Join(dir, "prompt.txt")
+	if err := os.WriteFile(promptFile, []byte("\nYou are a fly fishing guide.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(emptyFile, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		inline string
+		path   string
+		want   string
+	}{
+		{"default", "", "", defaultSystemPrompt},
+		{"inline", "  You are a charter captain. ", "", "You are a charter captain."},
+		{"inline wins over the file", "You are a charter captain.", promptFile, "You are a charter captain."},
+		{"file", "", promptFile, "You are a fly fishing guide."},
+		{"blank inline falls through to the file", "   ", promptFile, "You are a fly fishing guide."},
+		{"missing file", "", filepath.Join(dir, "missing.txt"), defaultSystemPrompt},
+		{"empty file", "", emptyFile, defaultSystemPrompt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loadSystemPrompt(tt.inline, tt.path); got != tt.want {
+				t.Errorf("loadSystemPrompt(%q, %q) = %q, want %q", tt.inline, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystemPromptStartsTheConversation(t *testing.T) {
+	a := newTestApp(t)
+	a.SystemPrompt = "You are a charter captain."
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	messages := a.llm.lastCall()
+	if len(messages) == 0 || messages[0].Role != "system" {
+		t.Fatalf("first message is not a system prompt: %+v", messages)
+	}
+	if !strings.HasPrefix(messages[0].Content, a.SystemPrompt) {
+		t.Errorf("system prompt %q does not start with the configured persona", messages[0].Content)
+	}
+}
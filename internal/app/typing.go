@@ -0,0 +1,77 @@
+// internal/app/typing.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// chatActionTyping is the Telegram chat action shown as "typing…".
+const chatActionTyping = "typing"
+
+// typingRefreshInterval is how often the typing indicator is re-sent; Telegram clears it after about 5 seconds.
+const typingRefreshInterval = 4 * time.Second
+
+// SendChatAction shows a chat action such as "typing" in the chat until the bot sends its next message.
+// Cancelling ctx aborts the request.
+func (a *App) SendChatAction(ctx context.Context, chatID int64, action string) error {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendChatAction", a.TelegramToken)
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("telegram API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// startTyping shows the typing indicator in the chat and keeps it alive until the returned stop function is called
+// or ctx is done.
+func (a *App) startTyping(ctx context.Context, chatID int64) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(typingRefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := a.SendChatAction(ctx, chatID, chatActionTyping); err != nil {
+				log.Printf("Failed to send typing action to chat %d: %v", chatID, err)
+			}
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
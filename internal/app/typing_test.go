@@ -0,0 +1,62 @@
+// internal/app/typing_test.go
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestSendChatAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"sent", http.StatusOK, false},
+		{"rejected", http.StatusBadRequest, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				return tt.status, `{"ok":false}`
+			}
+
+			if err := a.SendChatAction(context.Background(), 42, chatActionTyping); (err != nil) != tt.wantErr {
+				t.Fatalf("SendChatAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			sent := a.telegram.sent("sendChatAction")
+			if len(sent) != 1 || sent[0].Payload["chat_id"] != float64(42) || sent[0].Payload["action"] != "typing" {
+				t.Errorf("sendChatAction requests = %v, want one typing action for chat 42", sent)
+			}
+		})
+	}
+}
+
+func TestTypingIsShownWhileOpenAIAnswers(t *testing.T) {
+	a := newTestApp(t)
+	a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+		// The indicator must be visible before the answer is ready
+		waitFor(t, "typing action", func() bool { return len(a.telegram.sent("sendChatAction")) > 0 })
+		return "Use a jig.", nil
+	}
+
+	if err := a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	// Once the answer is sent the indicator is no longer refreshed
+	actions := len(a.telegram.sent("sendChatAction"))
+	time.Sleep(50 * time.Millisecond)
+	if got := len(a.telegram.sent("sendChatAction")); got != actions {
+		t.Errorf("sent %d typing actions after the answer, want none", got-actions)
+	}
+	if texts := a.telegram.texts(); len(texts) != 1 || texts[0] != "Use a jig."+helpFooter {
+		t.Errorf("sent %q, want the answer", texts)
+	}
+}
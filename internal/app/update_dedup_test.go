@@ -0,0 +1,108 @@
+// internal/app/update_dedup_test.go
+
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/queue"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// handledUpdates records the update_ids an update queue handed to its handler.
+type handledUpdates struct {
+	mutex sync.Mutex
+	ids   []int
+}
+
+func (h *handledUpdates) handle(update *types.TelegramUpdate) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.ids = append(h.ids, update.UpdateID)
+}
+
+func (h *handledUpdates) count() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.ids)
+}
+
+func TestDispatchUpdateSkipsDuplicates(t *testing.T) {
+	tests := []struct {
+		name        string
+		dedup       bool
+		updateIDs   []int
+		wantHandled int
+	}{
+		{"redelivered update", true, []int{1, 2, 1}, 2},
+		{"update ids are distinct", true, []int{1, 2, 3}, 3},
+		{"updates without an id", true, []int{0, 0}, 2},
+		{"deduplication disabled", false, []int{1, 1}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if tt.dedup {
+				a.seenUpdates = cache.NewCache()
+				a.UpdateDedupTTL = time.Hour
+			}
+			var handled handledUpdates
+			a.UpdateQueue = queue.NewUpdateQueue(len(tt.updateIDs), 1, handled.handle)
+
+			for _, id := range tt.updateIDs {
+				if !a.DispatchUpdate(&types.TelegramUpdate{UpdateID: id}) {
+					t.Errorf("update %d was not acknowledged", id)
+				}
+			}
+			waitFor(t, "the updates to be handled", func() bool { return handled.count() >= tt.wantHandled })
+			time.Sleep(20 * time.Millisecond)
+			if got := handled.count(); got != tt.wantHandled {
+				t.Errorf("handled %d updates, want %d", got, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestUpdateKeysAreNamespacedByInstance(t *testing.T) {
+	a := newTestApp(t)
+	a.seenUpdates = cache.NewCache()
+	a.UpdateDedupTTL = time.Hour
+
+	a.InstanceID = "bass-bot"
+	if a.markUpdateSeen(1) {
+		t.Fatal("first update reported as seen")
+	}
+	a.InstanceID = "trout-bot"
+	if a.markUpdateSeen(1) {
+		t.Error("another bot's update_id was reported as seen")
+	}
+	if !a.markUpdateSeen(1) {
+		t.Error("redelivered update was not reported as seen")
+	}
+}
+
+func TestRejectedUpdateIsProcessedOnRedelivery(t *testing.T) {
+	a := newTestApp(t)
+	a.seenUpdates = cache.NewCache()
+	a.UpdateDedupTTL = time.Hour
+	var handled handledUpdates
+	release := make(chan struct{})
+	a.UpdateQueue = queue.NewUpdateQueue(0, 1, func(update *types.TelegramUpdate) {
+		handled.handle(update)
+		<-release
+	})
+
+	// The worker takes update 1 and blocks, so the unbuffered queue rejects update 2
+	waitFor(t, "the worker to take update 1", func() bool { return a.DispatchUpdate(&types.TelegramUpdate{UpdateID: 1}) })
+	waitFor(t, "update 1 to be handled", func() bool { return handled.count() == 1 })
+	if a.DispatchUpdate(&types.TelegramUpdate{UpdateID: 2}) {
+		t.Fatal("update 2 was accepted by a full queue")
+	}
+
+	close(release)
+	waitFor(t, "the redelivered update 2 to be accepted", func() bool { return a.DispatchUpdate(&types.TelegramUpdate{UpdateID: 2}) })
+	waitFor(t, "update 2 to be handled", func() bool { return handled.count() == 2 })
+}
@@ -0,0 +1,137 @@
+// internal/app/update_timeout_test.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestUpdateContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantDeadline bool
+	}{
+		{"bounded", time.Minute, true},
+		{"disabled", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.UpdateTimeout = tt.timeout
+			ctx, cancel := a.updateContext()
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("context has a deadline: %v, want %v", ok, tt.wantDeadline)
+			}
+			if ok && time.Until(deadline) > tt.timeout {
+				t.Errorf("deadline is %v away, want at most %v", time.Until(deadline), tt.timeout)
+			}
+			cancel()
+			if ctx.Err() == nil {
+				t.Error("cancel did not end the context")
+			}
+		})
+	}
+}
+
+func TestExpiredUpdateIsNotRetried(t *testing.T) {
+	tests := []struct {
+		name   string
+		expire func(cancel context.CancelFunc) // Ends the update's context while the first attempt is failing
+	}{
+		{"cancelled during the attempt", func(cancel context.CancelFunc) { cancel() }},
+		{"cancelled during the retry delay", func(cancel context.CancelFunc) { time.AfterFunc(20*time.Millisecond, cancel) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.ProcessRetries = 3
+			a.ProcessRetryDelay = time.Hour
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+				tt.expire(cancel)
+				return "", &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- a.processMessage(ctx, 1, 7, "angler", "Best bait for bass?", 10, types.MessageMeta{})
+			}()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Error("processMessage() succeeded, want the attempt's error")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("processMessage() kept retrying after the update's context ended")
+			}
+			if got := a.llm.callCount(); got != 1 {
+				t.Errorf("OpenAI was called %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCancelledUpdateAbortsItsRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, a *testApp) error
+	}{
+		{"classifier", func(ctx context.Context, a *testApp) error {
+			a.ClassifierEnabled = true
+			answered := false
+			a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+				answered = true
+				return `{"category":"","fish_species":"","body_of_water":""}`, nil
+			}
+			if a.tagQuestion(ctx, "Best bass lure?", nil); answered {
+				return nil
+			}
+			return context.Canceled
+		}},
+		{"follow-up suggestions", func(ctx context.Context, a *testApp) error {
+			a.followUps = newFollowUps(2)
+			_, err := a.suggestFollowUps(ctx, "Best bass lure?", "A jig.")
+			return err
+		}},
+		{"training data", func(ctx context.Context, a *testApp) error {
+			a.KnowledgeBaseURL = "https://kb.example.com/train"
+			return a.sendTrainingData(ctx, "Q: Best bass lure? A: A jig.")
+		}},
+		{"notice", func(ctx context.Context, a *testApp) error {
+			ch := &telegramChannel{app: a.App, chatID: 1, messageID: 10}
+			return ch.notice(ctx, busyMessage)
+		}},
+		{"self-test", func(ctx context.Context, a *testApp) error {
+			if report := a.RunSelfTest(ctx); !strings.Contains(report, context.Canceled.Error()) {
+				return nil
+			}
+			return context.Canceled
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := tt.run(ctx, a); !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want the cancellation", err)
+			}
+			if calls := len(a.telegram.calls); calls != 0 {
+				t.Errorf("made %d Telegram or KB requests after the update was cancelled", calls)
+			}
+		})
+	}
+}
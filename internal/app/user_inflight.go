@@ -0,0 +1,39 @@
+// internal/app/user_inflight.go
+
+package app
+
+import "sync"
+
+// busyMessage is sent when a user asks a new question while too many of their earlier ones are still being answered.
+const busyMessage = "⏳ Please wait for the answer to your previous question before asking another one."
+
+// userInflight caps how many of a user's questions are being answered at once, so a script can't
+// open many concurrent OpenAI requests that each fit within the rolling rate-limit window.
+type userInflight struct {
+	mutex  sync.Mutex
+	limit  int
+	counts map[int]int
+}
+
+// newUserInflight initializes a cap of limit questions in flight per user.
+func newUserInflight(limit int) *userInflight {
+	return &userInflight{limit: limit, counts: make(map[int]int)}
+}
+
+// acquire reserves an in-flight slot for the user and returns the function that frees it,
+// or false when the user already has limit questions in flight.
+func (u *userInflight) acquire(userID int) (release func(), ok bool) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.counts[userID] >= u.limit {
+		return nil, false
+	}
+	u.counts[userID]++
+	return func() {
+		u.mutex.Lock()
+		defer u.mutex.Unlock()
+		if u.counts[userID]--; u.counts[userID] <= 0 {
+			delete(u.counts, userID)
+		}
+	}, true
+}
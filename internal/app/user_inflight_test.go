@@ -0,0 +1,109 @@
+// internal/app/user_inflight_test.go
+
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestUserInflightAcquire(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		acquires []int // User IDs acquiring in order, none released
+		want     []bool
+	}{
+		{"within the limit", 2, []int{7, 7}, []bool{true, true}},
+		{"over the limit", 1, []int{7, 7}, []bool{true, false}},
+		{"users are capped separately", 1, []int{7, 8, 7}, []bool{true, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUserInflight(tt.limit)
+			for i, userID := range tt.acquires {
+				if _, ok := u.acquire(userID); ok != tt.want[i] {
+					t.Errorf("acquire %d for user %d = %v, want %v", i, userID, ok, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUserInflightRelease(t *testing.T) {
+	u := newUserInflight(1)
+	release, ok := u.acquire(7)
+	if !ok {
+		t.Fatal("first acquire failed")
+	}
+	release()
+	if _, ok := u.acquire(7); !ok {
+		t.Error("acquire after release failed")
+	}
+	if len(u.counts) != 1 {
+		t.Errorf("tracking %d users, want 1", len(u.counts))
+	}
+}
+
+func TestQuestionsBeyondTheInflightCapAreTurnedAway(t *testing.T) {
+	tests := []struct {
+		name        string
+		noLimitUser bool
+		wantBusy    bool
+	}{
+		{"second question turned away", false, true},
+		{"no-limit users are not capped", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.userInflight = newUserInflight(1)
+			if tt.noLimitUser {
+				a.NoLimitUsers[7] = struct{}{}
+			}
+			started := make(chan struct{}, 2)
+			unblock := make(chan struct{})
+			a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+				started <- struct{}{}
+				<-unblock
+				return "Use a jig.", nil
+			}
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.ProcessMessage(context.Background(), 1, 7, "angler", "Best bass lure?", 10, types.MessageMeta{})
+			}()
+			<-started
+
+			second := make(chan error, 1)
+			go func() {
+				second <- a.ProcessMessage(context.Background(), 1, 7, "angler", "Best trout lure?", 11, types.MessageMeta{})
+			}()
+			if tt.wantBusy {
+				if err := <-second; err == nil {
+					t.Error("second question was accepted while the first was in flight")
+				}
+				if texts := a.telegram.texts(); len(texts) != 1 || texts[0] != busyMessage {
+					t.Errorf("sent %q, want the busy message", texts)
+				}
+				if used := a.usedMessages(7); used != 1 {
+					t.Errorf("charged %d messages, want only the first question", used)
+				}
+			} else {
+				<-started
+			}
+			close(unblock)
+			wg.Wait()
+			if !tt.wantBusy {
+				if err := <-second; err != nil {
+					t.Errorf("second question error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// internal/app/user_lock.go
+
+package app
+
+import "sync"
+
+// userLocks hands out one mutex per user so a user's messages are answered one at a time,
+// while different users are still answered concurrently.
+type userLocks struct {
+	mutex sync.Mutex
+	locks map[int]*userLock
+}
+
+// userLock is a user's mutex plus the number of messages holding or waiting for it.
+type userLock struct {
+	sync.Mutex
+	refs int
+}
+
+// newUserLocks initializes an empty set of per-user locks.
+func newUserLocks() *userLocks {
+	return &userLocks{locks: make(map[int]*userLock)}
+}
+
+// lock blocks until the user's lock is free and returns the function that releases it.
+// Locks are dropped once no message holds or waits for them, so the map only holds active users.
+func (l *userLocks) lock(userID int) (unlock func()) {
+	l.mutex.Lock()
+	ul, ok := l.locks[userID]
+	if !ok {
+		ul = &userLock{}
+		l.locks[userID] = ul
+	}
+	ul.refs++
+	l.mutex.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if ul.refs--; ul.refs == 0 {
+			delete(l.locks, userID)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// internal/app/user_lock_test.go
+
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestUserLocks(t *testing.T) {
+	tests := []struct {
+		name        string
+		first       int
+		second      int
+		wantBlocked bool
+	}{
+		{"same user waits", 7, 7, true},
+		{"different users run concurrently", 7, 8, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := newUserLocks()
+			unlockFirst := locks.lock(tt.first)
+
+			acquired := make(chan func())
+			go func() { acquired <- locks.lock(tt.second) }()
+
+			select {
+			case unlock := <-acquired:
+				if tt.wantBlocked {
+					t.Fatal("second lock acquired while the first was held")
+				}
+				unlock()
+				unlockFirst()
+			case <-time.After(50 * time.Millisecond):
+				if !tt.wantBlocked {
+					t.Fatal("second lock blocked on another user's lock")
+				}
+				unlockFirst()
+				(<-acquired)()
+			}
+
+			locks.mutex.Lock()
+			defer locks.mutex.Unlock()
+			if len(locks.locks) != 0 {
+				t.Errorf("%d locks left after release, want 0", len(locks.locks))
+			}
+		})
+	}
+}
+
+func TestConcurrentMessagesKeepBothTurns(t *testing.T) {
+	a := newTestApp(t)
+	a.userLocks = newUserLocks()
+	// A slow answer lets the second message arrive while the first is still being answered
+	a.llm.answer = func(messages []types.OpenAIMessage) (string, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "Answer to: " + messages[len(messages)-1].Content, nil
+	}
+
+	var wg sync.WaitGroup
+	for i, question := range []string{"Best bass lure?", "Best trout lure?"} {
+		wg.Add(1)
+		go func(question string, messageID int) {
+			defer wg.Done()
+			if err := a.ProcessMessage(context.Background(), 1, 7, "angler", question, messageID, types.MessageMeta{}); err != nil {
+				t.Errorf("ProcessMessage(%q) error = %v", question, err)
+			}
+		}(question, 10+i)
+	}
+	wg.Wait()
+
+	questions := map[string]bool{}
+	for _, message := range a.loadConversation(a.conversationKey(7)) {
+		if message.Role == "user" {
+			questions[message.Content] = true
+		}
+	}
+	for _, want := range []string{"Best bass lure?", "Best trout lure?"} {
+		if !questions[want] {
+			t.Errorf("conversation lost the turn %q; kept %v", want, questions)
+		}
+	}
+}
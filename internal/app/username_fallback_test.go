@@ -0,0 +1,46 @@
+// internal/app/username_fallback_test.go
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestCallbackLogsNameWithoutUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     types.TelegramUser
+		fallback bool
+		want     string
+	}{
+		{"username", types.TelegramUser{ID: 7, Username: "angler", FirstName: "Jane"}, true, "angler"},
+		{"first name instead of blank", types.TelegramUser{ID: 7, FirstName: "Jane"}, true, "Jane"},
+		{"full name", types.TelegramUser{ID: 7, FirstName: "Jane", LastName: "Doe"}, true, "Jane Doe"},
+		{"fallback disabled", types.TelegramUser{ID: 7, FirstName: "Jane"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.NameFallback = tt.fallback
+			a.promptMap["prompt_1"] = "Best bait for bass?"
+
+			err := a.HandleCallbackQuery(context.Background(), &types.TelegramCallbackQuery{
+				ID:      "cb",
+				From:    tt.from,
+				Message: &types.TelegramMessage{MessageID: 20, Chat: types.TelegramChat{ID: 7}},
+				Data:    "prompt_1",
+			})
+			if err != nil {
+				t.Fatalf("HandleCallbackQuery() error = %v", err)
+			}
+
+			rows := loggedRows(t, a)
+			if len(rows) != 1 || rows[0]["username"] != tt.want {
+				t.Errorf("logged %v, want username %q", rows, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// internal/app/voice.go
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"ReelTalkBot-Go/internal/api"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// Limits on voice messages
+const (
+	defaultVoiceMaxDuration = 2 * time.Minute
+	maxVoiceBytes           = 20 << 20  // Telegram bots can't download larger files anyway
+	filePathTTL             = time.Hour // Telegram keeps a download link valid for at least an hour
+)
+
+// errFileTooLarge is returned for files over maxVoiceBytes, which the Bot API won't serve.
+var errFileTooLarge = errors.New("file is over Telegram's 20 MB bot download limit")
+
+// voiceTooLargeMessage tells the user why a voice message over maxVoiceBytes was not answered.
+const voiceTooLargeMessage = "That voice message is over Telegram's 20 MB download limit for bots. Please send a shorter one or type your question."
+
+// voicePlaceholder stands in for the question of a voice message that was not transcribed because of quiet
+// hours or a rate limit, so the notice and log entry still happen.
+const voicePlaceholder = "[voice message]"
+
+// TranscribeVoice downloads a voice message and transcribes it with OpenAI so it can be answered like text.
+// It replies to the user itself when the voice message can't be used and returns "" in that case.
+// Cancelling ctx aborts the download and the transcription.
+func (a *App) TranscribeVoice(ctx context.Context, message *types.TelegramMessage) (string, error) {
+	chatID, voice := message.Chat.ID, message.Voice
+	if !a.VoiceMessages {
+		a.sendMessage(ctx, chatID, "Sorry, I can only answer text messages.", message.MessageID)
+		return "", nil
+	}
+
+	// Don't pay for a transcription that quiet hours or the rate limits would discard; the placeholder
+	// still reaches handleQuestion, which sends the notice. The chat limit applies even to no-limit users.
+	_, noLimit := a.NoLimitUsers[message.From.ID]
+	quiet := a.QuietHours != nil && a.QuietHours.Active(a.now())
+	if !a.UsageCache.CanChatProceed(chatID) || (!noLimit && (quiet || !a.UsageCache.CanUserChat(message.From.ID))) {
+		return voicePlaceholder, nil
+	}
+
+	if limit := a.VoiceMaxDuration; limit > 0 && time.Duration(voice.Duration)*time.Second > limit {
+		msg := fmt.Sprintf("That voice message is too long. Please keep questions under %s.", formatWait(limit))
+		a.sendMessage(ctx, chatID, msg, message.MessageID)
+		return "", nil
+	}
+
+	// Telegram reports the size up front, so an oversized file is turned away without a getFile call
+	if voice.FileSize > maxVoiceBytes {
+		a.sendMessage(ctx, chatID, voiceTooLargeMessage, message.MessageID)
+		return "", nil
+	}
+
+	stopTyping := a.startTyping(ctx, chatID)
+	defer stopTyping()
+
+	data, err := a.downloadTelegramFile(ctx, voice.FileID)
+	if errors.Is(err, errFileTooLarge) {
+		a.sendMessage(ctx, chatID, voiceTooLargeMessage, message.MessageID)
+		return "", nil
+	}
+	if err != nil {
+		a.sendMessage(ctx, chatID, "Sorry, I couldn't download that voice message. Please try again or type your question.", message.MessageID)
+		return "", fmt.Errorf("failed to download voice message: %w", err)
+	}
+	transcriber, ok := a.LLM.(api.Transcriber)
+	if !ok {
+		a.sendMessage(ctx, chatID, "Sorry, I can't understand voice messages right now. Please type your question.", message.MessageID)
+		return "", fmt.Errorf("LLM provider %T cannot transcribe audio", a.LLM)
+	}
+	transcript, err := transcriber.TranscribeAudio(ctx, data)
+	if err != nil {
+		a.sendMessage(ctx, chatID, "Sorry, I couldn't understand that voice message. Please try again or type your question.", message.MessageID)
+		return "", fmt.Errorf("failed to transcribe voice message: %w", err)
+	}
+	if transcript == "" {
+		a.sendMessage(ctx, chatID, "Sorry, I couldn't hear a question in that voice message.", message.MessageID)
+		return "", nil
+	}
+
+	log.Printf("Transcribed %ds voice message from user %d: %s", voice.Duration, message.From.ID, transcript)
+	return transcript, nil
+}
+
+// downloadTelegramFile resolves a file ID with getFile and downloads the file's contents.
+// Resolved paths are cached for filePathTTL, so a file sent again is downloaded without another getFile call.
+func (a *App) downloadTelegramFile(ctx context.Context, fileID string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	filePath, err := a.telegramFilePath(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", a.TelegramToken, filePath)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status downloading file: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVoiceBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxVoiceBytes {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
+
+// telegramFilePath returns the download path of a file, calling getFile unless the path is cached.
+func (a *App) telegramFilePath(ctx context.Context, fileID string) (string, error) {
+	if a.filePaths != nil {
+		if filePath, found := a.filePaths.Get(fileID); found {
+			return filePath, nil
+		}
+	}
+
+	var file struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			FilePath string `json:"file_path"`
+			FileSize int    `json:"file_size"`
+		} `json:"result"`
+	}
+	if err := a.callTelegram(ctx, "getFile", map[string]interface{}{"file_id": fileID}, &file); err != nil {
+		return "", err
+	}
+	if !file.OK || file.Result.FilePath == "" {
+		return "", fmt.Errorf("getFile returned no file path")
+	}
+	if file.Result.FileSize > maxVoiceBytes {
+		return "", fmt.Errorf("%w: %d bytes", errFileTooLarge, file.Result.FileSize)
+	}
+
+	if a.filePaths != nil {
+		a.filePaths.SetWithTTL(fileID, file.Result.FilePath, filePathTTL)
+	}
+	return file.Result.FilePath, nil
+}
@@ -0,0 +1,160 @@
+// internal/app/voice_test.go
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/types"
+	"ReelTalkBot-Go/internal/usage"
+)
+
+// serveVoiceFile makes the fake Telegram API resolve every file ID to voice/<file_id>.ogg.
+func serveVoiceFile(a *testApp) {
+	a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+		if method == "getFile" {
+			return http.StatusOK, `{"ok":true,"result":{"file_path":"voice/` + payload["file_id"].(string) + `.ogg","file_size":1024}}`
+		}
+		return 0, ""
+	}
+}
+
+func TestDownloadTelegramFileCachesFilePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		cached       bool
+		fileIDs      []string
+		wantGetFiles int
+	}{
+		{"repeated file ID skips getFile", true, []string{"abc", "abc", "abc"}, 1},
+		{"different file IDs are each resolved", true, []string{"abc", "def", "abc"}, 2},
+		{"without the cache every download calls getFile", false, []string{"abc", "abc"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if tt.cached {
+				a.filePaths = cache.NewCache()
+			}
+			serveVoiceFile(a)
+
+			for _, fileID := range tt.fileIDs {
+				if _, err := a.downloadTelegramFile(context.Background(), fileID); err != nil {
+					t.Fatalf("downloadTelegramFile(%q) error = %v", fileID, err)
+				}
+				if downloads := a.telegram.sent(fileID + ".ogg"); len(downloads) == 0 {
+					t.Errorf("file %q was not downloaded from its resolved path", fileID)
+				}
+			}
+			if got := len(a.telegram.sent("getFile")); got != tt.wantGetFiles {
+				t.Errorf("called getFile %d times, want %d", got, tt.wantGetFiles)
+			}
+		})
+	}
+}
+
+func TestTranscribeVoiceRejectsOversizedFiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		reportedSize int // file_size in the voice message
+		getFileSize  int // file_size returned by getFile
+		wantGetFiles int
+	}{
+		{"size in the message", 25 << 20, 25 << 20, 0},
+		{"size reported by getFile", 0, 25 << 20, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.VoiceMessages = true
+			a.telegram.respond = func(method string, payload map[string]interface{}) (int, string) {
+				if method == "getFile" {
+					return http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"file_path":"voice/big.ogg","file_size":%d}}`, tt.getFileSize)
+				}
+				return 0, ""
+			}
+
+			message := &types.TelegramMessage{
+				MessageID: 10,
+				Chat:      types.TelegramChat{ID: 1},
+				From:      types.TelegramUser{ID: 7},
+				Voice:     &types.TelegramVoice{FileID: "big", Duration: 30, FileSize: tt.reportedSize},
+			}
+			transcript, err := a.TranscribeVoice(context.Background(), message)
+			if err != nil || transcript != "" {
+				t.Fatalf("TranscribeVoice() = %q, %v; want no transcript and no error", transcript, err)
+			}
+			if texts := a.telegram.texts(); len(texts) != 1 || texts[0] != voiceTooLargeMessage {
+				t.Errorf("sent %q, want the size limit message", texts)
+			}
+			if got := len(a.telegram.sent("getFile")); got != tt.wantGetFiles {
+				t.Errorf("called getFile %d times, want %d", got, tt.wantGetFiles)
+			}
+			if downloads := a.telegram.sent("big.ogg"); len(downloads) != 0 {
+				t.Error("downloaded a file over the size limit")
+			}
+		})
+	}
+}
+
+func TestTranscribeVoiceSkipsDiscardedQuestions(t *testing.T) {
+	quietNight := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		setup        func(a *testApp)
+		wantDownload bool
+	}{
+		{"allowed", func(a *testApp) {}, true},
+		{"user limit reached", func(a *testApp) {
+			for i := 0; i < usage.DefaultLimit; i++ {
+				a.UsageCache.AddUsage(7)
+			}
+		}, false},
+		{"chat limit reached", func(a *testApp) {
+			a.UsageCache.SetChatLimit(1, time.Hour)
+			a.UsageCache.AddChatUsage(1)
+		}, false},
+		{"chat limit applies to no-limit users", func(a *testApp) {
+			a.NoLimitUsers[7] = struct{}{}
+			a.UsageCache.SetChatLimit(1, time.Hour)
+			a.UsageCache.AddChatUsage(1)
+		}, false},
+		{"quiet hours", func(a *testApp) {
+			a.QuietHours = &QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC}
+			a.now = func() time.Time { return quietNight }
+		}, false},
+		{"no-limit user during quiet hours", func(a *testApp) {
+			a.NoLimitUsers[7] = struct{}{}
+			a.QuietHours = &QuietHours{StartHour: 22, EndHour: 6, Location: time.UTC}
+			a.now = func() time.Time { return quietNight }
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.VoiceMessages = true
+			serveVoiceFile(a)
+			tt.setup(a)
+
+			message := &types.TelegramMessage{
+				MessageID: 10,
+				Chat:      types.TelegramChat{ID: 1},
+				From:      types.TelegramUser{ID: 7},
+				Voice:     &types.TelegramVoice{FileID: "note", Duration: 5, FileSize: 1024},
+			}
+			transcript, _ := a.TranscribeVoice(context.Background(), message)
+			downloaded := len(a.telegram.sent("note.ogg")) > 0
+			if downloaded != tt.wantDownload {
+				t.Errorf("downloaded the voice message: %v, want %v", downloaded, tt.wantDownload)
+			}
+			if !tt.wantDownload && transcript != voicePlaceholder {
+				t.Errorf("TranscribeVoice() = %q, want the placeholder", transcript)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// internal/app/webhook_test.go
+
+package app
+
+import "testing"
+
+func TestVerifyWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		token      string
+		want       bool
+	}{
+		{"disabled accepts without a token", "", "", true},
+		{"disabled accepts any token", "", "anything", true},
+		{"matching token", "s3cret-token", "s3cret-token", true},
+		{"missing token", "s3cret-token", "", false},
+		{"wrong token", "s3cret-token", "guess", false},
+		{"prefix of the token", "s3cret-token", "s3cret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.WebhookSecret = tt.configured
+			if got := a.VerifyWebhookSecret(tt.token); got != tt.want {
+				t.Errorf("VerifyWebhookSecret(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// internal/app/whatsapp.go
+
+package app
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ProcessWhatsAppMessage answers a message sent through the WhatsApp adapter and returns the reply text.
+// A wa_id is the sender's phone number, so it serves as both the user and the chat ID for WhatsApp's own rate limits.
+func (a *App) ProcessWhatsAppMessage(waID, text string) (string, error) {
+	uid, err := strconv.Atoi(waID)
+	if err != nil {
+		return "", fmt.Errorf("invalid WhatsApp ID %q: %w", waID, err)
+	}
+	return a.answerPlainText("whatsapp", int64(uid), uid, "whatsapp:"+waID, text)
+}
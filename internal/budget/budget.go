@@ -0,0 +1,117 @@
+// internal/budget/budget.go
+
+package budget
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// ErrBudgetExceeded is returned instead of calling OpenAI when the spending cap is reached and no fallback model is set
+var ErrBudgetExceeded = errors.New("OpenAI spending cap reached")
+
+// Price is the cost in USD per 1,000 prompt and completion tokens
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// DefaultPrices holds list prices for the models the bot uses; unknown models are charged at the gpt-4o rate
+var DefaultPrices = map[string]Price{
+	"gpt-4o-mini": {Prompt: 0.00015, Completion: 0.0006},
+	"gpt-4o":      {Prompt: 0.0025, Completion: 0.01},
+}
+
+// Tracker accumulates estimated OpenAI spend within a rolling window and flags when the ceiling is reached.
+// The spend is kept in memory per process: a restart starts a new window, and replicas each enforce their own ceiling.
+type Tracker struct {
+	ceiling  float64
+	window   time.Duration
+	prices   map[string]Price
+	onChange func(degraded bool, spent, ceiling float64) // Called when the tracker enters or leaves degraded mode
+	now      func() time.Time                            // Injectable clock
+
+	mutex       sync.Mutex
+	spent       float64
+	windowStart time.Time
+	degraded    bool
+}
+
+// NewTracker initializes a Tracker with a ceiling in USD per window.
+// The clock defaults to time.Now when now is nil.
+func NewTracker(ceiling float64, window time.Duration, onChange func(degraded bool, spent, ceiling float64), now func() time.Time) *Tracker {
+	if now == nil {
+		now = time.Now
+	}
+	return &Tracker{
+		ceiling:     ceiling,
+		window:      window,
+		prices:      DefaultPrices,
+		onChange:    onChange,
+		now:         now,
+		windowStart: now(),
+	}
+}
+
+// Record adds the estimated cost of a completed OpenAI call.
+func (t *Tracker) Record(model string, usage types.OpenAIUsage) {
+	price, ok := t.prices[model]
+	if !ok {
+		price = t.prices["gpt-4o"]
+	}
+	cost := float64(usage.PromptTokens)/1000*price.Prompt + float64(usage.CompletionTokens)/1000*price.Completion
+
+	t.mutex.Lock()
+	t.rollWindow()
+	t.spent += cost
+	changed := t.updateMode()
+	degraded, spent := t.degraded, t.spent
+	t.mutex.Unlock()
+
+	if changed && t.onChange != nil {
+		t.onChange(degraded, spent, t.ceiling)
+	}
+}
+
+// Exceeded reports whether the ceiling has been reached in the current window.
+func (t *Tracker) Exceeded() bool {
+	t.mutex.Lock()
+	t.rollWindow()
+	changed := t.updateMode()
+	degraded, spent := t.degraded, t.spent
+	t.mutex.Unlock()
+
+	if changed && t.onChange != nil {
+		t.onChange(degraded, spent, t.ceiling)
+	}
+	return degraded
+}
+
+// Spent returns the estimated spend in the current window.
+func (t *Tracker) Spent() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.rollWindow()
+	return t.spent
+}
+
+// rollWindow resets the spend once the window has elapsed. Callers must hold the mutex.
+func (t *Tracker) rollWindow() {
+	if now := t.now(); now.Sub(t.windowStart) >= t.window {
+		t.windowStart = now
+		t.spent = 0
+	}
+}
+
+// updateMode syncs the degraded flag with the current spend and reports whether it changed. Callers must hold the mutex.
+func (t *Tracker) updateMode() bool {
+	degraded := t.spent >= t.ceiling
+	if degraded == t.degraded {
+		return false
+	}
+	t.degraded = degraded
+	return true
+}
@@ -0,0 +1,80 @@
+// internal/budget/budget_test.go
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// alert is one onChange notification.
+type alert struct {
+	degraded bool
+	spent    float64
+}
+
+func TestTrackerFlipsModeAndAlerts(t *testing.T) {
+	// 1,000 gpt-4o completion tokens cost $0.01
+	call := types.OpenAIUsage{CompletionTokens: 1000}
+
+	tests := []struct {
+		name         string
+		calls        int
+		advance      time.Duration // Clock advance after the calls, before checking
+		wantExceeded bool
+		wantAlerts   []bool // degraded flag of each alert, in order
+	}{
+		{"under the ceiling", 2, 0, false, nil},
+		{"reaching the ceiling degrades", 3, 0, true, []bool{true}},
+		{"still within the window", 5, 30 * time.Minute, true, []bool{true}},
+		{"window rollover resumes", 3, time.Hour, false, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			var alerts []alert
+			tracker := NewTracker(0.03, time.Hour, func(degraded bool, spent, ceiling float64) {
+				alerts = append(alerts, alert{degraded, spent})
+			}, func() time.Time { return now })
+
+			for i := 0; i < tt.calls; i++ {
+				tracker.Record("gpt-4o", call)
+			}
+			now = now.Add(tt.advance)
+
+			if got := tracker.Exceeded(); got != tt.wantExceeded {
+				t.Errorf("Exceeded() = %v, want %v", got, tt.wantExceeded)
+			}
+			if len(alerts) != len(tt.wantAlerts) {
+				t.Fatalf("got %d alerts %v, want %v", len(alerts), alerts, tt.wantAlerts)
+			}
+			for i, want := range tt.wantAlerts {
+				if alerts[i].degraded != want {
+					t.Errorf("alert %d degraded = %v, want %v", i, alerts[i].degraded, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTrackerPricesByModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-4o-mini", 0.00075},
+		{"gpt-4o", 0.0125},
+		{"some-new-model", 0.0125},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			tracker := NewTracker(1, time.Hour, nil, nil)
+			tracker.Record(tt.model, types.OpenAIUsage{PromptTokens: 1000, CompletionTokens: 1000})
+			if got := tracker.Spent(); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("Spent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
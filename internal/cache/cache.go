@@ -4,53 +4,137 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// entry is a cached value with an optional expiry.
+type entry struct {
+	value    string
+	expireAt time.Time // Zero means the entry never expires
+}
+
+// expired reports whether the entry's expiry has passed at now.
+func (e entry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
 // Cache represents a thread-safe in-memory cache.
 type Cache struct {
-	data  map[string]string
-	mutex sync.RWMutex
+	data   map[string]entry
+	mutex  sync.RWMutex
+	hits   uint64 // Number of Get calls that found a value
+	misses uint64 // Number of Get calls that found nothing
 }
 
 // NewCache initializes and returns a new Cache instance.
 func NewCache() *Cache {
 	return &Cache{
-		data: make(map[string]string),
+		data: make(map[string]entry),
 	}
 }
 
-// Get retrieves the value associated with the given key.
+// Get retrieves the value associated with the given key. Expired entries are treated as missing.
 func (c *Cache) Get(key string) (string, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
-	val, exists := c.data[key]
-	return val, exists
+	e, exists := c.data[key]
+	if exists && e.expired(time.Now()) {
+		exists = false
+	}
+	if exists {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return e.value, exists
+}
+
+// Stats returns the number of cache hits and misses recorded by Get.
+func (c *Cache) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
 }
 
-// Set assigns a value to the given key in the cache.
+// Set assigns a value to the given key in the cache. The entry never expires.
 func (c *Cache) Set(key, value string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	c.data[key] = value
+	c.data[key] = entry{value: value}
+}
+
+// SetWithTTL assigns a value to the given key that expires after ttl. A ttl of 0 or less never expires.
+func (c *Cache) SetWithTTL(key, value string, ttl time.Duration) {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.data[key] = e
+}
+
+// Add assigns a value to the given key that expires after ttl, unless the key already holds an unexpired value.
+// It reports whether the value was stored, so concurrent callers can claim a key exactly once.
+func (c *Cache) Add(key, value string, ttl time.Duration) bool {
+	now := time.Now()
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expireAt = now.Add(ttl)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if existing, exists := c.data[key]; exists && !existing.expired(now) {
+		return false
+	}
+	c.data[key] = e
+	return true
+}
+
+// Take removes the given key and returns its value, reporting whether it held an unexpired value.
+// Concurrent callers can use it to consume a key exactly once.
+func (c *Cache) Take(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, exists := c.data[key]
+	if !exists {
+		return "", false
+	}
+	delete(c.data, key)
+	if e.expired(time.Now()) {
+		return "", false
+	}
+	return e.value, true
+}
+
+// Delete removes the given key from the cache.
+func (c *Cache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.data, key)
 }
 
 // StartEviction periodically removes expired entries from the cache.
-// Currently, it deletes all entries at each interval.
-// Implement TTL checks or other eviction policies as needed.
+// Entries stored with Set never expire and are kept.
 func (c *Cache) StartEviction(interval time.Duration) {
 	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
 
 	go func() {
-		for range ticker.C {
-			c.mutex.Lock()
-			for key := range c.data { // Removed 'value' as it's unused
-				// TODO: Implement TTL checks or other eviction policies
-				// Example: delete all entries (replace with actual logic)
-				delete(c.data, key)
-			}
-			c.mutex.Unlock()
+		defer ticker.Stop()
+		for now := range ticker.C {
+			c.evictExpired(now)
 		}
 	}()
 }
+
+// evictExpired deletes every entry whose expiry has passed at now.
+func (c *Cache) evictExpired(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, e := range c.data {
+		if e.expired(now) {
+			delete(c.data, key)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// internal/cache/cache_test.go
+
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     func(c *Cache)
+		wait      time.Duration
+		wantValue string
+		wantFound bool
+	}{
+		{"Set never expires", func(c *Cache) { c.Set("k", "v") }, 20 * time.Millisecond, "v", true},
+		{"unexpired TTL entry", func(c *Cache) { c.SetWithTTL("k", "v", time.Hour) }, 0, "v", true},
+		{"expired TTL entry", func(c *Cache) { c.SetWithTTL("k", "v", 10*time.Millisecond) }, 20 * time.Millisecond, "", false},
+		{"zero TTL never expires", func(c *Cache) { c.SetWithTTL("k", "v", 0) }, 20 * time.Millisecond, "v", true},
+		{"missing key", func(c *Cache) {}, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCache()
+			tt.store(c)
+			time.Sleep(tt.wait)
+			value, found := c.Get("k")
+			if found != tt.wantFound || (found && value != tt.wantValue) {
+				t.Errorf("Get = (%q, %v), want (%q, %v)", value, found, tt.wantValue, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestEvictExpired(t *testing.T) {
+	c := NewCache()
+	c.Set("forever", "v")
+	c.SetWithTTL("short", "v", time.Minute)
+	c.SetWithTTL("long", "v", time.Hour)
+
+	c.evictExpired(time.Now().Add(2 * time.Minute))
+
+	tests := []struct {
+		key  string
+		kept bool
+	}{
+		{"forever", true},
+		{"short", false},
+		{"long", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			c.mutex.RLock()
+			_, kept := c.data[tt.key]
+			c.mutex.RUnlock()
+			if kept != tt.kept {
+				t.Errorf("entry %q kept = %v, want %v", tt.key, kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestStartEvictionSweepsExpiredEntries(t *testing.T) {
+	c := NewCache()
+	c.Set("forever", "v")
+	c.SetWithTTL("short", "v", 5*time.Millisecond)
+	c.StartEviction(5 * time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.mutex.RLock()
+		_, short := c.data["short"]
+		_, forever := c.data["forever"]
+		c.mutex.RUnlock()
+		if !forever {
+			t.Fatal("sweep removed an entry without expiry")
+		}
+		if !short {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sweep did not remove the expired entry")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAddAndTakeIgnoreExpiredEntries(t *testing.T) {
+	c := NewCache()
+	c.SetWithTTL("k", "old", 5*time.Millisecond)
+	if c.Add("k", "new", time.Hour) {
+		t.Fatal("Add replaced an unexpired entry")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !c.Add("k", "new", time.Hour) {
+		t.Fatal("Add did not replace an expired entry")
+	}
+	if value, found := c.Take("k"); !found || value != "new" {
+		t.Fatalf("Take = (%q, %v), want (\"new\", true)", value, found)
+	}
+	if _, found := c.Take("k"); found {
+		t.Error("Take returned a value twice")
+	}
+}
+
+func TestAddClaimsAKeyOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(c *Cache, key string) // Run between the two claims
+		want    bool                       // Whether the second claim succeeds
+	}{
+		{"claimed", func(c *Cache, key string) {}, false},
+		{"deleted", func(c *Cache, key string) { c.Delete(key) }, true},
+		{"other key deleted", func(c *Cache, key string) { c.Delete("other") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCache()
+			if !c.Add("k", "", time.Hour) {
+				t.Fatal("first Add of a new key failed")
+			}
+			tt.release(c, "k")
+			if got := c.Add("k", "", time.Hour); got != tt.want {
+				t.Errorf("second Add = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
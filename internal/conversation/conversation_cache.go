@@ -56,6 +56,13 @@ func (cc *ConversationCache) Get(key string) (string, bool) {
 	return entry.data, true
 }
 
+// Delete removes a conversation context.
+func (cc *ConversationCache) Delete(key string) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	delete(cc.data, key)
+}
+
 // cleanupExpiredContexts periodically removes expired contexts.
 func (cc *ConversationCache) cleanupExpiredContexts() {
 	ticker := time.NewTicker(cc.expiry)
@@ -0,0 +1,33 @@
+// internal/conversation/conversation_cache_test.go
+
+package conversation
+
+import "testing"
+
+func TestConversationCacheDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		delete    string
+		wantFirst bool // Whether "user_1" is still cached
+	}{
+		{"deletes the context", "user_1", false},
+		{"leaves other contexts", "user_2", true},
+		{"missing key is a no-op", "user_3", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := NewConversationCache()
+			defer cc.Close()
+			cc.Set("user_1", "first")
+			cc.Set("user_2", "second")
+
+			cc.Delete(tt.delete)
+			if _, found := cc.Get("user_1"); found != tt.wantFirst {
+				t.Errorf("user_1 cached: %v, want %v", found, tt.wantFirst)
+			}
+			if _, found := cc.Get(tt.delete); found {
+				t.Errorf("%s is still cached after Delete", tt.delete)
+			}
+		})
+	}
+}
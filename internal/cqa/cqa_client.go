@@ -0,0 +1,100 @@
+// internal/cqa/cqa_client.go
+
+package cqa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/httpclient"
+	"ReelTalkBot-Go/internal/types"
+)
+
+// noAnswerText is the default answer Azure Question Answering returns when nothing matches.
+const noAnswerText = "No good match found in KB."
+
+// CQAClient queries an Azure Custom Question Answering (CQA) deployment.
+type CQAClient struct {
+	Endpoint            string  // Full query-knowledgebases URL including project, deployment, and api-version
+	APIKey              string  // Ocp-Apim-Subscription-Key for the Language resource
+	ConfidenceThreshold float64 // Minimum confidence score for an answer to be used
+	Client              *http.Client
+}
+
+// cqaQuery represents the payload sent to the CQA query endpoint.
+type cqaQuery struct {
+	Question                 string  `json:"question"`
+	Top                      int     `json:"top"`
+	ConfidenceScoreThreshold float64 `json:"confidenceScoreThreshold"`
+}
+
+// cqaResponse represents the response received from the CQA query endpoint.
+type cqaResponse struct {
+	Answers []struct {
+		Answer          string  `json:"answer"`
+		ConfidenceScore float64 `json:"confidenceScore"`
+	} `json:"answers"`
+}
+
+// NewCQAClient initializes a new CQAClient
+func NewCQAClient(endpoint, apiKey string, confidenceThreshold float64) *CQAClient {
+	return &CQAClient{
+		Endpoint:            endpoint,
+		APIKey:              apiKey,
+		ConfidenceThreshold: confidenceThreshold,
+		Client:              httpclient.New(5 * time.Second),
+	}
+}
+
+// GetAnswer returns the best CQA answer for the question.
+// It returns an empty string when CQA has no answer above the confidence threshold.
+func (c *CQAClient) GetAnswer(ctx context.Context, question string) (string, error) {
+	payloadBytes, err := json.Marshal(cqaQuery{
+		Question:                 question,
+		Top:                      1,
+		ConfidenceScoreThreshold: c.ConfidenceThreshold,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal CQA query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create CQA request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ocp-Apim-Subscription-Key", c.APIKey)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send CQA request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", &types.APIError{Service: "CQA", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	var result cqaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode CQA response: %w", err)
+	}
+
+	if len(result.Answers) == 0 {
+		return "", nil
+	}
+
+	best := result.Answers[0]
+	answer := strings.TrimSpace(best.Answer)
+	if answer == "" || answer == noAnswerText || best.ConfidenceScore < c.ConfidenceThreshold {
+		return "", nil
+	}
+	return answer, nil
+}
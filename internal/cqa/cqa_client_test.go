@@ -0,0 +1,63 @@
+// internal/cqa/cqa_client_test.go
+
+package cqa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestGetAnswer(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		want       string
+		wantStatus int // Status of the expected *types.APIError; 0 expects no error
+	}{
+		{"confident answer", http.StatusOK, `{"answers":[{"answer":" Use a jig. ","confidenceScore":0.9}]}`, "Use a jig.", 0},
+		{"below the threshold", http.StatusOK, `{"answers":[{"answer":"Use a jig.","confidenceScore":0.3}]}`, "", 0},
+		{"blank answer", http.StatusOK, `{"answers":[{"answer":"  ","confidenceScore":0.9}]}`, "", 0},
+		{"default no-match answer", http.StatusOK, `{"answers":[{"answer":"No good match found in KB.","confidenceScore":0.9}]}`, "", 0},
+		{"no answers", http.StatusOK, `{"answers":[]}`, "", 0},
+		{"service error", http.StatusUnauthorized, `{"error":"bad key"}`, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query cqaQuery
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Ocp-Apim-Subscription-Key"); got != "key" {
+					t.Errorf("subscription key header = %q, want %q", got, "key")
+				}
+				json.NewDecoder(r.Body).Decode(&query)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			answer, err := NewCQAClient(server.URL, "key", 0.5).GetAnswer(context.Background(), "Best bait for bass?")
+			if tt.wantStatus != 0 {
+				var apiErr *types.APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus || apiErr.Service != "CQA" {
+					t.Fatalf("GetAnswer() error = %v, want a CQA APIError with status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAnswer() error = %v", err)
+			}
+			if answer != tt.want {
+				t.Errorf("GetAnswer() = %q, want %q", answer, tt.want)
+			}
+			if query.Question != "Best bait for bass?" || query.Top != 1 || query.ConfidenceScoreThreshold != 0.5 {
+				t.Errorf("sent query %+v", query)
+			}
+		})
+	}
+}
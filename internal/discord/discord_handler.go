@@ -0,0 +1,253 @@
+// internal/discord/discord_handler.go
+
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/handlers"
+	"ReelTalkBot-Go/internal/httpclient"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// discordAPIBase is the Discord REST API used to deliver interaction responses.
+const discordAPIBase = "https://discord.com/api/v10"
+
+// messageLimit is the maximum length of a Discord message.
+const messageLimit = 2000
+
+// maxBodyBytes caps the size of an interaction payload.
+const maxBodyBytes = 1 << 20
+
+// maxTimestampSkew is how far an interaction's signed timestamp may be from now, so a captured request can't be replayed later.
+const maxTimestampSkew = 5 * time.Minute
+
+// Interaction types sent by Discord
+const (
+	interactionPing               = 1
+	interactionApplicationCommand = 2
+)
+
+// Interaction response types sent back to Discord
+const (
+	responsePong                   = 1
+	responseChannelMessage         = 4
+	responseDeferredChannelMessage = 5
+)
+
+// interaction is the subset of a Discord interaction payload the bot uses.
+type interaction struct {
+	Type      int    `json:"type"`
+	Token     string `json:"token"`
+	ChannelID string `json:"channel_id"`
+	Member    *struct {
+		User discordUser `json:"user"`
+	} `json:"member,omitempty"` // Set in guild channels
+	User *discordUser `json:"user,omitempty"` // Set in direct messages
+	Data struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+// discordUser identifies the user who invoked an interaction.
+type discordUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name,omitempty"`
+}
+
+// DiscordHandler answers Discord slash commands through the same pipeline as Telegram messages.
+type DiscordHandler struct {
+	PublicKey     ed25519.PublicKey // Application public key used to verify interaction signatures
+	ApplicationID string
+	Processor     handlers.DiscordProcessor
+	Client        *http.Client
+}
+
+// NewDiscordHandler initializes a DiscordHandler from the application's hex-encoded public key.
+func NewDiscordHandler(publicKeyHex, applicationID string, processor handlers.DiscordProcessor) (*DiscordHandler, error) {
+	publicKey, err := hex.DecodeString(strings.TrimSpace(publicKeyHex))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Discord public key")
+	}
+	return &DiscordHandler{
+		PublicKey:     publicKey,
+		ApplicationID: applicationID,
+		Processor:     processor,
+		Client:        httpclient.New(10 * time.Second),
+	}, nil
+}
+
+// HandleDiscordInteraction is the HTTP handler for Discord's interactions endpoint. It verifies the request
+// signature, answers pings, and defers slash commands so the answer can be posted once it is ready.
+func (dh *DiscordHandler) HandleDiscordInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	// Discord rejects endpoints that accept requests with invalid signatures
+	if !dh.verifySignature(r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body) {
+		log.Printf("Rejected Discord interaction with an invalid signature")
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var in interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		log.Printf("Failed to decode Discord interaction: %v", err)
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch in.Type {
+	case interactionPing:
+		writeResponse(w, map[string]interface{}{"type": responsePong})
+
+	case interactionApplicationCommand:
+		question := in.question()
+		if question == "" {
+			writeResponse(w, map[string]interface{}{
+				"type": responseChannelMessage,
+				"data": map[string]string{"content": "Please include a question, e.g. `/ask How do I rig a drop shot?`"},
+			})
+			return
+		}
+
+		// Discord expects a response within 3 seconds, so acknowledge now and post the answer later
+		writeResponse(w, map[string]interface{}{"type": responseDeferredChannelMessage})
+		go dh.answer(in, question)
+
+	default:
+		log.Printf("Ignoring Discord interaction of type %d", in.Type)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// verifySignature checks the Ed25519 signature Discord computes over the timestamp and body,
+// rejecting timestamps more than maxTimestampSkew away from now.
+func (dh *DiscordHandler) verifySignature(signatureHex, timestamp string, body []byte) bool {
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := time.Since(time.Unix(seconds, 0)); skew > maxTimestampSkew || skew < -maxTimestampSkew {
+		return false
+	}
+	return ed25519.Verify(dh.PublicKey, append([]byte(timestamp), body...), signature)
+}
+
+// question returns the text of the command's first string option.
+func (in interaction) question() string {
+	for _, option := range in.Data.Options {
+		var value string
+		if err := json.Unmarshal(option.Value, &value); err == nil && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// user returns whoever invoked the interaction, in a guild channel or a direct message.
+func (in interaction) user() discordUser {
+	if in.Member != nil {
+		return in.Member.User
+	}
+	if in.User != nil {
+		return *in.User
+	}
+	return discordUser{}
+}
+
+// answer runs the question through the processor and posts the reply as the deferred response.
+func (dh *DiscordHandler) answer(in interaction, question string) {
+	user := in.user()
+	username := user.Username
+	if user.GlobalName != "" {
+		username = user.GlobalName
+	}
+	log.Printf("Received Discord /%s from user %s (%s) in channel %s: %s", in.Data.Name, user.ID, username, in.ChannelID, question)
+
+	reply, err := dh.Processor.ProcessDiscordMessage(in.ChannelID, user.ID, username, question)
+	if err != nil {
+		log.Printf("Failed to process Discord message: %v", err)
+		reply = "Sorry, something went wrong while answering. Please try again later."
+	}
+
+	// The first chunk replaces the "thinking" placeholder; the rest are sent as follow-up messages
+	for i, chunk := range utils.SplitMessage(reply, messageLimit) {
+		method, endpoint := http.MethodPost, fmt.Sprintf("%s/webhooks/%s/%s", discordAPIBase, dh.ApplicationID, in.Token)
+		if i == 0 {
+			method, endpoint = http.MethodPatch, endpoint+"/messages/@original"
+		}
+		if err := dh.send(method, endpoint, chunk); err != nil {
+			log.Printf("Failed to send Discord reply: %v", err)
+			return
+		}
+	}
+}
+
+// send posts message content to a Discord interaction webhook.
+func (dh *DiscordHandler) send(method, endpoint, content string) error {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"content":          content,
+		"allowed_mentions": map[string][]string{"parse": {}}, // Never ping anyone from an answer
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := dh.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("discord API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// writeResponse writes an interaction response as JSON.
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to write Discord interaction response: %v", err)
+	}
+}
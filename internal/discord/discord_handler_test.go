@@ -0,0 +1,100 @@
+// internal/discord/discord_handler_test.go
+
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubProcessor answers every Discord question with a fixed reply.
+type stubProcessor struct{}
+
+func (stubProcessor) ProcessDiscordMessage(channelID, userID, username, userQuestion string) (string, error) {
+	return "reply", nil
+}
+
+// newTestHandler returns a handler with a fresh key pair and the private key to sign requests with.
+func newTestHandler(t *testing.T) (*DiscordHandler, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dh, err := NewDiscordHandler(hex.EncodeToString(publicKey), "app", stubProcessor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dh, privateKey
+}
+
+// sign returns the X-Signature-Ed25519 header Discord would send for timestamp and body.
+func sign(key ed25519.PrivateKey, timestamp, body string) string {
+	return hex.EncodeToString(ed25519.Sign(key, []byte(timestamp+body)))
+}
+
+func TestVerifySignature(t *testing.T) {
+	dh, key := newTestHandler(t)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	body := `{"type":1}`
+	now := time.Now()
+	unix := func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		want      bool
+	}{
+		{"current timestamp", unix(now), sign(key, unix(now), body), true},
+		{"slightly old timestamp", unix(now.Add(-time.Minute)), sign(key, unix(now.Add(-time.Minute)), body), true},
+		{"stale timestamp", unix(now.Add(-10 * time.Minute)), sign(key, unix(now.Add(-10*time.Minute)), body), false},
+		{"future timestamp", unix(now.Add(10 * time.Minute)), sign(key, unix(now.Add(10*time.Minute)), body), false},
+		{"non-numeric timestamp", "yesterday", sign(key, "yesterday", body), false},
+		{"missing timestamp", "", sign(key, "", body), false},
+		{"signed with another key", unix(now), sign(otherKey, unix(now), body), false},
+		{"malformed signature", unix(now), "zz", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dh.verifySignature(tt.signature, tt.timestamp, []byte(body)); got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleDiscordInteractionPing(t *testing.T) {
+	dh, key := newTestHandler(t)
+	body := `{"type":1}`
+
+	tests := []struct {
+		name       string
+		timestamp  string
+		wantStatus int
+	}{
+		{"fresh ping is answered", strconv.FormatInt(time.Now().Unix(), 10), http.StatusOK},
+		{"replayed ping is rejected", strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/discord", strings.NewReader(body))
+			req.Header.Set("X-Signature-Timestamp", tt.timestamp)
+			req.Header.Set("X-Signature-Ed25519", sign(key, tt.timestamp, body))
+			rec := httptest.NewRecorder()
+			dh.HandleDiscordInteraction(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), `"type":1`) {
+				t.Errorf("body = %q, want a pong", rec.Body.String())
+			}
+		})
+	}
+}
@@ -2,13 +2,28 @@
 
 package handlers
 
-import "ReelTalkBot-Go/internal/types"
+import (
+	"context"
+
+	"ReelTalkBot-Go/internal/types"
+)
 
 // MessageProcessor defines the methods that the telegram package requires from the app package.
 type MessageProcessor interface {
-	ProcessMessage(chatID int64, userID int, username string, userQuestion string, messageID int) error
-	HandleCommand(message *types.TelegramMessage, userID int, username string) (string, error)
+	ProcessMessage(ctx context.Context, chatID int64, userID int, username string, userQuestion string, messageID int, meta types.MessageMeta) error
+	HandleCommand(ctx context.Context, message *types.TelegramMessage, userID int, username string) (string, error)
 	SendMessage(chatID int64, text string, replyToMessageID int) error
 	SendMessageWithKeyboard(chatID int64, text string, replyToMessageID int, keyboard string) error
 	GetBotUsername() string
+	TranscribeVoice(ctx context.Context, message *types.TelegramMessage) (string, error)
+}
+
+// DiscordProcessor defines the methods that the discord package requires from the app package.
+type DiscordProcessor interface {
+	ProcessDiscordMessage(channelID, userID, username, userQuestion string) (string, error)
+}
+
+// WhatsAppProcessor defines the methods that the whatsapp package requires from the app package.
+type WhatsAppProcessor interface {
+	ProcessWhatsAppMessage(waID, text string) (string, error)
 }
@@ -0,0 +1,58 @@
+// internal/httpclient/httpclient.go
+
+package httpclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Version is the ReelTalkBot release reported in the default User-Agent
+const Version = "1.0.0"
+
+// DefaultUserAgent identifies the bot in upstream request logs
+const DefaultUserAgent = "ReelTalkBot/" + Version
+
+var userAgent atomic.Value
+
+func init() {
+	userAgent.Store(DefaultUserAgent)
+}
+
+// SetUserAgent changes the User-Agent sent by every client created with New.
+// An empty value restores the default.
+func SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	userAgent.Store(ua)
+}
+
+// UserAgent returns the User-Agent currently sent on outbound requests
+func UserAgent() string {
+	return userAgent.Load().(string)
+}
+
+// userAgentTransport sets the User-Agent header on requests that don't already carry one
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the caller's request
+	clone := req.Clone(req.Context())
+	clone.Header.Set("User-Agent", UserAgent())
+	return t.base.RoundTrip(clone)
+}
+
+// New returns an HTTP client with the given timeout that identifies itself with the configured User-Agent
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &userAgentTransport{base: http.DefaultTransport},
+	}
+}
@@ -0,0 +1,55 @@
+// internal/httpclient/httpclient_test.go
+
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUserAgentHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		requestUA  string // Set by the caller on the request
+		want       string
+	}{
+		{"default", "", "", DefaultUserAgent},
+		{"configured", "ReelTalkBot-staging/2.0", "", "ReelTalkBot-staging/2.0"},
+		{"caller's header kept", "ReelTalkBot-staging/2.0", "custom-probe/1.0", "custom-probe/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetUserAgent(tt.configured)
+			defer SetUserAgent("")
+
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.requestUA != "" {
+				req.Header.Set("User-Agent", tt.requestUA)
+			}
+			resp, err := New(5 * time.Second).Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if got != tt.want {
+				t.Errorf("server saw User-Agent %q, want %q", got, tt.want)
+			}
+			if tt.requestUA == "" && req.Header.Get("User-Agent") != "" {
+				t.Error("the caller's request was modified")
+			}
+		})
+	}
+}
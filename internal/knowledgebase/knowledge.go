@@ -8,27 +8,112 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"time"
 
+	"ReelTalkBot-Go/internal/httpclient"
 	"ReelTalkBot-Go/internal/types"
 )
 
+// Default retry settings for Knowledge Base requests
+const (
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 500 * time.Millisecond
+	DefaultDeadline     = 15 * time.Second
+)
+
 // KnowledgeBaseClient handles communication with the Knowledge Base microservice
 type KnowledgeBaseClient struct {
-	BaseURL string
-	APIKey  string
-	Client  *http.Client
+	BaseURL      string
+	APIKey       string
+	Client       *http.Client
+	MaxRetries   int               // Extra attempts after a network error, 429, or 5xx response
+	RetryBackoff time.Duration     // Delay before the first retry, doubled on each further retry
+	Deadline     time.Duration     // Overall time allowed for a call including retries; 0 relies on the caller's context
+	RegionURLs   map[string]string // Optional base URLs keyed by region; BaseURL is the fallback
 }
 
 // NewKnowledgeBaseClient initializes a new KnowledgeBaseClient
 func NewKnowledgeBaseClient(baseURL, apiKey string) *KnowledgeBaseClient {
 	return &KnowledgeBaseClient{
-		BaseURL: baseURL,
-		APIKey:  apiKey,
-		Client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		BaseURL:      baseURL,
+		APIKey:       apiKey,
+		Client:       httpclient.New(10 * time.Second),
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
+		Deadline:     DefaultDeadline,
+	}
+}
+
+// ForRegion returns a client targeting the region's base URL, or the client itself when the region has none.
+func (k *KnowledgeBaseClient) ForRegion(region string) *KnowledgeBaseClient {
+	baseURL, ok := k.RegionURLs[region]
+	if !ok || baseURL == "" {
+		return k
+	}
+	regional := *k
+	regional.BaseURL = baseURL
+	return &regional
+}
+
+// do sends a request, retrying transient failures with exponential backoff until MaxRetries or the deadline.
+// It returns the final status code and body; err is only set when no response was received.
+func (k *KnowledgeBaseClient) do(ctx context.Context, method, endpoint string, payload []byte) (int, []byte, error) {
+	return k.doWithRetries(ctx, method, endpoint, payload, k.MaxRetries)
+}
+
+// doWithRetries sends a request like do, allowing at most maxRetries extra attempts.
+func (k *KnowledgeBaseClient) doWithRetries(ctx context.Context, method, endpoint string, payload []byte, maxRetries int) (int, []byte, error) {
+	if k.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.Deadline)
+		defer cancel()
+	}
+
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+		if err != nil {
+			return 0, nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-KEY", k.APIKey)
+
+		status := 0
+		var respBody []byte
+		resp, err := k.Client.Do(req)
+		if err == nil {
+			status = resp.StatusCode
+			respBody, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err == nil && status != http.StatusTooManyRequests && status < http.StatusInternalServerError {
+				return status, respBody, nil
+			}
+		}
+
+		if attempt >= maxRetries || ctx.Err() != nil {
+			if err != nil {
+				return 0, nil, err
+			}
+			return status, respBody, nil
+		}
+
+		backoff := k.RetryBackoff << attempt
+		log.Printf("Knowledge Base request to %s failed (attempt %d, status %d, err %v). Retrying in %s", endpoint, attempt+1, status, err, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if err != nil {
+				return 0, nil, err
+			}
+			return status, respBody, nil
+		case <-timer.C:
+		}
 	}
 }
 
@@ -42,34 +127,26 @@ func (k *KnowledgeBaseClient) GetKnowledgeEntries(ctx context.Context, params ty
 		return nil, fmt.Errorf("failed to marshal query parameters: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create knowledge base request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-KEY", k.APIKey)
-
-	resp, err := k.Client.Do(req)
+	status, bodyBytes, err := k.do(ctx, "POST", endpoint, payloadBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send knowledge base request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("knowledge base returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if status != http.StatusOK {
+		return nil, &types.APIError{Service: "knowledge base", StatusCode: status, Body: string(bodyBytes)}
 	}
 
 	var entries []types.KnowledgeEntryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+	if err := json.Unmarshal(bodyBytes, &entries); err != nil {
 		return nil, fmt.Errorf("failed to decode knowledge base response: %w", err)
 	}
 
 	return entries, nil
 }
 
-// UpdateKnowledgeEntryRating updates the ratings of a KB entry based on user feedback
-func (k *KnowledgeBaseClient) UpdateKnowledgeEntryRating(kbNumber int, rating string) error {
+// UpdateKnowledgeEntryRating updates the ratings of a KB entry based on user feedback.
+// The request is not retried, since the endpoint isn't idempotent.
+func (k *KnowledgeBaseClient) UpdateKnowledgeEntryRating(ctx context.Context, kbNumber int, rating string) error {
 	endpoint := fmt.Sprintf("%s/rate", k.BaseURL) // Append /rate directly
 
 	payload := map[string]string{
@@ -80,27 +157,14 @@ func (k *KnowledgeBaseClient) UpdateKnowledgeEntryRating(kbNumber int, rating st
 		return fmt.Errorf("failed to marshal rating payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create rating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-KEY", k.APIKey)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req = req.WithContext(ctx)
-
-	resp, err := k.Client.Do(req)
+	// A rating is counted on every POST, so a request that may have landed is never resent
+	status, bodyBytes, err := k.doWithRetries(ctx, "POST", endpoint, payloadBytes, 0)
 	if err != nil {
 		return fmt.Errorf("failed to send rating request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("rating endpoint returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if status != http.StatusOK {
+		return &types.APIError{Service: "rating endpoint", StatusCode: status, Body: string(bodyBytes)}
 	}
 
 	return nil
@@ -125,7 +189,7 @@ func (k *KnowledgeBaseClient) GetKnowledgeEntry(ctx context.Context, kbNumber in
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("knowledge base get endpoint returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, &types.APIError{Service: "knowledge base get endpoint", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	var entry types.KnowledgeEntryResponse
@@ -135,3 +199,27 @@ func (k *KnowledgeBaseClient) GetKnowledgeEntry(ctx context.Context, kbNumber in
 
 	return &entry, nil
 }
+
+// GetTopEntries retrieves up to limit of the most-accessed and highest-rated entries, best first.
+func (k *KnowledgeBaseClient) GetTopEntries(ctx context.Context, limit int) ([]types.KnowledgeEntryResponse, error) {
+	endpoint := fmt.Sprintf("%s/top?limit=%d", k.BaseURL, limit) // Append /top directly
+
+	status, bodyBytes, err := k.do(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send knowledge base top entries request: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return nil, &types.APIError{Service: "knowledge base top endpoint", StatusCode: status, Body: string(bodyBytes)}
+	}
+
+	var entries []types.KnowledgeEntryResponse
+	if err := json.Unmarshal(bodyBytes, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode knowledge base top entries response: %w", err)
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
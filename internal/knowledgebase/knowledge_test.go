@@ -0,0 +1,173 @@
+// internal/knowledgebase/knowledge_test.go
+
+package knowledgebase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// newTestClient returns a client for a server that answers each request with the next status in statuses,
+// repeating the last one, and a counter of the requests it received.
+func newTestClient(t *testing.T, statuses []int, body string) (*KnowledgeBaseClient, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.WriteHeader(statuses[n-1])
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client := NewKnowledgeBaseClient(server.URL, "key")
+	client.RetryBackoff = time.Millisecond
+	return client, &requests
+}
+
+func TestGetKnowledgeEntriesRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int32
+	}{
+		{"success", []int{http.StatusOK}, false, 1},
+		{"recovers after a 503", []int{http.StatusServiceUnavailable, http.StatusOK}, false, 2},
+		{"recovers after a 429", []int{http.StatusTooManyRequests, http.StatusOK}, false, 2},
+		{"gives up after MaxRetries", []int{http.StatusBadGateway}, true, DefaultMaxRetries + 1},
+		{"client errors are not retried", []int{http.StatusBadRequest}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, tt.statuses, `[{"kb_number":7}]`)
+			_, err := client.GetKnowledgeEntries(context.Background(), types.QueryParameters{Query: "bass"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestUpdateKnowledgeEntryRatingIsNotRetried(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+	}{
+		{"success", []int{http.StatusOK}, false},
+		{"server error", []int{http.StatusInternalServerError, http.StatusOK}, true},
+		{"rate limited", []int{http.StatusTooManyRequests, http.StatusOK}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, tt.statuses, `{}`)
+			err := client.UpdateKnowledgeEntryRating(context.Background(), 7, "Helpful")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(requests); got != 1 {
+				t.Errorf("sent %d rating requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestUpdateKnowledgeEntryRatingHonorsContext(t *testing.T) {
+	client, requests := newTestClient(t, []int{http.StatusOK}, `{}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.UpdateKnowledgeEntryRating(ctx, 7, "Helpful"); err == nil {
+		t.Fatal("rating with a cancelled context succeeded")
+	}
+	if got := atomic.LoadInt32(requests); got != 0 {
+		t.Errorf("sent %d requests after the caller gave up", got)
+	}
+}
+
+func TestForRegion(t *testing.T) {
+	client := NewKnowledgeBaseClient("https://kb.example.com", "key")
+	client.RegionURLs = map[string]string{"west": "https://west.kb.example.com"}
+
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"west", "https://west.kb.example.com"},
+		{"east", "https://kb.example.com"},
+		{"", "https://kb.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			if got := client.ForRegion(tt.region).BaseURL; got != tt.want {
+				t.Errorf("ForRegion(%q).BaseURL = %q, want %q", tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetKnowledgeEntriesReturnsAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{"bad request", http.StatusBadRequest},
+		{"unauthorized", http.StatusUnauthorized},
+		{"server error after retries", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, []int{tt.status}, `{"error":"nope"}`)
+			_, err := client.GetKnowledgeEntries(context.Background(), types.QueryParameters{Query: "bass"})
+			var apiErr *types.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("error = %v, want an APIError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestGetTopEntries(t *testing.T) {
+	const body = `[{"kb_number":1},{"kb_number":2},{"kb_number":3}]`
+	tests := []struct {
+		name        string
+		status      int
+		limit       int
+		wantEntries int
+		wantErr     bool
+	}{
+		{"all entries", http.StatusOK, 5, 3, false},
+		{"extra entries are dropped", http.StatusOK, 2, 2, false},
+		{"API error", http.StatusNotFound, 5, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, []int{tt.status}, body)
+			entries, err := client.GetTopEntries(context.Background(), tt.limit)
+			if tt.wantErr {
+				var apiErr *types.APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+					t.Fatalf("error = %v, want an APIError with status %d", err, tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTopEntries failed: %v", err)
+			}
+			if len(entries) != tt.wantEntries {
+				t.Errorf("got %d entries, want %d", len(entries), tt.wantEntries)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// internal/metrics/metrics.go
+
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry holds every ReelTalkBot metric, so /metrics only exposes what the bot registers here
+var registry = prometheus.NewRegistry()
+
+var (
+	// MessagesProcessed counts questions received for answering, including rate-limited ones.
+	MessagesProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "reeltalkbot_messages_processed_total",
+		Help: "Questions received for answering.",
+	})
+	// RateLimited counts questions turned away by the per-user or per-chat rate limit.
+	RateLimited = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "reeltalkbot_rate_limited_total",
+		Help: "Questions turned away by a rate limit.",
+	})
+	// KnowledgeBaseHits counts questions answered from the Knowledge Base.
+	KnowledgeBaseHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "reeltalkbot_knowledge_base_hits_total",
+		Help: "Questions answered from the Knowledge Base.",
+	})
+	// OpenAICalls counts requests sent to OpenAI to answer questions; answer cache hits are not counted.
+	OpenAICalls = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "reeltalkbot_openai_calls_total",
+		Help: "Requests sent to OpenAI to answer questions.",
+	})
+	// Errors counts questions that could not be answered.
+	Errors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "reeltalkbot_errors_total",
+		Help: "Questions that failed to be answered.",
+	})
+	// OpenAIResponseTime observes how long OpenAI answers took, in seconds.
+	OpenAIResponseTime = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "reeltalkbot_openai_response_seconds",
+		Help:    "Time taken to answer a question with OpenAI.",
+		Buckets: []float64{0.5, 1, 2, 4, 8, 15, 30, 60},
+	})
+)
+
+// CacheStats are the hit and miss counts a cache has recorded.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// cacheCollector exposes per-cache hit and miss counters, read from the source on every scrape.
+type cacheCollector struct {
+	hits   *prometheus.Desc
+	misses *prometheus.Desc
+
+	mutex  sync.RWMutex
+	source func() map[string]CacheStats
+}
+
+var caches = &cacheCollector{
+	hits:   prometheus.NewDesc("reeltalkbot_cache_hits_total", "Cache lookups that found a value.", []string{"cache"}, nil),
+	misses: prometheus.NewDesc("reeltalkbot_cache_misses_total", "Cache lookups that found nothing.", []string{"cache"}, nil),
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	source := c.source
+	c.mutex.RUnlock()
+	if source == nil {
+		return
+	}
+	for name, stats := range source() {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), name)
+	}
+}
+
+// SetCacheStatsSource sets the function that reports each cache's counts, keyed by the cache label.
+// Setting it again replaces the previous source.
+func SetCacheStatsSource(source func() map[string]CacheStats) {
+	caches.mutex.Lock()
+	defer caches.mutex.Unlock()
+	caches.source = source
+}
+
+func init() {
+	registry.MustRegister(MessagesProcessed, RateLimited, KnowledgeBaseHits, OpenAICalls, Errors, OpenAIResponseTime, caches)
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
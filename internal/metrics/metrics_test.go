@@ -0,0 +1,73 @@
+// internal/metrics/metrics_test.go
+
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCacheStatsMetrics(t *testing.T) {
+	SetCacheStatsSource(func() map[string]CacheStats {
+		return map[string]CacheStats{
+			"answer":         {Hits: 3, Misses: 1},
+			"knowledge_base": {Hits: 0, Misses: 5},
+		}
+	})
+	t.Cleanup(func() { SetCacheStatsSource(nil) })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	tests := []string{
+		`reeltalkbot_cache_hits_total{cache="answer"} 3`,
+		`reeltalkbot_cache_misses_total{cache="answer"} 1`,
+		`reeltalkbot_cache_hits_total{cache="knowledge_base"} 0`,
+		`reeltalkbot_cache_misses_total{cache="knowledge_base"} 5`,
+	}
+	for _, want := range tests {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %q", want)
+		}
+	}
+}
+
+func TestCacheStatsMetricsWithoutSource(t *testing.T) {
+	SetCacheStatsSource(nil)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "reeltalkbot_cache_hits_total{") {
+		t.Error("cache counters were exposed before any source was set")
+	}
+}
+
+func TestHandlerExposesBotMetrics(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	tests := []struct {
+		name    string
+		series  string
+		exposed bool
+	}{
+		{"messages processed", "reeltalkbot_messages_processed_total", true},
+		{"rate limited", "reeltalkbot_rate_limited_total", true},
+		{"knowledge base hits", "reeltalkbot_knowledge_base_hits_total", true},
+		{"OpenAI calls", "reeltalkbot_openai_calls_total", true},
+		{"errors", "reeltalkbot_errors_total", true},
+		{"OpenAI response time", "reeltalkbot_openai_response_seconds_bucket", true},
+		{"Go runtime metrics", "go_goroutines", false},
+		{"process metrics", "process_cpu_seconds_total", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(body, "\n"+tt.series); got != tt.exposed {
+				t.Errorf("/metrics exposes %s: %v, want %v", tt.series, got, tt.exposed)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// internal/middleware/access_log_test.go
+
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        string
+		requestID     string
+		status        int // Status written by the handler; 0 writes only a body
+		wantStatus    int
+		wantRequestID string // Empty expects a generated ID
+	}{
+		{"implicit 200", "/webhook", "", 0, http.StatusOK, ""},
+		{"explicit status", "/webhook", "", http.StatusNotFound, http.StatusNotFound, ""},
+		{"incoming request ID", "/health", "abc123", http.StatusOK, http.StatusOK, "abc123"},
+		{"query string omitted", "/webhook?token=secret", "", http.StatusOK, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defer log.SetOutput(log.Writer())
+			log.SetOutput(&logs)
+			handler := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte("ok"))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(`{"message":"private"}`))
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(requestIDHeader)
+			if tt.wantRequestID != "" && requestID != tt.wantRequestID {
+				t.Errorf("response request ID = %q, want %q", requestID, tt.wantRequestID)
+			}
+			if tt.wantRequestID == "" && !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(requestID) {
+				t.Errorf("generated request ID = %q, want 16 hex characters", requestID)
+			}
+
+			line := logs.String()
+			pattern := regexp.MustCompile(`access method=POST path=(\S+) status=(\d+) duration_ms=\d+ request_id=(\S+)`)
+			match := pattern.FindStringSubmatch(line)
+			if match == nil {
+				t.Fatalf("log %q has no access line", line)
+			}
+			if want := strings.SplitN(tt.target, "?", 2)[0]; match[1] != want {
+				t.Errorf("logged path %q, want %q", match[1], want)
+			}
+			if match[2] != strconv.Itoa(tt.wantStatus) {
+				t.Errorf("logged status %s, want %d", match[2], tt.wantStatus)
+			}
+			if match[3] != requestID {
+				t.Errorf("logged request ID %q, want %q", match[3], requestID)
+			}
+			if strings.Contains(line, "secret") || strings.Contains(line, "private") {
+				t.Errorf("log %q contains the query string or body", line)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// internal/prompts/prompts.go
+
+package prompts
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultExamplePrompts holds the example prompts shipped with the bot.
+//
+//go:embed example_prompts.json
+var defaultExamplePrompts []byte
+
+// ExamplePrompt is an example question offered as an inline keyboard button in /help.
+type ExamplePrompt struct {
+	Label  string `json:"label"`  // Button text
+	Prompt string `json:"prompt"` // Question sent when the button is tapped
+}
+
+// LoadExamplePrompts loads example prompts from a JSON file, or the embedded defaults when path is empty.
+// Entries missing a label or prompt are skipped.
+func LoadExamplePrompts(path string) ([]ExamplePrompt, error) {
+	data := defaultExamplePrompts
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read example prompts file: %w", err)
+		}
+		data = fileData
+	}
+
+	var loaded []ExamplePrompt
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse example prompts: %w", err)
+	}
+
+	var examples []ExamplePrompt
+	for _, example := range loaded {
+		if example.Label != "" && example.Prompt != "" {
+			examples = append(examples, example)
+		}
+	}
+	return examples, nil
+}
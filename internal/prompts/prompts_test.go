@@ -0,0 +1,56 @@
+// internal/prompts/prompts_test.go
+
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadExamplePrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // Written to a file; empty loads the embedded defaults
+		want    []ExamplePrompt
+		wantErr bool
+	}{
+		{
+			name:    "valid file",
+			content: `[{"label":"Bass","prompt":"Best bait for bass?"},{"label":"Trout","prompt":"Best fly for trout?"}]`,
+			want:    []ExamplePrompt{{"Bass", "Best bait for bass?"}, {"Trout", "Best fly for trout?"}},
+		},
+		{
+			name:    "incomplete entries skipped",
+			content: `[{"label":"Bass"},{"prompt":"Best fly for trout?"},{"label":"Pike","prompt":"Where are the pike?"}]`,
+			want:    []ExamplePrompt{{"Pike", "Where are the pike?"}},
+		},
+		{name: "invalid JSON", content: `{"label":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prompts.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadExamplePrompts(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadExamplePrompts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadExamplePrompts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadExamplePromptsDefaults(t *testing.T) {
+	got, err := LoadExamplePrompts("")
+	if err != nil || len(got) == 0 {
+		t.Fatalf("LoadExamplePrompts(\"\") = %d prompts, error %v; want the embedded defaults", len(got), err)
+	}
+	if _, err := LoadExamplePrompts(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
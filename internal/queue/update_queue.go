@@ -0,0 +1,51 @@
+// internal/queue/update_queue.go
+
+package queue
+
+import (
+	"ReelTalkBot-Go/internal/types"
+)
+
+// UpdateQueue buffers incoming Telegram updates in a bounded channel processed by a fixed pool of workers.
+type UpdateQueue struct {
+	updates chan *types.TelegramUpdate
+	handler func(*types.TelegramUpdate)
+}
+
+// NewUpdateQueue initializes an UpdateQueue holding up to size pending updates and starts the workers.
+func NewUpdateQueue(size, workers int, handler func(*types.TelegramUpdate)) *UpdateQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &UpdateQueue{
+		updates: make(chan *types.TelegramUpdate, size),
+		handler: handler,
+	}
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+// Enqueue adds an update to the queue without blocking.
+// It returns false when the queue is full so the caller can apply backpressure.
+func (q *UpdateQueue) Enqueue(update *types.TelegramUpdate) bool {
+	select {
+	case q.updates <- update:
+		return true
+	default:
+		return false
+	}
+}
+
+// Len returns the number of updates waiting to be processed.
+func (q *UpdateQueue) Len() int {
+	return len(q.updates)
+}
+
+// worker processes updates until the queue channel is closed.
+func (q *UpdateQueue) worker() {
+	for update := range q.updates {
+		q.handler(update)
+	}
+}
@@ -0,0 +1,92 @@
+// internal/queue/update_queue_test.go
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestUpdateQueueBoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		updates int
+		want    int32 // Maximum handlers running at once
+	}{
+		{"single worker", 1, 5, 1},
+		{"several workers", 3, 10, 3},
+		{"workers floored at one", 0, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak int32
+			var processed sync.Map
+			var wg sync.WaitGroup
+			wg.Add(tt.updates)
+			q := NewUpdateQueue(tt.updates, tt.workers, func(update *types.TelegramUpdate) {
+				defer wg.Done()
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				processed.Store(update.UpdateID, true)
+			})
+
+			for i := 1; i <= tt.updates; i++ {
+				if !q.Enqueue(&types.TelegramUpdate{UpdateID: i}) {
+					t.Fatalf("Enqueue(%d) = false with room in the queue", i)
+				}
+			}
+			wg.Wait()
+
+			if got := atomic.LoadInt32(&peak); got != tt.want {
+				t.Errorf("peak concurrency = %d, want %d", got, tt.want)
+			}
+			for i := 1; i <= tt.updates; i++ {
+				if _, ok := processed.Load(i); !ok {
+					t.Errorf("update %d was never processed", i)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateQueueRejectsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	q := NewUpdateQueue(2, 1, func(*types.TelegramUpdate) {
+		started <- struct{}{}
+		<-release
+	})
+	defer close(release)
+
+	// The worker holds the first update, so the queue then fills up with the next two
+	q.Enqueue(&types.TelegramUpdate{UpdateID: 1})
+	<-started
+	tests := []struct {
+		updateID int
+		want     bool
+	}{
+		{2, true},
+		{3, true},
+		{4, false},
+	}
+	for _, tt := range tests {
+		if got := q.Enqueue(&types.TelegramUpdate{UpdateID: tt.updateID}); got != tt.want {
+			t.Errorf("Enqueue(%d) = %v, want %v", tt.updateID, got, tt.want)
+		}
+	}
+	if got := q.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+}
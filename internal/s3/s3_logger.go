@@ -0,0 +1,311 @@
+// internal/s3/s3_logger.go
+
+package s3client
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Formats of the interaction log written to S3
+const (
+	LogFormatCSV   = "csv"   // A single CSV downloaded, appended to, and re-uploaded on every flush
+	LogFormatJSONL = "jsonl" // One JSON-lines object per flush under a daily prefix
+)
+
+// Default batching settings for the S3 logger
+const (
+	DefaultLogBatchSize     = 20
+	DefaultLogFlushInterval = 10 * time.Second
+)
+
+// csvLogKey is the object holding the CSV interaction log.
+const csvLogKey = "logs/telegram_logs.csv"
+
+// maxPendingRecords bounds the log records held in memory while S3 is unavailable.
+const maxPendingRecords = 1000
+
+// csvHeaders are the columns of the CSV interaction log.
+var csvHeaders = []string{
+	"userID",
+	"username",
+	"prompt",
+	"keywords",
+	"keyword_summary",
+	"categories",
+	"response_time",
+	"is_rate_limited",
+	"model",
+	"species",
+	"location",
+	"technique",
+	"gear",
+}
+
+// LogRecord is a single user interaction in the S3 log.
+type LogRecord struct {
+	Time           time.Time `json:"time"`
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Prompt         string    `json:"prompt"`
+	Keywords       string    `json:"keywords"`
+	KeywordSummary string    `json:"keyword_summary"`
+	Categories     string    `json:"categories"`
+	ResponseTime   string    `json:"response_time"`
+	IsRateLimited  bool      `json:"is_rate_limited"`
+	Model          string    `json:"model"`
+	Species        string    `json:"species,omitempty"`   // Structured entities, set when entity extraction is enabled
+	Location       string    `json:"location,omitempty"`  // Body of water mentioned
+	Technique      string    `json:"technique,omitempty"` // Fishing techniques mentioned
+	Gear           string    `json:"gear,omitempty"`      // Fishing gear mentioned
+}
+
+// csvRow returns the record as a row of the CSV log.
+func (r LogRecord) csvRow() []string {
+	return []string{
+		strconv.Itoa(r.UserID),
+		r.Username,
+		r.Prompt,
+		r.Keywords,
+		r.KeywordSummary,
+		r.Categories,
+		r.ResponseTime,
+		fmt.Sprintf("Rate limited: %t", r.IsRateLimited),
+		r.Model,
+		r.Species,
+		r.Location,
+		r.Technique,
+		r.Gear,
+	}
+}
+
+// S3Logger buffers interaction records in memory and writes them to S3 in batches from a background
+// goroutine, so S3 round-trips stay off the path of answering a message.
+type S3Logger struct {
+	Format string // LogFormatCSV or LogFormatJSONL
+
+	client        S3ClientInterface
+	bucket        string
+	keyPrefix     string // Prepended to every object key, so several bot instances can share a bucket
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex  // Guards pending
+	pending []LogRecord // Records waiting to be written, including ones from failed writes
+	writeMu sync.Mutex  // Serializes writes so CSV read-modify-write cycles never overlap
+
+	flushNow  chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewS3Logger initializes an S3Logger that writes objects under keyPrefix and flushes every batchSize records
+// or every flushInterval, whichever comes first, and starts its background goroutine. A flushInterval of 0
+// disables the timer.
+func NewS3Logger(client S3ClientInterface, bucket, keyPrefix string, batchSize int, flushInterval time.Duration) *S3Logger {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	l := &S3Logger{
+		Format:        LogFormatCSV,
+		client:        client,
+		bucket:        bucket,
+		keyPrefix:     keyPrefix,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		flushNow:      make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Enqueue adds a record to the buffer and wakes the background goroutine once a batch is full.
+func (l *S3Logger) Enqueue(record LogRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	l.pending = append(l.pending, record)
+	full := len(l.pending) >= l.batchSize
+	l.mu.Unlock()
+
+	if full {
+		select {
+		case l.flushNow <- struct{}{}:
+		default: // A flush is already pending
+		}
+	}
+}
+
+// Close stops the background goroutine and writes any buffered records. It is safe to call more than once.
+func (l *S3Logger) Close() {
+	l.closeOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.Flush()
+	})
+}
+
+// run flushes the buffer whenever a batch fills up or the flush interval elapses, until Close is called.
+func (l *S3Logger) run() {
+	defer close(l.done)
+
+	var tick <-chan time.Time
+	if l.flushInterval > 0 {
+		ticker := time.NewTicker(l.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.flushNow:
+		case <-tick:
+		}
+		l.Flush()
+	}
+}
+
+// Flush writes all buffered records to S3. Records from a failed write are kept for the next flush,
+// dropping the oldest beyond maxPendingRecords.
+func (l *S3Logger) Flush() {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	l.mu.Lock()
+	records := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if len(records) == 0 {
+		return
+	}
+
+	var err error
+	if l.Format == LogFormatJSONL {
+		err = l.writeJSONL(records)
+	} else {
+		err = l.writeCSV(records)
+	}
+	if err == nil {
+		log.Printf("Successfully wrote %d log records to S3", len(records))
+		return
+	}
+
+	log.Printf("Failed to write %d log records to S3: %v. Keeping them until the next flush.", len(records), err)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(records, l.pending...)
+	if dropped := len(l.pending) - maxPendingRecords; dropped > 0 {
+		log.Printf("Log buffer full. Dropping %d oldest records.", dropped)
+		l.pending = l.pending[dropped:]
+	}
+}
+
+// writeCSV appends the records to the CSV log. Any failure to read the existing CSV other than a missing
+// object aborts the write, since uploading without the existing rows would truncate the log.
+func (l *S3Logger) writeCSV(records []LogRecord) error {
+	existingData, err := l.readCSV()
+	if err != nil {
+		return fmt.Errorf("failed to read existing CSV: %w", err)
+	}
+
+	// If the CSV is empty, add headers
+	if len(existingData) == 0 {
+		existingData = append(existingData, csvHeaders)
+	} else if header := existingData[0]; len(header) < len(csvHeaders) {
+		// Logs written before the later columns were added gain them in the header; older rows leave them blank
+		existingData[0] = append(header, csvHeaders[len(header):]...)
+	}
+	for _, record := range records {
+		existingData = append(existingData, record.csvRow())
+	}
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(existingData); err != nil {
+		return fmt.Errorf("failed to write CSV data to buffer: %w", err)
+	}
+
+	_, err = l.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(l.bucket),
+		Key:    aws.String(l.keyPrefix + csvLogKey),
+		Body:   bytes.NewReader(buf.Bytes()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload updated CSV: %w", err)
+	}
+	return nil
+}
+
+// readCSV downloads and parses the CSV log. A missing object yields no rows and no error.
+func (l *S3Logger) readCSV() ([][]string, error) {
+	key := l.keyPrefix + csvLogKey
+	resp, err := l.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(l.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			log.Printf("Log CSV %s does not exist yet. A new CSV will be created.", key)
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV body: %w", err)
+	}
+	if len(bodyBytes) == 0 {
+		return nil, nil
+	}
+	reader := csv.NewReader(bytes.NewReader(bodyBytes))
+	reader.FieldsPerRecord = -1 // Rows written before the model column have one field fewer
+	existingData, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse existing CSV: %w", err)
+	}
+	return existingData, nil
+}
+
+// writeJSONL writes the records as JSON lines to a new object under logs/interactions-YYYY-MM-DD/.
+// Nothing is downloaded or rewritten, so writes never conflict.
+func (l *S3Logger) writeJSONL(records []LogRecord) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to marshal log record: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%slogs/interactions-%s/%d.jsonl", l.keyPrefix, now.Format("2006-01-02"), now.UnixNano())
+	_, err := l.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(l.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(buf.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// internal/s3/s3_logger_test.go
+
+package s3client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// memoryS3 is an in-memory S3 bucket whose reads can be made to fail.
+type memoryS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	getErr  error
+	puts    int
+}
+
+func newMemoryS3() *memoryS3 {
+	return &memoryS3{objects: make(map[string][]byte)}
+}
+
+func (m *memoryS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	data, ok := m.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *memoryS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.objects[aws.StringValue(input.Key)] = data
+	m.puts++
+	return &s3.PutObjectOutput{}, nil
+}
+
+// keys returns the keys of the stored objects.
+func (m *memoryS3) keys() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var keys []string
+	for key := range m.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestCSVReadErrorDoesNotTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		getErr   error
+		wantPuts int
+	}{
+		{"read failure skips the upload", errors.New("connection reset"), 0},
+		{"access denied skips the upload", awserr.New("AccessDenied", "denied", nil), 0},
+		{"missing log is created", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			store.getErr = tt.getErr
+			logger := NewS3Logger(store, "bucket", "", 100, 0)
+			logger.Enqueue(LogRecord{UserID: 1, Prompt: "Best bait?"})
+			logger.Flush()
+
+			if store.puts != tt.wantPuts {
+				t.Fatalf("made %d PUTs, want %d", store.puts, tt.wantPuts)
+			}
+			logger.mu.Lock()
+			pending := len(logger.pending)
+			logger.mu.Unlock()
+			if wantPending := 1 - tt.wantPuts; pending != wantPending {
+				t.Errorf("%d records pending, want %d", pending, wantPending)
+			}
+
+			// Once reads work again, the kept record is written
+			store.getErr = nil
+			logger.Close()
+			if data := store.objects[csvLogKey]; !strings.Contains(string(data), "Best bait?") {
+				t.Errorf("record missing from the log: %q", data)
+			}
+		})
+	}
+}
+
+func TestLogKeysArePrefixed(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		prefix     string
+		wantPrefix string
+	}{
+		{"csv without instance", LogFormatCSV, "", "logs/telegram_logs.csv"},
+		{"csv with instance", LogFormatCSV, "bot-a:", "bot-a:logs/telegram_logs.csv"},
+		{"jsonl without instance", LogFormatJSONL, "", "logs/interactions-"},
+		{"jsonl with instance", LogFormatJSONL, "bot-a:", "bot-a:logs/interactions-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			logger := NewS3Logger(store, "bucket", tt.prefix, 100, 0)
+			logger.Format = tt.format
+			logger.Enqueue(LogRecord{UserID: 1})
+			logger.Close()
+
+			keys := store.keys()
+			if len(keys) != 1 || !strings.HasPrefix(keys[0], tt.wantPrefix) {
+				t.Errorf("wrote %v, want one key starting with %s", keys, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestModelIsLogged(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // CSV already in the bucket
+		want     string
+	}{
+		{"new log", "", "userID,username,prompt,keywords,keyword_summary,categories,response_time,is_rate_limited,model,species,location,technique,gear\n" +
+			"7,angler,Best bait?,,,,1s,Rate limited: false,cheap-model,,,,\n"},
+		{"log written before the model column", "userID,username,prompt,keywords,keyword_summary,categories,response_time,is_rate_limited\n1,old,Hi,,,,2s,Rate limited: false\n",
+			"userID,username,prompt,keywords,keyword_summary,categories,response_time,is_rate_limited,model,species,location,technique,gear\n" +
+				"1,old,Hi,,,,2s,Rate limited: false\n" +
+				"7,angler,Best bait?,,,,1s,Rate limited: false,cheap-model,,,,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			if tt.existing != "" {
+				store.objects[csvLogKey] = []byte(tt.existing)
+			}
+			logger := NewS3Logger(store, "bucket", "", 100, 0)
+			logger.Enqueue(LogRecord{UserID: 7, Username: "angler", Prompt: "Best bait?", ResponseTime: "1s", Model: "cheap-model"})
+			logger.Close()
+
+			if got := string(store.objects[csvLogKey]); got != tt.want {
+				t.Errorf("CSV log =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLRecordShape(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		record LogRecord
+		want   string
+	}{
+		{"all columns", LogRecord{
+			Time: at, UserID: 7, Username: "angler", Prompt: "Best bait for stripers?", Keywords: "bait stripers",
+			KeywordSummary: "bait, stripers", Categories: "Bait/Lures/Fly Selection", ResponseTime: "1.2s",
+			IsRateLimited: false, Model: "gpt-4o-mini", Species: "striped bass", Location: "chesapeake bay",
+			Technique: "trolling", Gear: "planer board",
+		}, `{"time":"2026-10-16T12:30:00Z","user_id":7,"username":"angler","prompt":"Best bait for stripers?",` +
+			`"keywords":"bait stripers","keyword_summary":"bait, stripers","categories":"Bait/Lures/Fly Selection",` +
+			`"response_time":"1.2s","is_rate_limited":false,"model":"gpt-4o-mini","species":"striped bass",` +
+			`"location":"chesapeake bay","technique":"trolling","gear":"planer board"}`},
+		{"entities omitted when empty", LogRecord{Time: at, UserID: 8, Username: "other", Prompt: "Hi", IsRateLimited: true},
+			`{"time":"2026-10-16T12:30:00Z","user_id":8,"username":"other","prompt":"Hi","keywords":"",` +
+				`"keyword_summary":"","categories":"","response_time":"","is_rate_limited":true,"model":""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			logger := NewS3Logger(store, "bucket", "", 100, 0)
+			logger.Format = LogFormatJSONL
+			logger.Enqueue(tt.record)
+			logger.Close()
+
+			keys := store.keys()
+			if len(keys) != 1 || !strings.HasPrefix(keys[0], "logs/interactions-"+time.Now().UTC().Format("2006-01-02")+"/") ||
+				!strings.HasSuffix(keys[0], ".jsonl") {
+				t.Fatalf("wrote %v, want one object under today's interactions prefix", keys)
+			}
+			if got := string(store.objects[keys[0]]); got != tt.want+"\n" {
+				t.Errorf("record =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLWritesOneLinePerRecord(t *testing.T) {
+	store := newMemoryS3()
+	logger := NewS3Logger(store, "bucket", "", 100, 0)
+	logger.Format = LogFormatJSONL
+	for i := 0; i < 3; i++ {
+		logger.Enqueue(LogRecord{UserID: i, Prompt: "line\nbreak"})
+	}
+	logger.Close()
+
+	keys := store.keys()
+	if len(keys) != 1 {
+		t.Fatalf("wrote %d objects, want 1", len(keys))
+	}
+	lines := strings.Split(strings.TrimSuffix(string(store.objects[keys[0]]), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("object has %d lines, want 3", len(lines))
+	}
+	for i, line := range lines {
+		var record LogRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil || record.UserID != i || record.Prompt != "line\nbreak" {
+			t.Errorf("line %d = %q (%v), want user %d's record", i, line, err, i)
+		}
+	}
+	if _, err := store.GetObject(&s3.GetObjectInput{Key: aws.String(csvLogKey)}); err == nil {
+		t.Error("the CSV log was written in JSONL mode")
+	}
+}
+
+// putCount returns the number of PUTs made so far.
+func (m *memoryS3) putCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.puts
+}
+
+// waitForPuts polls until the store has received want PUTs, failing the test after a second.
+func waitForPuts(t *testing.T, store *memoryS3, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for store.putCount() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("made %d PUTs, want %d", store.putCount(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLoggerBatching(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		flushInterval time.Duration
+		records       int
+		wantPuts      int // PUTs expected without waiting for the interval
+	}{
+		{"below the batch size waits", 3, 0, 2, 0},
+		{"full batch is flushed", 3, 0, 3, 1},
+		{"interval flushes a partial batch", 100, 20 * time.Millisecond, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			logger := NewS3Logger(store, "bucket", "", tt.batchSize, tt.flushInterval)
+			defer logger.Close()
+
+			for i := 0; i < tt.records; i++ {
+				logger.Enqueue(LogRecord{UserID: i})
+			}
+			if tt.wantPuts > 0 {
+				waitForPuts(t, store, tt.wantPuts)
+			} else {
+				time.Sleep(50 * time.Millisecond)
+			}
+			if got := store.putCount(); got != tt.wantPuts {
+				t.Errorf("made %d PUTs, want %d", got, tt.wantPuts)
+			}
+		})
+	}
+}
+
+func TestCloseFlushesBufferedRecords(t *testing.T) {
+	store := newMemoryS3()
+	logger := NewS3Logger(store, "bucket", "", 100, time.Hour)
+	for i := 0; i < 5; i++ {
+		logger.Enqueue(LogRecord{UserID: i, Prompt: "question"})
+	}
+	if got := store.putCount(); got != 0 {
+		t.Fatalf("made %d PUTs before Close, want 0", got)
+	}
+
+	logger.Close()
+	logger.Close() // Safe to call again
+
+	if got := store.putCount(); got != 1 {
+		t.Errorf("made %d PUTs, want one for the whole batch", got)
+	}
+	if rows := strings.Count(string(store.objects[csvLogKey]), "question"); rows != 5 {
+		t.Errorf("log holds %d records, want 5", rows)
+	}
+}
+
+func TestConcurrentEnqueue(t *testing.T) {
+	store := newMemoryS3()
+	logger := NewS3Logger(store, "bucket", "", 7, 0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				logger.Enqueue(LogRecord{UserID: g*10 + i, Prompt: "question"})
+			}
+		}(g)
+	}
+	wg.Wait()
+	logger.Close()
+
+	if rows := strings.Count(string(store.objects[csvLogKey]), "question"); rows != 100 {
+		t.Errorf("log holds %d records, want all 100", rows)
+	}
+}
@@ -5,6 +5,7 @@ package secrets
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // GetSecret retrieves a secret from environment variables.
@@ -15,3 +16,18 @@ func GetSecret(key string) (string, error) {
 	}
 	return secret, nil
 }
+
+// RequireSecrets checks that every key is set, returning one error naming all missing keys so a
+// misconfigured deployment is reported in full rather than one variable at a time.
+func RequireSecrets(keys ...string) error {
+	var missing []string
+	for _, key := range keys {
+		if _, err := GetSecret(key); err != nil {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// internal/secrets/secrets_manager_test.go
+
+package secrets
+
+import "testing"
+
+func TestRequireSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"all set", map[string]string{"TELEGRAM_TOKEN": "t", "OPENAI_KEY": "k"}, ""},
+		{"one missing", map[string]string{"TELEGRAM_TOKEN": "t", "OPENAI_KEY": ""}, "required environment variables not set: OPENAI_KEY"},
+		{"all missing are named", map[string]string{"TELEGRAM_TOKEN": "", "OPENAI_KEY": ""}, "required environment variables not set: TELEGRAM_TOKEN, OPENAI_KEY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			err := RequireSecrets("TELEGRAM_TOKEN", "OPENAI_KEY")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("RequireSecrets error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("RequireSecrets error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	t.Setenv("OPENAI_KEY", "sk-test")
+	if got, err := GetSecret("OPENAI_KEY"); err != nil || got != "sk-test" {
+		t.Errorf("GetSecret = (%q, %v), want (\"sk-test\", nil)", got, err)
+	}
+	t.Setenv("OPENAI_KEY", "")
+	if _, err := GetSecret("OPENAI_KEY"); err == nil {
+		t.Error("GetSecret of an empty variable succeeded")
+	}
+}
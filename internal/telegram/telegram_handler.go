@@ -3,8 +3,11 @@
 package telegram
 
 import (
+	"context"
 	"log"
+	"regexp"
 	"strings"
+	"sync"
 
 	"ReelTalkBot-Go/internal/handlers"
 	"ReelTalkBot-Go/internal/types"
@@ -12,7 +15,11 @@ import (
 
 // TelegramHandler processes Telegram messages using a MessageProcessor interface.
 type TelegramHandler struct {
-	Processor handlers.MessageProcessor
+	Processor    handlers.MessageProcessor
+	NameFallback bool // Use the sender's first and last name when they have no username
+
+	mentionOnce    sync.Once
+	mentionPattern *regexp.Regexp // Matches mentions of the bot; compiled on first use
 }
 
 // NewTelegramHandler initializes a new TelegramHandler with the provided MessageProcessor.
@@ -23,7 +30,8 @@ func NewTelegramHandler(processor handlers.MessageProcessor) *TelegramHandler {
 }
 
 // HandleTelegramMessage processes incoming Telegram messages and queries OpenAI or Knowledge Base.
-func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (string, error) {
+// ctx bounds answering the message and is passed on to the processor.
+func (th *TelegramHandler) HandleTelegramMessage(ctx context.Context, update *types.TelegramUpdate) (string, error) {
 	var message *types.TelegramMessage
 
 	// Determine the type of message received
@@ -33,6 +41,16 @@ func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (
 		message = update.EditedMessage
 	} else if update.ChannelPost != nil {
 		message = update.ChannelPost
+	} else if update.BusinessMessage != nil || update.EditedBusinessMessage != nil {
+		message = update.BusinessMessage
+		if message == nil {
+			message = update.EditedBusinessMessage
+		}
+		// In a business chat the chat ID is the customer's ID; skip messages the business owner sends
+		if int64(message.From.ID) != message.Chat.ID {
+			log.Printf("Ignoring outgoing business message in chat %d", message.Chat.ID)
+			return "", nil
+		}
 	} else if update.CallbackQuery != nil {
 		// Handle callback queries separately if needed
 		log.Printf("Received callback query: %+v", update.CallbackQuery)
@@ -43,7 +61,7 @@ func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (
 	}
 
 	// Validate message structure
-	if message.Chat.ID == 0 || message.Text == "" {
+	if message.Chat.ID == 0 || (message.Text == "" && message.Voice == nil) {
 		log.Println("Invalid message structure: missing chat ID or text.")
 		return "", nil // Return empty string to avoid sending a message
 	}
@@ -54,13 +72,16 @@ func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (
 	messageID := message.MessageID
 	userID := message.From.ID
 	username := message.From.Username
+	if username == "" && th.NameFallback {
+		username = message.From.FullName()
+	}
 
 	log.Printf("Received message from user %d (%s) in chat %d: %s", userID, username, chatID, userQuestion)
 
 	// Check if the message is a command (starts with "/")
 	if strings.HasPrefix(message.Text, "/") {
 		log.Printf("Message is a command: %s", message.Text)
-		_, err := th.Processor.HandleCommand(message, userID, username)
+		_, err := th.Processor.HandleCommand(ctx, message, userID, username)
 		if err != nil {
 			log.Printf("Error handling command: %v", err)
 			return "", nil // Return empty string to avoid sending a message
@@ -87,7 +108,7 @@ func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (
 				log.Printf("Detected mention: %s", mention)
 				if isTaggedMention(mention, th.Processor.GetBotUsername()) {
 					isTagged = true
-					userQuestion = removeMention(userQuestion, mention)
+					userQuestion = stripBotMentions(userQuestion, th.botMentionPattern())
 					log.Printf("Message is tagged with bot username: %s", th.Processor.GetBotUsername())
 					break
 				}
@@ -101,10 +122,32 @@ func (th *TelegramHandler) HandleTelegramMessage(update *types.TelegramUpdate) (
 		return "", nil // Return empty string to avoid sending a message
 	}
 
+	// Voice messages are transcribed and answered like typed questions
+	if message.Text == "" && message.Voice != nil {
+		transcript, err := th.Processor.TranscribeVoice(ctx, message)
+		if err != nil {
+			log.Printf("Error transcribing voice message: %v", err)
+		}
+		if transcript == "" {
+			return "", nil // Return empty string to avoid sending a message
+		}
+		userQuestion = transcript
+	}
+
 	log.Printf("Processing message in chat %d: %s", chatID, userQuestion)
 
+	// Keep any passage the user quoted so the answer can be attached to it
+	meta := types.MessageMeta{
+		FirstName: message.From.FirstName,
+		Edited:    update.EditedMessage != nil || update.EditedBusinessMessage != nil,
+	}
+	if isReply && message.Quote != nil {
+		meta.Quote = message.Quote
+		meta.QuotedMessageID = message.ReplyToMessage.MessageID
+	}
+
 	// Process the message: Query Knowledge Base or fallback to OpenAI
-	if err := th.Processor.ProcessMessage(chatID, userID, username, userQuestion, messageID); err != nil {
+	if err := th.Processor.ProcessMessage(ctx, chatID, userID, username, userQuestion, messageID, meta); err != nil {
 		log.Printf("Error processing message: %v", err)
 		return "", nil // Return empty string to avoid sending a message
 	}
@@ -117,7 +160,31 @@ func isTaggedMention(mention, botUsername string) bool {
 	return strings.ToLower(mention) == "@"+strings.ToLower(botUsername)
 }
 
-// removeMention removes the bot's mention from the message text.
-func removeMention(text, mention string) string {
-	return strings.TrimSpace(strings.Replace(text, mention, "", 1))
+// leadingPunctuation matches punctuation and whitespace left at the start of a message once mentions are removed.
+var leadingPunctuation = regexp.MustCompile(`^[\s,.:;!?\-–—]+`)
+
+// repeatedSpaces matches runs of spaces and tabs left behind by removed mentions.
+var repeatedSpaces = regexp.MustCompile(`[ \t]{2,}`)
+
+// botMentionPattern returns the pattern matching mentions of the bot in any letter case. The bot username
+// is fixed at startup, so the pattern is compiled once rather than for every message.
+func (th *TelegramHandler) botMentionPattern() *regexp.Regexp {
+	th.mentionOnce.Do(func() {
+		th.mentionPattern = mentionPatternFor(th.Processor.GetBotUsername())
+	})
+	return th.mentionPattern
+}
+
+// mentionPatternFor builds the pattern matching "@" followed by botUsername in any letter case.
+func mentionPatternFor(botUsername string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(botUsername) + `\b`)
+}
+
+// stripBotMentions removes every match of mentionPattern from the message text,
+// along with leading punctuation such as "@ReelTalkBot, hey" leaves behind.
+func stripBotMentions(text string, mentionPattern *regexp.Regexp) string {
+	text = mentionPattern.ReplaceAllString(text, "")
+	text = repeatedSpaces.ReplaceAllString(text, " ")
+	text = leadingPunctuation.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
 }
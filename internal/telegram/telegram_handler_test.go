@@ -0,0 +1,168 @@
+// internal/telegram/telegram_handler_test.go
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// fakeProcessor records the questions and usernames passed to ProcessMessage.
+type fakeProcessor struct {
+	botUsername   string
+	questions     []string
+	usernames     []string
+	transcript    string // Returned by TranscribeVoice
+	transcribeErr error
+}
+
+func (f *fakeProcessor) ProcessMessage(ctx context.Context, chatID int64, userID int, username string, userQuestion string, messageID int, meta types.MessageMeta) error {
+	f.questions = append(f.questions, userQuestion)
+	f.usernames = append(f.usernames, username)
+	return nil
+}
+
+func (f *fakeProcessor) HandleCommand(ctx context.Context, message *types.TelegramMessage, userID int, username string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeProcessor) SendMessage(chatID int64, text string, replyToMessageID int) error {
+	return nil
+}
+
+func (f *fakeProcessor) SendMessageWithKeyboard(chatID int64, text string, replyToMessageID int, keyboard string) error {
+	return nil
+}
+
+func (f *fakeProcessor) GetBotUsername() string { return f.botUsername }
+
+func (f *fakeProcessor) TranscribeVoice(ctx context.Context, message *types.TelegramMessage) (string, error) {
+	return f.transcript, f.transcribeErr
+}
+
+func TestStripBotMentions(t *testing.T) {
+	pattern := mentionPatternFor("ReelTalkBot")
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"leading comma", "@ReelTalkBot, best bait for bass?", "best bait for bass?"},
+		{"double mention", "@ReelTalkBot @reeltalkbot best bait for bass?", "best bait for bass?"},
+		{"mid-sentence mention", "What do you think @ReelTalkBot about spinnerbaits?", "What do you think about spinnerbaits?"},
+		{"trailing mention", "Best bait for bass? @ReelTalkBot", "Best bait for bass?"},
+		{"longer username kept", "@ReelTalkBotFan says hi", "@ReelTalkBotFan says hi"},
+		{"only a mention", "@ReelTalkBot!", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripBotMentions(tt.text, pattern); got != tt.want {
+				t.Errorf("stripBotMentions(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaggedGroupMessagesAreStripped(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"leading comma", "@ReelTalkBot, best bait for bass?", "best bait for bass?"},
+		{"double mention", "@ReelTalkBot @ReelTalkBot best bait for bass?", "best bait for bass?"},
+	}
+	processor := &fakeProcessor{botUsername: "ReelTalkBot"}
+	handler := NewTelegramHandler(processor)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor.questions = nil
+			update := &types.TelegramUpdate{Message: &types.TelegramMessage{
+				MessageID: 10,
+				Text:      tt.text,
+				Chat:      types.TelegramChat{ID: -100, Type: "group"},
+				From:      types.TelegramUser{ID: 7},
+				Entities:  []types.TelegramEntity{{Type: "mention", Offset: 0, Length: len("@ReelTalkBot")}},
+			}}
+
+			if _, err := handler.HandleTelegramMessage(context.Background(), update); err != nil {
+				t.Fatalf("HandleTelegramMessage() error = %v", err)
+			}
+			if len(processor.questions) != 1 || processor.questions[0] != tt.want {
+				t.Errorf("processed %q, want [%q]", processor.questions, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsernameFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     types.TelegramUser
+		fallback bool
+		want     string
+	}{
+		{"username kept", types.TelegramUser{ID: 7, Username: "angler", FirstName: "Jane"}, true, "angler"},
+		{"first and last name", types.TelegramUser{ID: 7, FirstName: "Jane", LastName: "Doe"}, true, "Jane Doe"},
+		{"first name only", types.TelegramUser{ID: 7, FirstName: "Jane"}, true, "Jane"},
+		{"fallback disabled", types.TelegramUser{ID: 7, FirstName: "Jane", LastName: "Doe"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &fakeProcessor{botUsername: "ReelTalkBot"}
+			handler := NewTelegramHandler(processor)
+			handler.NameFallback = tt.fallback
+			update := &types.TelegramUpdate{Message: &types.TelegramMessage{
+				MessageID: 10,
+				Text:      "Best bait for bass?",
+				Chat:      types.TelegramChat{ID: 7, Type: "private"},
+				From:      tt.from,
+			}}
+
+			if _, err := handler.HandleTelegramMessage(context.Background(), update); err != nil {
+				t.Fatalf("HandleTelegramMessage() error = %v", err)
+			}
+			if len(processor.usernames) != 1 || processor.usernames[0] != tt.want {
+				t.Errorf("processed as %q, want [%q]", processor.usernames, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoiceMessagesAreAnsweredFromTheirTranscript(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		transcript    string
+		transcribeErr error
+		want          []string
+	}{
+		{"transcribed", "", "Best bait for bass?", nil, []string{"Best bait for bass?"}},
+		{"empty transcript is ignored", "", "", nil, nil},
+		{"transcription failure is ignored", "", "", errors.New("whisper unavailable"), nil},
+		{"caption text wins over the voice note", "Best lure for pike?", "Best bait for bass?", nil, []string{"Best lure for pike?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &fakeProcessor{botUsername: "ReelTalkBot", transcript: tt.transcript, transcribeErr: tt.transcribeErr}
+			handler := NewTelegramHandler(processor)
+			update := &types.TelegramUpdate{Message: &types.TelegramMessage{
+				MessageID: 10,
+				Text:      tt.text,
+				Chat:      types.TelegramChat{ID: 7, Type: "private"},
+				From:      types.TelegramUser{ID: 7, Username: "angler"},
+				Voice:     &types.TelegramVoice{FileID: "voice-1", Duration: 4},
+			}}
+
+			if _, err := handler.HandleTelegramMessage(context.Background(), update); err != nil {
+				t.Fatalf("HandleTelegramMessage() error = %v", err)
+			}
+			if len(processor.questions) != len(tt.want) || (len(tt.want) > 0 && processor.questions[0] != tt.want[0]) {
+				t.Errorf("processed %q, want %q", processor.questions, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// internal/types/errors.go
+
+package types
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is a non-success HTTP response from an upstream service such as OpenAI or the Knowledge Base.
+// Callers can use errors.As to branch on the status code.
+type APIError struct {
+	Service    string // Upstream that answered, e.g. "OpenAI"
+	StatusCode int
+	Body       string // Response body, which usually explains the failure
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s returned status %d", e.Service, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returned status %d: %s", e.Service, e.StatusCode, e.Body)
+}
+
+// RateLimited reports whether the upstream rejected the request for being over its rate limit or quota.
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Unauthorized reports whether the upstream rejected the request's credentials.
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
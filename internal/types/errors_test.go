@@ -0,0 +1,44 @@
+// internal/types/errors_test.go
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              *APIError
+		wantMessage      string
+		wantRateLimited  bool
+		wantUnauthorized bool
+	}{
+		{"rate limited", &APIError{Service: "OpenAI", StatusCode: http.StatusTooManyRequests, Body: "slow down"}, "OpenAI returned status 429: slow down", true, false},
+		{"unauthorized", &APIError{Service: "OpenAI", StatusCode: http.StatusUnauthorized}, "OpenAI returned status 401", false, true},
+		{"forbidden", &APIError{Service: "Knowledge Base", StatusCode: http.StatusForbidden}, "Knowledge Base returned status 403", false, true},
+		{"server error", &APIError{Service: "OpenAI", StatusCode: http.StatusBadGateway, Body: "upstream"}, "OpenAI returned status 502: upstream", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.wantMessage {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMessage)
+			}
+			if got := tt.err.RateLimited(); got != tt.wantRateLimited {
+				t.Errorf("RateLimited() = %v, want %v", got, tt.wantRateLimited)
+			}
+			if got := tt.err.Unauthorized(); got != tt.wantUnauthorized {
+				t.Errorf("Unauthorized() = %v, want %v", got, tt.wantUnauthorized)
+			}
+
+			// Callers find the status through wrapping
+			var apiErr *APIError
+			if wrapped := fmt.Errorf("query failed: %w", tt.err); !errors.As(wrapped, &apiErr) || apiErr.StatusCode != tt.err.StatusCode {
+				t.Errorf("errors.As did not find the APIError through wrapping")
+			}
+		})
+	}
+}
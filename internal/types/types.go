@@ -2,24 +2,64 @@
 
 package types
 
+import "strings"
+
 // TelegramUpdate represents an incoming update from Telegram.
 type TelegramUpdate struct {
-	UpdateID      int                    `json:"update_id"`
-	Message       *TelegramMessage       `json:"message,omitempty"`
-	EditedMessage *TelegramMessage       `json:"edited_message,omitempty"`
-	ChannelPost   *TelegramMessage       `json:"channel_post,omitempty"`
-	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+	UpdateID              int                         `json:"update_id"`
+	Message               *TelegramMessage            `json:"message,omitempty"`
+	EditedMessage         *TelegramMessage            `json:"edited_message,omitempty"`
+	ChannelPost           *TelegramMessage            `json:"channel_post,omitempty"`
+	CallbackQuery         *TelegramCallbackQuery      `json:"callback_query,omitempty"`
+	BusinessConnection    *TelegramBusinessConnection `json:"business_connection,omitempty"`
+	BusinessMessage       *TelegramMessage            `json:"business_message,omitempty"`
+	EditedBusinessMessage *TelegramMessage            `json:"edited_business_message,omitempty"`
 }
 
 // TelegramMessage represents a message in Telegram.
 type TelegramMessage struct {
-	MessageID      int              `json:"message_id"`
-	From           TelegramUser     `json:"from"`
-	Chat           TelegramChat     `json:"chat"`
-	Date           int              `json:"date"`
-	Text           string           `json:"text"`
-	Entities       []TelegramEntity `json:"entities,omitempty"`
-	ReplyToMessage *TelegramMessage `json:"reply_to_message,omitempty"`
+	MessageID            int                `json:"message_id"`
+	From                 TelegramUser       `json:"from"`
+	Chat                 TelegramChat       `json:"chat"`
+	Date                 int                `json:"date"`
+	Text                 string             `json:"text"`
+	Voice                *TelegramVoice     `json:"voice,omitempty"`
+	Entities             []TelegramEntity   `json:"entities,omitempty"`
+	ReplyToMessage       *TelegramMessage   `json:"reply_to_message,omitempty"`
+	Quote                *TelegramTextQuote `json:"quote,omitempty"`
+	BusinessConnectionID string             `json:"business_connection_id,omitempty"`
+}
+
+// TelegramVoice is a voice note recorded in Telegram.
+type TelegramVoice struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int    `json:"duration"` // Length in seconds
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int    `json:"file_size,omitempty"`
+}
+
+// TelegramTextQuote is the part of the replied-to message that a user quoted in their reply.
+type TelegramTextQuote struct {
+	Text     string `json:"text"`
+	Position int    `json:"position"` // Offset in UTF-16 code units within the quoted message
+	IsManual bool   `json:"is_manual,omitempty"`
+}
+
+// TelegramReplyParameters describes the message being replied to, optionally quoting part of it.
+type TelegramReplyParameters struct {
+	MessageID                int    `json:"message_id"`
+	Quote                    string `json:"quote,omitempty"`
+	QuotePosition            int    `json:"quote_position,omitempty"`
+	AllowSendingWithoutReply bool   `json:"allow_sending_without_reply,omitempty"`
+}
+
+// MessageMeta carries optional context about an incoming message beyond its text.
+type MessageMeta struct {
+	Quote           *TelegramTextQuote // Passage the user quoted from the message they replied to
+	QuotedMessageID int                // ID of the message the quote was taken from
+	FirstName       string             // Sender's first name, used to personalize answers when enabled
+	Edited          bool               // The message is an edit of one the user sent earlier
 }
 
 // TelegramCallbackQuery represents a callback query from an inline keyboard.
@@ -31,15 +71,31 @@ type TelegramCallbackQuery struct {
 	// You can include other fields as needed based on Telegram's API.
 }
 
+// TelegramBusinessConnection represents a connection between the bot and a Telegram business account.
+type TelegramBusinessConnection struct {
+	ID         string       `json:"id"`
+	User       TelegramUser `json:"user"`
+	UserChatID int64        `json:"user_chat_id"`
+	Date       int          `json:"date"`
+	CanReply   bool         `json:"can_reply"`
+	IsEnabled  bool         `json:"is_enabled"`
+}
+
 // TelegramUser represents a user in Telegram.
 type TelegramUser struct {
 	ID           int    `json:"id"`
 	IsBot        bool   `json:"is_bot"`
 	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
 	Username     string `json:"username,omitempty"`
 	LanguageCode string `json:"language_code,omitempty"`
 }
 
+// FullName returns the user's first and last name.
+func (u TelegramUser) FullName() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
 // TelegramChat represents a chat in Telegram.
 type TelegramChat struct {
 	ID                          int64  `json:"id"`
@@ -81,10 +137,28 @@ type OpenAIMessage struct {
 
 // OpenAIQuery represents the payload sent to OpenAI's API.
 type OpenAIQuery struct {
-	Model       string          `json:"model"`
-	Messages    []OpenAIMessage `json:"messages"`
-	Temperature float64         `json:"temperature"`
-	MaxTokens   int             `json:"max_tokens"`
+	Model         string               `json:"model"`
+	Messages      []OpenAIMessage      `json:"messages"`
+	Temperature   float64              `json:"temperature"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"` // Asks for token usage in the final chunk
+}
+
+// OpenAIStreamOptions configures a streamed OpenAI response.
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// OpenAIStreamChunk is a single server-sent event of a streamed OpenAI response.
+type OpenAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *OpenAIUsage `json:"usage,omitempty"` // Only set on the final chunk
 }
 
 // OpenAIResponse represents the response received from OpenAI's API.
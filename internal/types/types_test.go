@@ -0,0 +1,58 @@
+// internal/types/types_test.go
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFullName(t *testing.T) {
+	tests := []struct {
+		name string
+		user TelegramUser
+		want string
+	}{
+		{"first and last", TelegramUser{FirstName: "Jane", LastName: "Doe"}, "Jane Doe"},
+		{"first only", TelegramUser{FirstName: "Jane"}, "Jane"},
+		{"last only", TelegramUser{LastName: "Doe"}, "Doe"},
+		{"no name", TelegramUser{Username: "angler"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.FullName(); got != tt.want {
+				t.Errorf("FullName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoiceMessageDecoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		update    string
+		wantVoice *TelegramVoice
+	}{
+		{
+			name:      "voice note",
+			update:    `{"update_id":1,"message":{"message_id":10,"chat":{"id":7},"voice":{"file_id":"abc","file_unique_id":"u1","duration":4,"mime_type":"audio/ogg","file_size":2048}}}`,
+			wantVoice: &TelegramVoice{FileID: "abc", FileUniqueID: "u1", Duration: 4, MimeType: "audio/ogg", FileSize: 2048},
+		},
+		{
+			name:   "text message",
+			update: `{"update_id":1,"message":{"message_id":10,"chat":{"id":7},"text":"Best bait?"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update TelegramUpdate
+			if err := json.Unmarshal([]byte(tt.update), &update); err != nil {
+				t.Fatalf("Unmarshal error = %v", err)
+			}
+			voice := update.Message.Voice
+			if (voice == nil) != (tt.wantVoice == nil) || (voice != nil && *voice != *tt.wantVoice) {
+				t.Errorf("Voice = %+v, want %+v", voice, tt.wantVoice)
+			}
+		})
+	}
+}
@@ -3,46 +3,146 @@
 package usage
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
 
-// UsageCache tracks user message usage for rate limiting.
+// Default rate limit applied by NewDefaultUsageCache
+const (
+	DefaultLimit  = 10
+	DefaultWindow = 10 * time.Minute
+)
+
+// UsageCache tracks user and chat message usage for rate limiting.
 type UsageCache struct {
-	users    map[int][]time.Time
-	mutex    sync.Mutex
-	limit    int
-	duration time.Duration
+	users        map[int][]time.Time
+	chats        map[int64][]time.Time
+	mutex        sync.Mutex
+	limit        int
+	duration     time.Duration
+	chatLimit    int // 0 disables the per-chat limit
+	chatDuration time.Duration
+	store        *usageStore // Persists per-user usage to S3; nil keeps usage in memory only
 }
 
-// NewUsageCache initializes a new UsageCache.
-func NewUsageCache() *UsageCache {
+// NewUsageCache initializes a new UsageCache allowing limit messages per duration.
+func NewUsageCache(limit int, duration time.Duration) *UsageCache {
 	return &UsageCache{
 		users:    make(map[int][]time.Time),
-		limit:    10,               // Default limit of 10 messages per duration
-		duration: 10 * time.Minute, // 10-minute window
+		chats:    make(map[int64][]time.Time),
+		limit:    limit,
+		duration: duration,
 	}
 }
 
+// NewDefaultUsageCache initializes a UsageCache with the default limit of 10 messages per 10 minutes.
+func NewDefaultUsageCache() *UsageCache {
+	return NewUsageCache(DefaultLimit, DefaultWindow)
+}
+
+// EmptyCopy returns a UsageCache enforcing the same user and chat limits with no usage recorded.
+// The copy is kept in memory only, even when u is persisted to S3.
+func (u *UsageCache) EmptyCopy() *UsageCache {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	c := NewUsageCache(u.limit, u.duration)
+	c.chatLimit = u.chatLimit
+	c.chatDuration = u.chatDuration
+	return c
+}
+
+// SetChatLimit caps the messages a whole chat may send per duration. A limit of 0 disables the cap.
+func (u *UsageCache) SetChatLimit(limit int, duration time.Duration) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.chatLimit = limit
+	u.chatDuration = duration
+}
+
+// Describe returns the enforced per-user limit in words, e.g. "10 messages per 10 minutes".
+func (u *UsageCache) Describe() string {
+	return describeLimit(u.limit, u.duration)
+}
+
+// DescribeChatLimit returns the enforced per-chat limit in words, e.g. "50 messages per 10 minutes".
+func (u *UsageCache) DescribeChatLimit() string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return describeLimit(u.chatLimit, u.chatDuration)
+}
+
+// describeLimit renders a limit and window in words.
+func describeLimit(limit int, duration time.Duration) string {
+	return fmt.Sprintf("%s per %s", pluralize(limit, "message"), formatWindow(duration))
+}
+
+// formatWindow renders a duration in the largest whole unit that represents it exactly.
+func formatWindow(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return pluralize(int(d/time.Hour), "hour")
+	case d >= time.Minute && d%time.Minute == 0:
+		return pluralize(int(d/time.Minute), "minute")
+	default:
+		return pluralize(int(d.Round(time.Second)/time.Second), "second")
+	}
+}
+
+// pluralize formats a count with its unit, adding an "s" unless the count is one.
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
 // CanUserChat checks if a user is allowed to send a message based on usage in the last duration
 func (u *UsageCache) CanUserChat(userID int) bool {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
 	// Filter out old timestamps
-	validTimes := u.filterRecentMessages(userID)
+	validTimes := filterRecent(u.users[userID], u.duration)
 	u.users[userID] = validTimes
 
 	// Check if user has exceeded the limit
 	return len(validTimes) < u.limit
 }
 
+// CanChatProceed checks if a chat is below its shared limit. It always returns true when the chat limit is disabled.
+func (u *UsageCache) CanChatProceed(chatID int64) bool {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.chatLimit <= 0 {
+		return true
+	}
+	validTimes := filterRecent(u.chats[chatID], u.chatDuration)
+	u.chats[chatID] = validTimes
+	return len(validTimes) < u.chatLimit
+}
+
 // AddUsage records a new message usage for the user
 func (u *UsageCache) AddUsage(userID int) {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
 	u.users[userID] = append(u.users[userID], time.Now())
+	if u.store != nil {
+		u.store.dirty = true
+	}
+}
+
+// AddChatUsage records a new message usage for the chat
+func (u *UsageCache) AddChatUsage(chatID int64) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.chatLimit <= 0 {
+		return
+	}
+	u.chats[chatID] = append(u.chats[chatID], time.Now())
 }
 
 // TimeUntilLimitReset calculates the time remaining until the rate limit is lifted
@@ -50,26 +150,50 @@ func (u *UsageCache) TimeUntilLimitReset(userID int) time.Duration {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	validTimes := u.filterRecentMessages(userID)
-	if len(validTimes) < u.limit {
-		return 0 // No limit currently in place
+	return timeUntilReset(filterRecent(u.users[userID], u.duration), u.limit, u.duration)
+}
+
+// RemainingMessages returns how many more messages the user may send in the current window, and how long
+// until their oldest counted message leaves the window and frees a slot (0 when nothing is counted).
+func (u *UsageCache) RemainingMessages(userID int) (remaining int, resetIn time.Duration) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	validTimes := filterRecent(u.users[userID], u.duration)
+	remaining = u.limit - len(validTimes)
+	if remaining < 0 {
+		remaining = 0
+	}
+	if len(validTimes) > 0 {
+		resetIn = u.duration - time.Since(validTimes[0])
 	}
+	return remaining, resetIn
+}
 
-	// Calculate time remaining until the oldest timestamp falls outside the duration window
-	oldestTime := validTimes[0]
-	return u.duration - time.Since(oldestTime)
+// TimeUntilChatLimitReset calculates the time remaining until the chat's rate limit is lifted
+func (u *UsageCache) TimeUntilChatLimitReset(chatID int64) time.Duration {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.chatLimit <= 0 {
+		return 0
+	}
+	return timeUntilReset(filterRecent(u.chats[chatID], u.chatDuration), u.chatLimit, u.chatDuration)
 }
 
-// filterRecentMessages filters messages within the allowed duration
-func (u *UsageCache) filterRecentMessages(userID int) []time.Time {
-	if _, exists := u.users[userID]; !exists {
-		u.users[userID] = []time.Time{}
-		return u.users[userID]
+// timeUntilReset returns how long until the oldest timestamp falls outside the window, or 0 below the limit.
+func timeUntilReset(validTimes []time.Time, limit int, duration time.Duration) time.Duration {
+	if len(validTimes) < limit {
+		return 0 // No limit currently in place
 	}
+	return duration - time.Since(validTimes[0])
+}
 
+// filterRecent returns the timestamps within the allowed duration
+func filterRecent(times []time.Time, duration time.Duration) []time.Time {
 	validTimes := []time.Time{}
-	for _, t := range u.users[userID] {
-		if time.Since(t) <= u.duration {
+	for _, t := range times {
+		if time.Since(t) <= duration {
 			validTimes = append(validTimes, t)
 		}
 	}
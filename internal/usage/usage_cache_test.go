@@ -0,0 +1,141 @@
+// internal/usage/usage_cache_test.go
+
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		duration time.Duration
+		want     string
+	}{
+		{"default", DefaultLimit, DefaultWindow, "10 messages per 10 minutes"},
+		{"single message", 1, time.Hour, "1 message per 1 hour"},
+		{"hours", 50, 2 * time.Hour, "50 messages per 2 hours"},
+		{"mixed units fall back to minutes", 5, 90 * time.Minute, "5 messages per 90 minutes"},
+		{"seconds", 3, 30 * time.Second, "3 messages per 30 seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewUsageCache(tt.limit, tt.duration).Describe(); got != tt.want {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanUserChatWithNonDefaultLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		duration time.Duration
+		wait     time.Duration // Slept after using up the limit
+		wantOK   bool
+	}{
+		{"blocked within the window", 3, time.Hour, 0, false},
+		{"allowed once the window passes", 3, 20 * time.Millisecond, 40 * time.Millisecond, true},
+		{"single message limit", 1, time.Hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewUsageCache(tt.limit, tt.duration)
+			for i := 0; i < tt.limit; i++ {
+				if !u.CanUserChat(7) {
+					t.Fatalf("message %d blocked, want allowed", i+1)
+				}
+				u.AddUsage(7)
+			}
+			time.Sleep(tt.wait)
+			if got := u.CanUserChat(7); got != tt.wantOK {
+				t.Errorf("CanUserChat() after %d messages = %v, want %v", tt.limit, got, tt.wantOK)
+			}
+			if !u.CanUserChat(8) {
+				t.Error("another user was blocked")
+			}
+		})
+	}
+}
+
+func TestRemainingMessages(t *testing.T) {
+	u := NewUsageCache(3, time.Hour)
+	u.AddUsage(7)
+	remaining, resetIn := u.RemainingMessages(7)
+	if remaining != 2 {
+		t.Errorf("remaining = %d, want 2", remaining)
+	}
+	if resetIn <= 0 || resetIn > time.Hour {
+		t.Errorf("resetIn = %v, want within the hour window", resetIn)
+	}
+	if remaining, resetIn := u.RemainingMessages(8); remaining != 3 || resetIn != 0 {
+		t.Errorf("unused user has %d remaining resetting in %v, want 3 and 0", remaining, resetIn)
+	}
+}
+
+func TestCanChatProceed(t *testing.T) {
+	tests := []struct {
+		name      string
+		chatLimit int
+		messages  int // Messages recorded for the chat, spread over several users
+		want      bool
+	}{
+		{"disabled", 0, 100, true},
+		{"below the limit", 3, 2, true},
+		{"at the limit", 3, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewDefaultUsageCache()
+			u.SetChatLimit(tt.chatLimit, time.Hour)
+			for i := 0; i < tt.messages; i++ {
+				u.AddUsage(i)
+				u.AddChatUsage(-100)
+			}
+			if got := u.CanChatProceed(-100); got != tt.want {
+				t.Errorf("CanChatProceed() = %v, want %v", got, tt.want)
+			}
+			if !u.CanChatProceed(-200) {
+				t.Error("another chat was blocked")
+			}
+			if tt.chatLimit > 0 && tt.messages >= tt.chatLimit && u.TimeUntilChatLimitReset(-100) <= 0 {
+				t.Error("TimeUntilChatLimitReset() is not positive for a blocked chat")
+			}
+		})
+	}
+}
+
+func TestEmptyCopy(t *testing.T) {
+	original := NewUsageCache(2, time.Hour)
+	original.SetChatLimit(3, time.Hour)
+	original.AddUsage(7)
+	original.AddUsage(7)
+	original.AddChatUsage(1)
+	c := original.EmptyCopy()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"user limit", c.Describe(), original.Describe()},
+		{"chat limit", c.DescribeChatLimit(), original.DescribeChatLimit()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("copy enforces %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+	if !c.CanUserChat(7) {
+		t.Error("copy carried over the original's usage")
+	}
+	c.AddUsage(8)
+	if remaining, _ := original.RemainingMessages(8); remaining != 2 {
+		t.Errorf("usage added to the copy reached the original: %d messages left, want 2", remaining)
+	}
+}
@@ -0,0 +1,177 @@
+// internal/usage/usage_store.go
+
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	s3client "ReelTalkBot-Go/internal/s3"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// UsageStateKey is the S3 object holding the persisted per-user usage, before any bot instance prefix.
+const UsageStateKey = "state/usage.json"
+
+// DefaultSaveInterval is how often NewPersistentUsageCache writes changed usage to S3.
+const DefaultSaveInterval = time.Minute
+
+// usageState is the persisted form of the per-user usage.
+type usageState struct {
+	Users map[int][]time.Time `json:"users"`
+}
+
+// usageStore saves per-user usage to an S3 object so rate limits survive process restarts.
+type usageStore struct {
+	client s3client.S3ClientInterface
+	bucket string
+	key    string
+	dirty  bool // Usage changed since the last save; guarded by the UsageCache mutex
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPersistentUsageCache initializes a UsageCache like NewUsageCache, loading the usage saved under key in
+// the bucket and writing changes back every saveInterval. Timestamps already outside the window are dropped
+// on load. A failed load is logged and the cache starts empty.
+func NewPersistentUsageCache(limit int, duration time.Duration, client s3client.S3ClientInterface, bucket, key string, saveInterval time.Duration) *UsageCache {
+	u := NewUsageCache(limit, duration)
+	u.store = &usageStore{
+		client: client,
+		bucket: bucket,
+		key:    key,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if err := u.load(); err != nil {
+		log.Printf("Failed to load usage from S3, starting with empty usage: %v", err)
+	}
+
+	if saveInterval <= 0 {
+		saveInterval = DefaultSaveInterval
+	}
+	go u.runSaves(saveInterval)
+	return u
+}
+
+// load reads the saved usage, keeping only timestamps within the window. A missing object is not an error.
+func (u *UsageCache) load() error {
+	resp, err := u.store.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(u.store.bucket),
+		Key:    aws.String(u.store.key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", u.store.key, err)
+	}
+	var state usageState
+	if err := json.Unmarshal(body, &state); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", u.store.key, err)
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	for userID, times := range state.Users {
+		if recent := filterRecent(times, u.duration); len(recent) > 0 {
+			u.users[userID] = recent
+		}
+	}
+	log.Printf("Loaded usage for %d users from S3", len(u.users))
+	return nil
+}
+
+// Save writes the current per-user usage to S3, pruning timestamps outside the window. It does nothing
+// when the cache is not persistent or nothing changed since the last save.
+func (u *UsageCache) Save() error {
+	if u.store == nil {
+		return nil
+	}
+
+	u.mutex.Lock()
+	if !u.store.dirty {
+		u.mutex.Unlock()
+		return nil
+	}
+	state := usageState{Users: make(map[int][]time.Time, len(u.users))}
+	for userID, times := range u.users {
+		recent := filterRecent(times, u.duration)
+		if len(recent) == 0 {
+			delete(u.users, userID)
+			continue
+		}
+		u.users[userID] = recent
+		state.Users[userID] = recent
+	}
+	u.store.dirty = false
+	u.mutex.Unlock()
+
+	body, err := json.Marshal(state)
+	if err == nil {
+		_, err = u.store.client.PutObject(&s3.PutObjectInput{
+			Bucket:      aws.String(u.store.bucket),
+			Key:         aws.String(u.store.key),
+			Body:        bytes.NewReader(body),
+			ContentType: aws.String("application/json"),
+		})
+	}
+	if err != nil {
+		// Try again on the next save
+		u.mutex.Lock()
+		u.store.dirty = true
+		u.mutex.Unlock()
+		return fmt.Errorf("failed to save usage: %w", err)
+	}
+	return nil
+}
+
+// Close stops the periodic saves and writes any unsaved usage. It is safe to call more than once and
+// does nothing when the cache is not persistent.
+func (u *UsageCache) Close() {
+	if u.store == nil {
+		return
+	}
+	u.store.closeOnce.Do(func() {
+		close(u.store.stop)
+		<-u.store.done
+		if err := u.Save(); err != nil {
+			log.Printf("%v", err)
+		}
+	})
+}
+
+// runSaves saves changed usage every interval until Close is called.
+func (u *UsageCache) runSaves(interval time.Duration) {
+	defer close(u.store.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-u.store.stop:
+			return
+		case <-ticker.C:
+			if err := u.Save(); err != nil {
+				log.Printf("%v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// internal/usage/usage_store_test.go
+
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// memoryS3 is an in-memory S3 bucket.
+type memoryS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryS3() *memoryS3 {
+	return &memoryS3{objects: make(map[string][]byte)}
+}
+
+func (m *memoryS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	data, ok := m.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *memoryS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.objects[aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestPersistentUsageRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		stored        map[int][]time.Duration // Ages of each user's saved timestamps
+		wantRemaining map[int]int
+	}{
+		{
+			name:          "recent usage survives a restart",
+			stored:        map[int][]time.Duration{1: {time.Minute, 2 * time.Minute}},
+			wantRemaining: map[int]int{1: 8},
+		},
+		{
+			name:          "usage outside the window is pruned",
+			stored:        map[int][]time.Duration{1: {time.Minute, time.Hour}, 2: {2 * time.Hour}},
+			wantRemaining: map[int]int{1: 9, 2: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryS3()
+			state := usageState{Users: make(map[int][]time.Time)}
+			for userID, ages := range tt.stored {
+				for _, age := range ages {
+					state.Users[userID] = append(state.Users[userID], time.Now().Add(-age))
+				}
+			}
+			body, _ := json.Marshal(state)
+			store.objects["bot-a:"+UsageStateKey] = body
+
+			u := NewPersistentUsageCache(DefaultLimit, DefaultWindow, store, "bucket", "bot-a:"+UsageStateKey, time.Hour)
+			defer u.Close()
+			for userID, want := range tt.wantRemaining {
+				if got, _ := u.RemainingMessages(userID); got != want {
+					t.Errorf("user %d has %d messages left, want %d", userID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPersistentUsageSaveUsesKey(t *testing.T) {
+	store := newMemoryS3()
+	first := NewPersistentUsageCache(DefaultLimit, DefaultWindow, store, "bucket", "bot-a:"+UsageStateKey, time.Hour)
+	first.AddUsage(1)
+	first.Close()
+
+	if _, ok := store.objects[UsageStateKey]; ok {
+		t.Fatalf("usage was saved under the shared key %s", UsageStateKey)
+	}
+
+	// Another instance sharing the bucket starts from its own state
+	other := NewPersistentUsageCache(DefaultLimit, DefaultWindow, store, "bucket", "bot-b:"+UsageStateKey, time.Hour)
+	defer other.Close()
+	if got, _ := other.RemainingMessages(1); got != DefaultLimit {
+		t.Errorf("other instance sees %d messages left, want %d", got, DefaultLimit)
+	}
+
+	restarted := NewPersistentUsageCache(DefaultLimit, DefaultWindow, store, "bucket", "bot-a:"+UsageStateKey, time.Hour)
+	defer restarted.Close()
+	if got, _ := restarted.RemainingMessages(1); got != DefaultLimit-1 {
+		t.Errorf("restarted instance sees %d messages left, want %d", got, DefaultLimit-1)
+	}
+}
@@ -0,0 +1,79 @@
+// internal/utils/entities.go
+
+package utils
+
+import "strings"
+
+// TechniqueKeywords lists the fishing techniques recognized in questions.
+var TechniqueKeywords = []string{"fly fishing", "dead drift", "swing", "nymphing", "trolling", "jigging", "drift fishing", "float fishing", "bottom fishing", "casting", "spinning", "crabbing", "ice fishing", "catch and release"}
+
+// GearKeywords lists the fishing gear recognized in questions.
+var GearKeywords = []string{"fly rod", "spinning rod", "rod", "reel", "leader", "tippet", "line", "hook", "float", "bobber", "sinker", "waders", "net", "crab pot", "lure", "jig", "spoon", "fly"}
+
+// FishingEntities holds the structured entities found in a question, each a comma-separated list
+// of canonical names in the order they are listed in the taxonomy.
+type FishingEntities struct {
+	Species   string
+	Location  string
+	Technique string
+	Gear      string
+}
+
+// ExtractFishingEntities finds every known species, body of water, technique, and piece of gear mentioned
+// in a question. Species nicknames are resolved first, as in IdentifyTaxonomyCategories.
+func ExtractFishingEntities(query string) FishingEntities {
+	lowerQuery := strings.ToLower(ResolveSpeciesSynonyms(query))
+	return FishingEntities{
+		Species:   matchAll(lowerQuery, FishSpeciesKeywords),
+		Location:  matchAll(lowerQuery, BodyOfWaterKeywords),
+		Technique: matchAll(lowerQuery, TechniqueKeywords),
+		Gear:      matchAll(lowerQuery, GearKeywords),
+	}
+}
+
+// matchAll returns the keywords found as whole words in lowerQuery, comma-separated. A keyword contained
+// in a longer match, such as "rod" in "fly rod", is skipped.
+func matchAll(lowerQuery string, keywords []string) string {
+	var found []string
+	for _, kw := range keywords {
+		if !containsWord(lowerQuery, kw) {
+			continue
+		}
+		covered := false
+		for _, f := range found {
+			if containsWord(f, kw) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			found = append(found, kw)
+		}
+	}
+	return strings.Join(found, ", ")
+}
+
+// containsWord reports whether phrase appears in text with no letters directly before or after it,
+// allowing a trailing "s" for plurals such as "hooks".
+func containsWord(text, phrase string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(phrase)
+		if end < len(text) && text[end] == 's' {
+			end++
+		}
+		if (i == 0 || !isLetter(text[i-1])) && (end == len(text) || !isLetter(text[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+// isLetter reports whether b is an ASCII letter.
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
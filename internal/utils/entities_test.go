@@ -0,0 +1,47 @@
+// internal/utils/entities_test.go
+
+package utils
+
+import "testing"
+
+func TestExtractFishingEntities(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  FishingEntities
+	}{
+		{
+			"every entity",
+			"Fly fishing for steelhead on the Salmon River with a 9ft fly rod",
+			FishingEntities{Species: "steelhead", Location: "salmon river", Technique: "fly fishing", Gear: "fly rod"},
+		},
+		{
+			"species nickname",
+			"Trolling for stripers in Chesapeake Bay",
+			FishingEntities{Species: "striped bass", Location: "chesapeake bay", Technique: "trolling"},
+		},
+		{
+			"several of a kind in taxonomy order",
+			"Which reel and hooks for brown trout and steelhead?",
+			FishingEntities{Species: "steelhead, brown trout", Gear: "reel, hook"},
+		},
+		{
+			"longer match covers the shorter keyword",
+			"Is a spinning rod better than a fly rod?",
+			FishingEntities{Technique: "spinning", Gear: "fly rod, spinning rod"},
+		},
+		{
+			"keywords inside other words are ignored",
+			"Spotted a crooked liner near the jetty",
+			FishingEntities{},
+		},
+		{"no entities", "What's the weather tomorrow?", FishingEntities{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractFishingEntities(tt.query); got != tt.want {
+				t.Errorf("ExtractFishingEntities(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
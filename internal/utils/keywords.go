@@ -0,0 +1,66 @@
+// internal/utils/keywords.go
+
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minKeywordLength is the shortest word kept as a keyword unless it is in shortKeywords.
+const minKeywordLength = 4
+
+// stopwords are common words, plus filler frequent in fishing questions, that say nothing about the topic.
+var stopwords = toSet(
+	"about", "after", "also", "any", "are", "been", "before", "being", "best", "can", "could", "does", "doing",
+	"each", "even", "every", "from", "good", "have", "having", "here", "into", "just", "know", "like", "looking",
+	"make", "more", "most", "much", "need", "only", "other", "over", "please", "really", "should", "some",
+	"tell", "than", "thank", "thanks", "that", "their", "them", "then", "there", "these", "they", "thing",
+	"things", "this", "those", "tips", "very", "want", "what", "when", "where", "which", "while", "will",
+	"with", "without", "would", "your",
+	"anyone", "fish", "fishing", "fisherman", "going", "help", "recommend", "recommendations", "someone",
+	"trying", "usually",
+)
+
+// shortKeywords are meaningful words shorter than minKeywordLength that are kept as keywords.
+var shortKeywords = toSet("bay", "cod", "eel", "fly", "gar", "jig", "net", "rod", "gps", "ice")
+
+// toSet builds a lookup set from words.
+func toSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[word] = struct{}{}
+	}
+	return set
+}
+
+// ExtractKeywords returns the distinct keywords of the text, lowercased, without stopwords or short words
+// (except those in shortKeywords). Keywords are ordered by frequency, most frequent first, then alphabetically.
+func ExtractKeywords(text string) []string {
+	counts := make(map[string]int)
+	for _, word := range strings.Fields(text) {
+		word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}))
+		if _, ok := stopwords[word]; ok || word == "" {
+			continue
+		}
+		if _, ok := shortKeywords[word]; !ok && len(word) < minKeywordLength {
+			continue
+		}
+		counts[word]++
+	}
+
+	keywords := make([]string, 0, len(counts))
+	for word := range counts {
+		keywords = append(keywords, word)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if counts[keywords[i]] != counts[keywords[j]] {
+			return counts[keywords[i]] > counts[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+	return keywords
+}
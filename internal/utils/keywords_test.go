@@ -0,0 +1,33 @@
+// internal/utils/keywords_test.go
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"stopwords dropped", "What is the best bait for walleye?", []string{"bait", "walleye"}},
+		{"fishing filler dropped", "Can someone recommend fishing tips for trout?", []string{"trout"}},
+		{"short words dropped unless allowlisted", "Fly rod and jig for a big cod", []string{"cod", "fly", "jig", "rod"}},
+		{"punctuation trimmed", "\"Crankbaits\", (spinners) and jerkbaits!?", []string{"crankbaits", "jerkbaits", "spinners"}},
+		{"ordered by frequency then alphabetically", "Trout lure, trout line, trout rod, bass lure", []string{"trout", "lure", "bass", "line", "rod"}},
+		{"case folded", "Steelhead STEELHEAD steelhead", []string{"steelhead"}},
+		{"numbers kept", "Is 10lb line enough for 2024?", []string{"10lb", "2024", "enough", "line"}},
+		{"only stopwords", "What should I do?", []string{}},
+		{"empty", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractKeywords(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractKeywords(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// internal/utils/links.go
+
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultAgencyLinks maps state fish and wildlife agency names to their official fishing regulation pages.
+var DefaultAgencyLinks = map[string]string{
+	"DEC": "https://dec.ny.gov/things-to-do/freshwater-fishing/regulations-and-laws",
+	"FWC": "https://myfwc.com/fishing/",
+}
+
+// regulationKeywords identify questions or answers about fishing regulations.
+var regulationKeywords = []string{"regulation", "rules", "law", "legal", "license", "permit", "bag limit", "size limit", "creel limit", "closed season"}
+
+// IsRegulationText reports whether the text is about fishing regulations.
+func IsRegulationText(text string) bool {
+	lowerText := strings.ToLower(text)
+	for _, kw := range regulationKeywords {
+		if strings.Contains(lowerText, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrichAgencyLinks appends the official link for each agency mentioned in the text,
+// skipping agencies whose site is already linked. Agency names are matched case-sensitively
+// as whole words so abbreviations like "DEC" don't match "Dec" or "decide".
+func EnrichAgencyLinks(text string, links map[string]string) string {
+	agencies := make([]string, 0, len(links))
+	for agency := range links {
+		agencies = append(agencies, agency)
+	}
+	sort.Strings(agencies) // Deterministic link order
+
+	var additions []string
+	for _, agency := range agencies {
+		link := links[agency]
+		mentioned := regexp.MustCompile(`\b` + regexp.QuoteMeta(agency) + `\b`).MatchString(text)
+		if !mentioned || containsLink(text, link) {
+			continue
+		}
+		additions = append(additions, fmt.Sprintf("[Official %s regulations](%s)", agency, link))
+	}
+
+	if len(additions) == 0 {
+		return text
+	}
+	return text + "\n\n" + strings.Join(additions, "\n")
+}
+
+// containsLink reports whether the text already links to the same site as link.
+func containsLink(text, link string) bool {
+	if strings.Contains(text, link) {
+		return true
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(parsed.Host))
+}
+
+// ParseAgencyLinks parses a comma-separated list of AGENCY=URL pairs.
+func ParseAgencyLinks(raw string) map[string]string {
+	links := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		agency, link := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if agency != "" && link != "" {
+			links[agency] = link
+		}
+	}
+	return links
+}
@@ -0,0 +1,66 @@
+// internal/utils/links_test.go
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnrichAgencyLinks(t *testing.T) {
+	decLink := "[Official DEC regulations](" + DefaultAgencyLinks["DEC"] + ")"
+	fwcLink := "[Official FWC regulations](" + DefaultAgencyLinks["FWC"] + ")"
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"DEC mention", "Check the DEC rules for the Salmon River.", "Check the DEC rules for the Salmon River.\n\n" + decLink},
+		{"DEC mentioned twice", "The DEC sets limits; see the DEC site.", "The DEC sets limits; see the DEC site.\n\n" + decLink},
+		{"already linked", "See https://dec.ny.gov/fishing for DEC rules.", "See https://dec.ny.gov/fishing for DEC rules."},
+		{"several agencies", "Both FWC and DEC publish rules.", "Both FWC and DEC publish rules.\n\n" + decLink + "\n" + fwcLink},
+		{"different case", "In Dec the season closes; decide early.", "In Dec the season closes; decide early."},
+		{"no agency", "Bag limits vary by state.", "Bag limits vary by state."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnrichAgencyLinks(tt.text, DefaultAgencyLinks)
+			if got != tt.want {
+				t.Errorf("EnrichAgencyLinks(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			// Enriching again never adds a second copy of a link
+			if again := EnrichAgencyLinks(got, DefaultAgencyLinks); again != got {
+				t.Errorf("enriching twice = %q, want %q", again, got)
+			}
+			if n := strings.Count(got, decLink); n > 1 {
+				t.Errorf("DEC link appended %d times", n)
+			}
+		})
+	}
+}
+
+func TestIsRegulationText(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"What are the rules on the Salmon River?", true},
+		{"Do I need a LICENSE?", true},
+		{"What's the bag limit for redfish?", true},
+		{"Best bait for bass?", false},
+	}
+	for _, tt := range tests {
+		if got := IsRegulationText(tt.text); got != tt.want {
+			t.Errorf("IsRegulationText(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseAgencyLinks(t *testing.T) {
+	got := ParseAgencyLinks(" DEC = https://dec.ny.gov ,broken, =https://x.example.com,FWC=")
+	want := map[string]string{"DEC": "https://dec.ny.gov"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAgencyLinks() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+// internal/utils/markdown.go
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownHeading matches a Markdown heading line, which Telegram's legacy Markdown doesn't support.
+var markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+
+// markdownBullet matches a "* " or "- " list marker at the start of a line.
+var markdownBullet = regexp.MustCompile(`^(\s*)[*-]\s+`)
+
+// SanitizeMarkdown rewrites model output so Telegram accepts it with parse_mode Markdown. Common Markdown that
+// legacy Markdown lacks is converted: **bold** and __italic__ lose their doubled markers, headings become bold
+// lines, and "* " bullets become "•". Any *, _, `, or [ that would start an entity Telegram can't close is
+// escaped, and an unclosed ``` block is closed at the end of the text.
+func SanitizeMarkdown(text string) string {
+	text = normalizeMarkdown(text)
+
+	var sb strings.Builder
+	sb.Grow(len(text) + 16)
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("_*`[", text[i+1]) >= 0:
+			sb.WriteString(text[i : i+2]) // Already escaped
+			i += 2
+
+		case strings.HasPrefix(text[i:], codeFence):
+			end := strings.Index(text[i+len(codeFence):], codeFence)
+			if end < 0 {
+				sb.WriteString(text[i:])
+				sb.WriteString("\n" + codeFence)
+				return sb.String()
+			}
+			end += i + 2*len(codeFence)
+			sb.WriteString(text[i:end])
+			i = end
+
+		case c == '*' || c == '_' || c == '`':
+			// An underscore inside a word, as in snake_case, is never meant as italics
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 || (c == '_' && isWordByteAt(text, i-1) && isWordByteAt(text, i+1)) {
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+				i++
+				continue
+			}
+			// Entity contents are shown as-is, so they are copied through to the closing marker
+			end += i + 2
+			sb.WriteString(text[i:end])
+			i = end
+
+		case c == '[':
+			if end := linkEnd(text, i); end > 0 {
+				sb.WriteString(text[i:end])
+				i = end
+				continue
+			}
+			sb.WriteString(`\[`)
+			i++
+
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// normalizeMarkdown converts headings, bullets, and doubled emphasis markers outside code blocks
+// into their legacy Markdown equivalents.
+func normalizeMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+			if strings.Count(line, codeFence)%2 == 1 {
+				inFence = !inFence
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			line = "*" + strings.NewReplacer("*", "", "_", "").Replace(m[1]) + "*"
+		}
+		line = markdownBullet.ReplaceAllString(line, "$1• ")
+		line = strings.ReplaceAll(line, "**", "*")
+		line = strings.ReplaceAll(line, "__", "_")
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// linkEnd returns the position after a [text](url) link starting at text[i], or 0 when there is none.
+func linkEnd(text string, i int) int {
+	closeText := strings.IndexByte(text[i+1:], ']')
+	if closeText < 0 {
+		return 0
+	}
+	openURL := i + 1 + closeText + 1
+	if openURL >= len(text) || text[openURL] != '(' {
+		return 0
+	}
+	closeURL := strings.IndexByte(text[openURL:], ')')
+	if closeURL < 0 || strings.ContainsAny(text[openURL:openURL+closeURL], " \n") {
+		return 0
+	}
+	return openURL + closeURL + 1
+}
+
+// isWordByteAt reports whether text[i] is an ASCII letter or digit; positions outside text are not.
+func isWordByteAt(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	b := text[i]
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
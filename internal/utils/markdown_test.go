@@ -0,0 +1,35 @@
+// internal/utils/markdown_test.go
+
+package utils
+
+import "testing"
+
+func TestSanitizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text", "Use a jig.", "Use a jig."},
+		{"legacy entities kept", "*Bold* _italic_ `code`", "*Bold* _italic_ `code`"},
+		{"double bold", "Use a **jig**.", "Use a *jig*."},
+		{"double italic", "Use a __jig__.", "Use a _jig_."},
+		{"heading", "## Best *Lures*", "*Best Lures*"},
+		{"bullets", "* Jig\n- Spoon\n  * Fly", "• Jig\n• Spoon\n  • Fly"},
+		{"unclosed bold", "Limit is 5*3 fish", `Limit is 5\*3 fish`},
+		{"snake case", "Set max_tokens and kb_number", `Set max\_tokens and kb\_number`},
+		{"already escaped", `Use a \* marker`, `Use a \* marker`},
+		{"link", "See [regulations](https://dec.ny.gov) now", "See [regulations](https://dec.ny.gov) now"},
+		{"bracket without link", "Size [in inches", `Size \[in inches`},
+		{"closed code block", "```\nx = a*b\n```", "```\nx = a*b\n```"},
+		{"unclosed code block", "```\nx = a*b", "```\nx = a*b\n```"},
+		{"markers in code block kept", "```\n# comment\n* item\n```", "```\n# comment\n* item\n```"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeMarkdown(tt.text); got != tt.want {
+				t.Errorf("SanitizeMarkdown(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
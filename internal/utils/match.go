@@ -0,0 +1,32 @@
+// internal/utils/match.go
+
+package utils
+
+import (
+	"math"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// ScoreKnowledgeMatch rates how well a Knowledge Base entry matches a question, from 0 (nothing in common)
+// to 1 (same keywords). It is the cosine similarity of the keyword sets of the question and the entry's
+// question template, after resolving species nicknames.
+func ScoreKnowledgeMatch(query string, entry types.KnowledgeEntryResponse) float64 {
+	queryKeywords := ExtractKeywords(ResolveSpeciesSynonyms(query))
+	entryKeywords := ExtractKeywords(ResolveSpeciesSynonyms(entry.QuestionTemplate))
+	if len(queryKeywords) == 0 || len(entryKeywords) == 0 {
+		return 0
+	}
+
+	entrySet := make(map[string]struct{}, len(entryKeywords))
+	for _, kw := range entryKeywords {
+		entrySet[kw] = struct{}{}
+	}
+	shared := 0
+	for _, kw := range queryKeywords {
+		if _, ok := entrySet[kw]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(queryKeywords))*float64(len(entryKeywords)))
+}
@@ -0,0 +1,33 @@
+// internal/utils/match_test.go
+
+package utils
+
+import (
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+func TestScoreKnowledgeMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		template string
+		min, max float64
+	}{
+		{"same keywords", "Best bait for largemouth bass?", "Best bait for largemouth bass", 1, 1},
+		{"nickname matches the species", "Best lure for stripers?", "Best lure for striped bass", 1, 1},
+		{"partial overlap", "What bait for bass in spring?", "Best bait for bass", 0.5, 0.99},
+		{"nothing in common", "How do I tie a knot?", "Best bait for bass", 0, 0},
+		{"only stopwords", "the and of", "Best bait for bass", 0, 0},
+		{"empty template", "Best bait for bass?", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := ScoreKnowledgeMatch(tt.query, types.KnowledgeEntryResponse{QuestionTemplate: tt.template})
+			if score < tt.min-1e-9 || score > tt.max+1e-9 {
+				t.Errorf("ScoreKnowledgeMatch(%q, %q) = %.3f, want between %.2f and %.2f", tt.query, tt.template, score, tt.min, tt.max)
+			}
+		})
+	}
+}
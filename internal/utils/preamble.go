@@ -0,0 +1,66 @@
+// internal/utils/preamble.go
+
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultPreamblePhrases lists filler openings that models commonly prepend to answers.
+var DefaultPreamblePhrases = []string{
+	"Sure!",
+	"Sure,",
+	"Certainly!",
+	"Certainly,",
+	"Of course!",
+	"Of course,",
+	"Absolutely!",
+	"Absolutely,",
+	"Great question!",
+	"Here's",
+	"Here is",
+}
+
+// StripPreamble removes a leading boilerplate phrase from a response. It is deliberately conservative:
+//   - if the response starts with a phrase and its first line is an introduction ending in a colon
+//     (e.g. "Sure! Here's what you need to know:"), the whole first line is dropped;
+//   - otherwise the phrase itself is only removed when it ends in punctuation (e.g. "Sure!"),
+//     so phrases like "Here's" never cut into a substantive sentence.
+//
+// The original text is returned if stripping would leave nothing.
+func StripPreamble(text string, phrases []string) string {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	lowerText := strings.ToLower(trimmed)
+
+	for _, phrase := range phrases {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" || !strings.HasPrefix(lowerText, strings.ToLower(phrase)) {
+			continue
+		}
+
+		var stripped string
+		firstLine, rest, _ := strings.Cut(trimmed, "\n")
+		if strings.HasSuffix(strings.TrimSpace(firstLine), ":") {
+			stripped = rest
+		} else if last, _ := utf8.DecodeLastRuneInString(phrase); unicode.IsPunct(last) {
+			stripped = trimmed[len(phrase):]
+		} else {
+			continue
+		}
+
+		stripped = strings.TrimSpace(stripped)
+		if stripped == "" {
+			return text
+		}
+		return capitalizeFirst(stripped)
+	}
+	return text
+}
+
+// capitalizeFirst upper-cases the first letter of the text.
+func capitalizeFirst(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
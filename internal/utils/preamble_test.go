@@ -0,0 +1,39 @@
+// internal/utils/preamble_test.go
+
+package utils
+
+import "testing"
+
+func TestStripPreamble(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"exclamation", "Sure! Use a drop shot rig near weed edges.", "Use a drop shot rig near weed edges."},
+		{"comma", "Certainly, spinnerbaits work well in stained water.", "Spinnerbaits work well in stained water."},
+		{"introduction line", "Great question! Here's what you need to know:\n- Fish early\n- Use live bait", "- Fish early\n- Use live bait"},
+		{"leading whitespace", "  \nOf course! Try a jig.", "Try a jig."},
+		{"phrase without punctuation is kept", "Here's the thing about tides: they move fish.", "Here's the thing about tides: they move fish."},
+		{"substantive answer untouched", "Largemouth bass prefer warm, shallow water.", "Largemouth bass prefer warm, shallow water."},
+		{"nothing left after stripping", "Sure!", "Sure!"},
+		{"phrase mid-answer untouched", "Bass bite at dawn. Sure! They also bite at dusk.", "Bass bite at dawn. Sure! They also bite at dusk."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripPreamble(tt.text, DefaultPreamblePhrases); got != tt.want {
+				t.Errorf("StripPreamble(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripPreambleCustomPhrases(t *testing.T) {
+	phrases := []string{"Ahoy, angler!", "  "}
+	if got, want := StripPreamble("Ahoy, angler! Cast upstream.", phrases), "Cast upstream."; got != want {
+		t.Errorf("StripPreamble() = %q, want %q", got, want)
+	}
+	if got, want := StripPreamble("Sure! Cast upstream.", phrases), "Sure! Cast upstream."; got != want {
+		t.Errorf("StripPreamble() with an unconfigured phrase = %q, want %q", got, want)
+	}
+}
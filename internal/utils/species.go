@@ -0,0 +1,90 @@
+// internal/utils/species.go
+
+package utils
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultSpeciesSynonyms maps canonical species names to common nicknames.
+//
+//go:embed species_synonyms.json
+var defaultSpeciesSynonyms []byte
+
+var (
+	speciesMutex   sync.RWMutex
+	speciesAliases map[string]string // Lowercase nickname to canonical species
+	speciesPattern *regexp.Regexp    // Matches any nickname as a whole word
+)
+
+func init() {
+	if err := setSpeciesSynonyms(defaultSpeciesSynonyms); err != nil {
+		panic(fmt.Sprintf("invalid embedded species synonyms: %v", err))
+	}
+}
+
+// LoadSpeciesSynonyms replaces the species synonym map with a JSON file of canonical names to nicknames.
+// An empty path keeps the embedded defaults.
+func LoadSpeciesSynonyms(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read species synonyms file: %w", err)
+	}
+	return setSpeciesSynonyms(data)
+}
+
+// setSpeciesSynonyms parses the synonym JSON and compiles the nickname pattern.
+func setSpeciesSynonyms(data []byte) error {
+	var synonyms map[string][]string
+	if err := json.Unmarshal(data, &synonyms); err != nil {
+		return fmt.Errorf("failed to parse species synonyms: %w", err)
+	}
+
+	aliases := make(map[string]string)
+	var quoted []string
+	for canonical, nicknames := range synonyms {
+		for _, nickname := range nicknames {
+			nickname = strings.ToLower(strings.TrimSpace(nickname))
+			if nickname == "" {
+				continue
+			}
+			aliases[nickname] = strings.ToLower(canonical)
+			quoted = append(quoted, regexp.QuoteMeta(nickname))
+		}
+	}
+
+	var pattern *regexp.Regexp
+	if len(quoted) > 0 {
+		// Longest nicknames first so "chinook salmon" wins over "chinook"
+		sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+		pattern = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+
+	speciesMutex.Lock()
+	defer speciesMutex.Unlock()
+	speciesAliases = aliases
+	speciesPattern = pattern
+	return nil
+}
+
+// ResolveSpeciesSynonyms replaces whole-word species nicknames in text with their canonical names.
+func ResolveSpeciesSynonyms(text string) string {
+	speciesMutex.RLock()
+	defer speciesMutex.RUnlock()
+	if speciesPattern == nil {
+		return text
+	}
+	return speciesPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return speciesAliases[strings.ToLower(match)]
+	})
+}
@@ -0,0 +1,82 @@
+// internal/utils/species_test.go
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSpeciesSynonyms(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"nickname", "Where are the stripers?", "Where are the striped bass?"},
+		{"case insensitive", "Any KINGS running?", "Any king salmon running?"},
+		{"longest nickname wins", "Chinook salmon limits", "king salmon limits"},
+		{"canonical name unchanged", "striped bass season", "striped bass season"},
+		{"no partial-word match", "Parking at Kingston for brownstone bay", "Parking at Kingston for brownstone bay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveSpeciesSynonyms(tt.input); got != tt.want {
+				t.Errorf("ResolveSpeciesSynonyms(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifyTaxonomyCategoriesResolvesNicknames(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantSpecies string
+	}{
+		{"striper", "Where are the stripers biting?", "striped bass"},
+		{"kings", "When do kings run?", "king salmon"},
+		{"steelies", "Best flies for steelies", "steelhead"},
+		{"no false positive", "Is the parking at Kingsbury open?", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, species, _, _ := IdentifyTaxonomyCategories(tt.query); species != tt.wantSpecies {
+				t.Errorf("IdentifyTaxonomyCategories(%q) species = %q, want %q", tt.query, species, tt.wantSpecies)
+			}
+		})
+	}
+}
+
+func TestLoadSpeciesSynonyms(t *testing.T) {
+	defer setSpeciesSynonyms(defaultSpeciesSynonyms)
+
+	path := filepath.Join(t.TempDir(), "synonyms.json")
+	if err := os.WriteFile(path, []byte(`{"walleye": ["eyes", "marble eyes"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+		input   string
+		want    string
+	}{
+		{"empty path keeps defaults", "", false, "stripers", "striped bass"},
+		{"missing file", filepath.Join(t.TempDir(), "missing.json"), true, "stripers", "striped bass"},
+		{"custom file replaces defaults", path, false, "marble eyes and stripers", "walleye and stripers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadSpeciesSynonyms(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSpeciesSynonyms error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := ResolveSpeciesSynonyms(tt.input); got != tt.want {
+				t.Errorf("ResolveSpeciesSynonyms(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// internal/utils/split.go
+
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TelegramMessageLimit is the maximum length of a Telegram message.
+const TelegramMessageLimit = 4096
+
+// codeFence opens and closes a Markdown code block.
+const codeFence = "```"
+
+// splitSeparators are the preferred places to split a message, from best to worst.
+var splitSeparators = []string{"\n\n", "\n", ". ", "! ", "? ", " "}
+
+// SplitMessage breaks text into chunks of at most limit bytes, preferring paragraph, line, sentence,
+// and then word boundaries. Chunks never end inside a code block, inline code, or bold span unless a
+// single span is longer than the limit, in which case an open code block is closed and reopened.
+func SplitMessage(text string, limit int) []string {
+	if limit <= 0 || len(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	for len(text) > limit {
+		var chunk, rest string
+		if cut := splitPoint(text[:limit]); cut > 0 {
+			chunk, rest = text[:cut], text[cut:]
+		} else {
+			// No safe boundary: cut mid-span, leaving room to close and reopen a code block
+			reserve := 0
+			if limit > 4*(len(codeFence)+1) {
+				reserve = len(codeFence) + 1
+			}
+			cut = runeCut(text, limit-reserve)
+			if nl := strings.LastIndex(text[:cut], "\n"); nl > cut/2 {
+				cut = nl + 1 // Keep code lines whole where possible
+			}
+			chunk, rest = text[:cut], text[cut:]
+			if reserve > 0 && markdownState(chunk).inFence {
+				chunk = strings.TrimRight(chunk, "\n") + "\n" + codeFence
+				rest = codeFence + "\n" + rest
+			}
+		}
+
+		if chunk = strings.TrimRight(chunk, " \n"); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		text = strings.TrimLeft(rest, " \n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// splitPoint returns the latest position in window to split at, preferring better separators.
+// It returns 0 when no separator falls outside a Markdown span.
+func splitPoint(window string) int {
+	safe := safePositions(window)
+	for _, sep := range splitSeparators {
+		for i := strings.LastIndex(window, sep); i > 0; i = strings.LastIndex(window[:i], sep) {
+			if cut := i + len(sep); safe[cut] {
+				return cut
+			}
+		}
+	}
+	return 0
+}
+
+// runeCut returns the largest position no greater than limit that does not split a UTF-8 character.
+func runeCut(text string, limit int) int {
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		return limit
+	}
+	return cut
+}
+
+// markdownSpans tracks which Markdown spans are open while scanning text.
+type markdownSpans struct {
+	inFence bool // Inside a ``` code block
+	inCode  bool // Inside `inline code`
+	inBold  bool // Inside *bold* text
+}
+
+// open reports whether any span is still open.
+func (m markdownSpans) open() bool {
+	return m.inFence || m.inCode || m.inBold
+}
+
+// scan advances the state past the token at text[i] and returns the position after it.
+func (m *markdownSpans) scan(text string, i int) int {
+	switch {
+	case strings.HasPrefix(text[i:], codeFence):
+		m.inFence = !m.inFence
+		return i + len(codeFence)
+	case m.inFence:
+	case text[i] == '\\' && i+1 < len(text):
+		return i + 2 // Escaped character
+	case text[i] == '`':
+		m.inCode = !m.inCode
+	case m.inCode:
+	case text[i] == '*':
+		m.inBold = !m.inBold
+	}
+	return i + 1
+}
+
+// markdownState returns the spans left open at the end of text.
+func markdownState(text string) markdownSpans {
+	var state markdownSpans
+	for i := 0; i < len(text); {
+		i = state.scan(text, i)
+	}
+	return state
+}
+
+// safePositions reports, for each position in text, whether splitting there leaves no span open.
+func safePositions(text string) []bool {
+	safe := make([]bool, len(text)+1)
+	safe[0] = true
+	var state markdownSpans
+	for i := 0; i < len(text); {
+		i = state.scan(text, i)
+		safe[i] = !state.open()
+	}
+	return safe
+}
@@ -0,0 +1,61 @@
+// internal/utils/split_test.go
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	var longCode strings.Builder
+	longCode.WriteString("```\n")
+	for i := 0; i < 10; i++ {
+		longCode.WriteString("cast line number\n")
+	}
+	longCode.WriteString("```")
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string // Exact chunks; nil checks only the invariants
+	}{
+		{"fits", "Use a jig.", 4096, []string{"Use a jig."}},
+		{"sentences", "First sentence here. Second sentence here. Third one.", 25,
+			[]string{"First sentence here.", "Second sentence here.", "Third one."}},
+		{"paragraph preferred", "Intro line one\nline two\n\nNext paragraph.", 30,
+			[]string{"Intro line one\nline two", "Next paragraph."}},
+		{"code block kept whole", "Intro text.\n\n```\ncode line one\ncode line two\n```\n\nOutro.", 50,
+			[]string{"Intro text.\n\n```\ncode line one\ncode line two\n```", "Outro."}},
+		{"bold span kept whole", "Intro *bold words here and more* end", 30,
+			[]string{"Intro", "*bold words here and more* end"}},
+		{"oversized code block is reopened", longCode.String(), 60, nil},
+		{"multibyte text without spaces", strings.Repeat("é", 30), 9, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitMessage(tt.text, tt.limit)
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitMessage() = %q, want %q", got, tt.want)
+			}
+			for i, chunk := range got {
+				if len(chunk) > tt.limit {
+					t.Errorf("chunk %d is %d bytes, over the %d limit", i, len(chunk), tt.limit)
+				}
+				if !utf8.ValidString(chunk) {
+					t.Errorf("chunk %d splits a character: %q", i, chunk)
+				}
+				if markdownState(chunk).inFence {
+					t.Errorf("chunk %d leaves a code block open: %q", i, chunk)
+				}
+			}
+			if joined := strings.Join(got, ""); strings.Count(joined, "cast line number") != strings.Count(tt.text, "cast line number") ||
+				strings.Count(joined, "é") != strings.Count(tt.text, "é") {
+				t.Errorf("content was lost: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// internal/utils/tokens.go
+
+package utils
+
+import "ReelTalkBot-Go/internal/types"
+
+// Approximate token accounting: about four characters per token plus a fixed per-message overhead.
+const (
+	charsPerToken    = 4
+	tokensPerMessage = 4
+)
+
+// EstimateMessageTokens returns the approximate number of tokens a message uses in a request.
+func EstimateMessageTokens(message types.OpenAIMessage) int {
+	return tokensPerMessage + (len(message.Content)+charsPerToken-1)/charsPerToken
+}
+
+// TrimMessagesToTokenBudget keeps the leading system message plus the most recent messages that fit
+// within maxTokens, dropping the oldest turns first. The latest message is always kept.
+// A maxTokens of 0 or less disables trimming.
+func TrimMessagesToTokenBudget(messages []types.OpenAIMessage, maxTokens int) []types.OpenAIMessage {
+	if maxTokens <= 0 || len(messages) == 0 {
+		return messages
+	}
+
+	var system []types.OpenAIMessage
+	rest := messages
+	used := 0
+	if messages[0].Role == "system" {
+		system = messages[:1]
+		rest = messages[1:]
+		used = EstimateMessageTokens(messages[0])
+	}
+
+	// Walk backwards from the newest message, keeping turns while they fit
+	keepFrom := len(rest)
+	for i := len(rest) - 1; i >= 0; i-- {
+		tokens := EstimateMessageTokens(rest[i])
+		if used+tokens > maxTokens && i < len(rest)-1 {
+			break
+		}
+		used += tokens
+		keepFrom = i
+	}
+	if keepFrom == 0 {
+		return messages
+	}
+
+	trimmed := make([]types.OpenAIMessage, 0, len(system)+len(rest)-keepFrom)
+	trimmed = append(trimmed, system...)
+	return append(trimmed, rest[keepFrom:]...)
+}
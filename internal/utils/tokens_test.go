@@ -0,0 +1,74 @@
+// internal/utils/tokens_test.go
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ReelTalkBot-Go/internal/types"
+)
+
+// sized returns a message estimated at tokens tokens.
+func sized(role, name string, tokens int) types.OpenAIMessage {
+	content := name + strings.Repeat(" ", (tokens-tokensPerMessage)*charsPerToken-len(name))
+	return types.OpenAIMessage{Role: role, Content: content}
+}
+
+func TestEstimateMessageTokens(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"", 4},
+		{"bass", 5},
+		{"bass!", 6},
+		{strings.Repeat("x", 400), 104},
+	}
+	for _, tt := range tests {
+		if got := EstimateMessageTokens(types.OpenAIMessage{Role: "user", Content: tt.content}); got != tt.want {
+			t.Errorf("EstimateMessageTokens(%d chars) = %d, want %d", len(tt.content), got, tt.want)
+		}
+	}
+}
+
+func TestTrimMessagesToTokenBudget(t *testing.T) {
+	system := sized("system", "system", 10)
+	q1 := sized("user", "q1", 10)
+	a1 := sized("assistant", "a1", 10)
+	q2 := sized("user", "q2", 10)
+	a2 := sized("assistant", "a2", 10)
+	q3 := sized("user", "q3", 10)
+	big := sized("user", "big", 100)
+
+	tests := []struct {
+		name      string
+		messages  []types.OpenAIMessage
+		maxTokens int
+		want      []types.OpenAIMessage
+	}{
+		{"disabled", []types.OpenAIMessage{system, q1, a1, q2}, 0, []types.OpenAIMessage{system, q1, a1, q2}},
+		{"fits", []types.OpenAIMessage{system, q1, a1, q2}, 40, []types.OpenAIMessage{system, q1, a1, q2}},
+		{"oldest turns dropped", []types.OpenAIMessage{system, q1, a1, q2, a2, q3}, 40, []types.OpenAIMessage{system, q2, a2, q3}},
+		{"no system prompt", []types.OpenAIMessage{q1, a1, q2, a2, q3}, 20, []types.OpenAIMessage{a2, q3}},
+		{"latest message always kept", []types.OpenAIMessage{system, q1, a1, big}, 40, []types.OpenAIMessage{system, big}},
+		{"empty", nil, 40, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimMessagesToTokenBudget(tt.messages, tt.maxTokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TrimMessagesToTokenBudget() kept %v, want %v", contents(got), contents(tt.want))
+			}
+		})
+	}
+}
+
+// contents returns the leading word of each message for readable failures.
+func contents(messages []types.OpenAIMessage) []string {
+	names := make([]string, 0, len(messages))
+	for _, m := range messages {
+		names = append(names, strings.TrimSpace(m.Content))
+	}
+	return names
+}
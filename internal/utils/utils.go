@@ -14,42 +14,41 @@ func SummarizeToLength(text string, maxLength int) string {
 	return text[:maxLength]
 }
 
-// ExtractKeywords extracts keywords from the input text.
-// This is a simple implementation and can be enhanced.
-func ExtractKeywords(text string) []string {
-	words := strings.Fields(text)
-	keywordSet := make(map[string]struct{})
-	for _, word := range words {
-		cleanedWord := strings.ToLower(strings.Trim(word, ".,!?\"'"))
-		if len(cleanedWord) > 3 { // Simple filter: words longer than 3 characters
-			keywordSet[cleanedWord] = struct{}{}
-		}
-	}
+// markdownEscaper escapes characters that have special meaning in Telegram's legacy Markdown.
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
 
-	var keywords []string
-	for word := range keywordSet {
-		keywords = append(keywords, word)
-	}
-	return keywords
+// EscapeMarkdown escapes user-provided text so it is shown literally with parse_mode Markdown.
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// BodyOfWaterKeywords lists the bodies of water recognized in questions.
+var BodyOfWaterKeywords = []string{"salmon river", "lake ontario", "hoh river", "chesapeake bay", "great lake tributaries"}
+
+// FishSpeciesKeywords lists the fish species recognized in questions.
+var FishSpeciesKeywords = []string{"steelhead", "blue crab", "striped bass", "king salmon", "coho salmon", "brown trout", "eastern menhaden", "spot", "croaker", "black drum", "atlantic sturgeon"}
+
+// WaterTypeKeywords lists the water types recognized in questions.
+var WaterTypeKeywords = []string{"adronomous", "lentic", "lotic"}
+
+// CategoryKeywords maps each question category to the keywords that indicate it.
+var CategoryKeywords = map[string][]string{
+	"Timing":                          {"timing", "season", "best time", "peak season"},
+	"Gear Selection":                  {"gear", "equipment", "rod", "reel", "line"},
+	"Bait/Lures/Fly Selection":        {"bait", "lures", "fly selection", "fly patterns"},
+	"Reading Water":                   {"reading water", "water conditions", "pools", "seams"},
+	"Presenting Bait/Lure/Fly":        {"presentation", "drift", "swing", "dead drift"},
+	"Handling the Strike or Fight":    {"handling strike", "fighting fish", "hook set"},
+	"Casting/Presentation":            {"casting", "presentation", "mending"},
+	"Fish Handling/Catch and Release": {"handling fish", "catch and release", "revive"},
 }
 
 // DetermineCategories determines categories based on keywords.
 func DetermineCategories(keywords []string) string {
-	categoryMap := map[string][]string{
-		"Timing":                          {"timing", "season", "best time", "peak season"},
-		"Gear Selection":                  {"gear", "equipment", "rod", "reel", "line"},
-		"Bait/Lures/Fly Selection":        {"bait", "lures", "fly selection", "fly patterns"},
-		"Reading Water":                   {"reading water", "water conditions", "pools", "seams"},
-		"Presenting Bait/Lure/Fly":        {"presentation", "drift", "swing", "dead drift"},
-		"Handling the Strike or Fight":    {"handling strike", "fighting fish", "hook set"},
-		"Casting/Presentation":            {"casting", "presentation", "mending"},
-		"Fish Handling/Catch and Release": {"handling fish", "catch and release", "revive"},
-	}
-
 	determinedCategories := make(map[string]struct{})
 
 	for _, kw := range keywords {
-		for category, kws := range categoryMap {
+		for category, kws := range CategoryKeywords {
 			for _, ckw := range kws {
 				if kw == ckw {
 					determinedCategories[category] = struct{}{}
@@ -71,26 +70,13 @@ func DetermineCategories(keywords []string) string {
 }
 
 // IdentifyTaxonomyCategories parses the user query to extract taxonomy categories.
+// Species nicknames such as "stripers" are resolved to their canonical names before matching.
 // This function can be further enhanced based on specific taxonomy requirements.
 func IdentifyTaxonomyCategories(query string) (bodyOfWater, fishSpecies, waterType, category string) {
-	lowerQuery := strings.ToLower(query)
-
-	bodyOfWaterKeywords := []string{"salmon river", "lake ontario", "hoh river", "chesapeake bay", "great lake tributaries"}
-	fishSpeciesKeywords := []string{"steelhead", "blue crab", "striped bass", "king salmon", "coho salmon", "brown trout", "eastern menhaden", "spot", "croaker", "black drum", "atlantic sturgeon"}
-	waterTypeKeywords := []string{"adronomous", "lentic", "lotic"}
-	categoryKeywords := map[string][]string{
-		"Timing":                          {"timing", "season", "best time", "peak season"},
-		"Gear Selection":                  {"gear", "equipment", "rod", "reel", "line"},
-		"Bait/Lures/Fly Selection":        {"bait", "lures", "fly selection", "fly patterns"},
-		"Reading Water":                   {"reading water", "water conditions", "pools", "seams"},
-		"Presenting Bait/Lure/Fly":        {"presentation", "drift", "swing", "dead drift"},
-		"Handling the Strike or Fight":    {"handling strike", "fighting fish", "hook set"},
-		"Casting/Presentation":            {"casting", "presentation", "mending"},
-		"Fish Handling/Catch and Release": {"handling fish", "catch and release", "revive"},
-	}
+	lowerQuery := strings.ToLower(ResolveSpeciesSynonyms(query))
 
 	// Identify BodyOfWater
-	for _, kw := range bodyOfWaterKeywords {
+	for _, kw := range BodyOfWaterKeywords {
 		if strings.Contains(lowerQuery, kw) {
 			bodyOfWater = kw
 			break
@@ -98,7 +84,7 @@ func IdentifyTaxonomyCategories(query string) (bodyOfWater, fishSpecies, waterTy
 	}
 
 	// Identify FishSpecies
-	for _, kw := range fishSpeciesKeywords {
+	for _, kw := range FishSpeciesKeywords {
 		if strings.Contains(lowerQuery, kw) {
 			fishSpecies = kw
 			break
@@ -106,7 +92,7 @@ func IdentifyTaxonomyCategories(query string) (bodyOfWater, fishSpecies, waterTy
 	}
 
 	// Identify WaterType
-	for _, kw := range waterTypeKeywords {
+	for _, kw := range WaterTypeKeywords {
 		if strings.Contains(lowerQuery, kw) {
 			waterType = kw
 			break
@@ -114,7 +100,7 @@ func IdentifyTaxonomyCategories(query string) (bodyOfWater, fishSpecies, waterTy
 	}
 
 	// Identify Category
-	for cat, kws := range categoryKeywords {
+	for cat, kws := range CategoryKeywords {
 		for _, kw := range kws {
 			if strings.Contains(lowerQuery, kw) {
 				category = cat
@@ -128,3 +114,23 @@ func IdentifyTaxonomyCategories(query string) (bodyOfWater, fishSpecies, waterTy
 
 	return
 }
+
+// Regions used to route Knowledge Base queries
+const (
+	RegionEast = "east"
+	RegionWest = "west"
+)
+
+// bodyOfWaterRegions maps the bodies of water recognized by IdentifyTaxonomyCategories to their coast.
+var bodyOfWaterRegions = map[string]string{
+	"salmon river":           RegionEast,
+	"lake ontario":           RegionEast,
+	"chesapeake bay":         RegionEast,
+	"great lake tributaries": RegionEast,
+	"hoh river":              RegionWest,
+}
+
+// RegionForBodyOfWater returns the region of a detected body of water, or "" when it is unknown.
+func RegionForBodyOfWater(bodyOfWater string) string {
+	return bodyOfWaterRegions[strings.ToLower(bodyOfWater)]
+}
@@ -0,0 +1,85 @@
+// internal/watchdog/watchdog.go
+
+package watchdog
+
+import (
+	"sync"
+	"time"
+)
+
+// Watchdog raises an alert when no updates have been processed for longer than the idle interval.
+// It only alerts during active hours and alerts once per idle period.
+type Watchdog struct {
+	idle      time.Duration
+	startHour int // First active hour (0-23)
+	endHour   int // Hour at which the active window ends (1-24); equal to startHour means always active
+	alert     func(idleFor time.Duration)
+	now       func() time.Time // Injectable clock
+
+	mutex    sync.Mutex
+	lastSeen time.Time
+	fired    bool
+}
+
+// NewWatchdog initializes a Watchdog that calls alert after idle without a Touch.
+// The clock defaults to time.Now when now is nil.
+func NewWatchdog(idle time.Duration, startHour, endHour int, alert func(idleFor time.Duration), now func() time.Time) *Watchdog {
+	if now == nil {
+		now = time.Now
+	}
+	return &Watchdog{
+		idle:      idle,
+		startHour: startHour,
+		endHour:   endHour,
+		alert:     alert,
+		now:       now,
+		lastSeen:  now(),
+	}
+}
+
+// Touch records that an update was processed and re-arms the alert.
+func (w *Watchdog) Touch() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.lastSeen = w.now()
+	w.fired = false
+}
+
+// Check fires the alert if the bot has been idle too long during active hours.
+// It reports whether the alert fired.
+func (w *Watchdog) Check() bool {
+	w.mutex.Lock()
+	now := w.now()
+	idleFor := now.Sub(w.lastSeen)
+	if w.fired || idleFor < w.idle || !w.isActiveHour(now.Hour()) {
+		w.mutex.Unlock()
+		return false
+	}
+	w.fired = true
+	w.mutex.Unlock()
+
+	w.alert(idleFor)
+	return true
+}
+
+// Start runs Check every interval in a background goroutine.
+func (w *Watchdog) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for range ticker.C {
+			w.Check()
+		}
+	}()
+}
+
+// isActiveHour reports whether hour falls within the active window, which may wrap past midnight.
+func (w *Watchdog) isActiveHour(hour int) bool {
+	if w.startHour == w.endHour {
+		return true
+	}
+	if w.startHour < w.endHour {
+		return hour >= w.startHour && hour < w.endHour
+	}
+	return hour >= w.startHour || hour < w.endHour
+}
@@ -0,0 +1,83 @@
+// internal/watchdog/watchdog_test.go
+
+package watchdog
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for driving a Watchdog without waiting.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     time.Time
+		startHour int
+		endHour   int
+		idleFor   time.Duration
+		wantFire  bool
+	}{
+		{"not idle long enough", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), 8, 22, 30 * time.Minute, false},
+		{"idle during active hours", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), 8, 22, 2 * time.Hour, true},
+		{"idle outside active hours", time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), 8, 22, 2 * time.Hour, false},
+		{"window wrapping midnight", time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC), 20, 4, 2 * time.Hour, true},
+		{"outside a wrapping window", time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), 20, 4, 2 * time.Hour, false},
+		{"equal hours are always active", time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), 0, 0, 2 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: tt.start}
+			var alerts []time.Duration
+			w := NewWatchdog(time.Hour, tt.startHour, tt.endHour, func(idleFor time.Duration) {
+				alerts = append(alerts, idleFor)
+			}, clock.Now)
+
+			clock.advance(tt.idleFor)
+			if got := w.Check(); got != tt.wantFire {
+				t.Fatalf("Check() = %v, want %v", got, tt.wantFire)
+			}
+			if tt.wantFire && (len(alerts) != 1 || alerts[0] != tt.idleFor) {
+				t.Errorf("alerts = %v, want one for %v", alerts, tt.idleFor)
+			}
+			if !tt.wantFire && len(alerts) != 0 {
+				t.Errorf("alerts = %v, want none", alerts)
+			}
+		})
+	}
+}
+
+func TestCheckAlertsOncePerIdlePeriod(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	alerts := 0
+	w := NewWatchdog(time.Hour, 0, 0, func(time.Duration) { alerts++ }, clock.Now)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		touch   bool
+		want    int
+	}{
+		{"first idle period alerts", 2 * time.Hour, false, 1},
+		{"still idle does not alert again", time.Hour, false, 1},
+		{"touch re-arms but is not idle yet", 0, true, 1},
+		{"next idle period alerts", 2 * time.Hour, false, 2},
+	}
+	for _, step := range steps {
+		clock.advance(step.advance)
+		if step.touch {
+			w.Touch()
+		}
+		w.Check()
+		if alerts != step.want {
+			t.Fatalf("%s: %d alerts, want %d", step.name, alerts, step.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// internal/whatsapp/whatsapp_handler.go
+
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ReelTalkBot-Go/internal/cache"
+	"ReelTalkBot-Go/internal/handlers"
+	"ReelTalkBot-Go/internal/httpclient"
+	"ReelTalkBot-Go/internal/utils"
+)
+
+// graphAPIBase is the Graph API used to send WhatsApp messages.
+const graphAPIBase = "https://graph.facebook.com/v19.0"
+
+// messageLimit is the maximum length of a WhatsApp text message.
+const messageLimit = 4096
+
+// maxBodyBytes caps the size of a webhook payload.
+const maxBodyBytes = 1 << 20
+
+// DefaultMaxConcurrent is the default number of WhatsApp messages answered at once.
+const DefaultMaxConcurrent = 8
+
+// messageDedupTTL covers the period in which Meta redelivers a webhook event that wasn't acknowledged.
+const messageDedupTTL = time.Hour
+
+// webhookPayload is the subset of a WhatsApp Cloud API webhook event the bot uses.
+type webhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Messages []inboundMessage `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// inboundMessage is a message a WhatsApp user sent to the business number.
+type inboundMessage struct {
+	From string `json:"from"` // The sender's wa_id
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Text *struct {
+		Body string `json:"body"`
+	} `json:"text,omitempty"`
+}
+
+// TextMessage is a text message extracted from a webhook event.
+type TextMessage struct {
+	ID   string // The message's wamid, which stays the same when Meta redelivers it
+	WaID string // The sender's WhatsApp ID, their phone number in international format
+	Text string
+}
+
+// WhatsAppHandler answers WhatsApp Cloud API messages through the same pipeline as Telegram messages.
+type WhatsAppHandler struct {
+	VerifyToken   string // Token Meta echoes during webhook verification
+	AppSecret     string // App secret used to verify X-Hub-Signature-256; events are rejected without one
+	AccessToken   string // Graph API token used to send replies
+	PhoneNumberID string // Business phone number that replies are sent from
+	Processor     handlers.WhatsAppProcessor
+	Client        *http.Client
+	seen          *cache.Cache  // IDs of messages already accepted, so redelivered events are not answered twice
+	slots         chan struct{} // Bounds the messages being answered at once
+}
+
+// NewWhatsAppHandler initializes a WhatsAppHandler for the business phone number that answers up to
+// maxConcurrent messages at once.
+func NewWhatsAppHandler(verifyToken, appSecret, accessToken, phoneNumberID string, maxConcurrent int, processor handlers.WhatsAppProcessor) *WhatsAppHandler {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	seen := cache.NewCache()
+	seen.StartEviction(messageDedupTTL)
+	return &WhatsAppHandler{
+		VerifyToken:   verifyToken,
+		AppSecret:     appSecret,
+		AccessToken:   accessToken,
+		PhoneNumberID: phoneNumberID,
+		Processor:     processor,
+		Client:        httpclient.New(10 * time.Second),
+		seen:          seen,
+		slots:         make(chan struct{}, maxConcurrent),
+	}
+}
+
+// HandleWhatsAppWebhook is the HTTP handler for the WhatsApp webhook. GET requests answer Meta's
+// hub.challenge verification; POST requests are verified, acknowledged, and answered in the background.
+func (wh *WhatsAppHandler) HandleWhatsAppWebhook(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		wh.handleVerification(w, r)
+	case http.MethodPost:
+		wh.handleEvent(w, r)
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleVerification echoes hub.challenge when the subscription request carries the configured verify token.
+func (wh *WhatsAppHandler) handleVerification(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("hub.mode") != "subscribe" || wh.VerifyToken == "" ||
+		!hmac.Equal([]byte(query.Get("hub.verify_token")), []byte(wh.VerifyToken)) {
+		log.Printf("Rejected WhatsApp webhook verification with an invalid verify token")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, query.Get("hub.challenge"))
+}
+
+// handleEvent verifies a webhook event and answers every text message in it.
+func (wh *WhatsAppHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if !wh.verifySignature(r.Header.Get("X-Hub-Signature-256"), body) {
+		log.Printf("Rejected WhatsApp webhook event with an invalid signature")
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	messages, err := ExtractTextMessages(body)
+	if err != nil {
+		log.Printf("Failed to decode WhatsApp webhook event: %v", err)
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	// Meta redelivers events that aren't acknowledged quickly, so answer in the background
+	if !wh.dispatch(messages) {
+		log.Printf("WhatsApp handler is busy, asking Meta to redeliver the event")
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatch starts answering each message not seen before, up to the concurrency limit. It reports false
+// when some messages were turned away for lack of a free slot; those are forgotten so a redelivery answers them.
+func (wh *WhatsAppHandler) dispatch(messages []TextMessage) bool {
+	accepted := true
+	for _, message := range messages {
+		if message.ID != "" && !wh.seen.Add(message.ID, "", messageDedupTTL) {
+			log.Printf("Skipping duplicate WhatsApp message %s", message.ID)
+			continue
+		}
+		select {
+		case wh.slots <- struct{}{}:
+			go func(message TextMessage) {
+				defer func() { <-wh.slots }()
+				wh.answer(message)
+			}(message)
+		default:
+			if message.ID != "" {
+				wh.seen.Delete(message.ID)
+			}
+			accepted = false
+		}
+	}
+	return accepted
+}
+
+// verifySignature checks the HMAC-SHA256 of the body that Meta sends as "sha256=<hex>".
+// Every request is rejected when no app secret is configured.
+func (wh *WhatsAppHandler) verifySignature(header string, body []byte) bool {
+	if wh.AppSecret == "" {
+		return false
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil || !strings.HasPrefix(header, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(wh.AppSecret))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// ExtractTextMessages returns the text messages in a webhook event. Status updates and other
+// message types, such as images or reactions, are skipped.
+func ExtractTextMessages(body []byte) ([]TextMessage, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var messages []TextMessage
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, message := range change.Value.Messages {
+				if message.Type != "text" || message.Text == nil || message.From == "" {
+					continue
+				}
+				if text := strings.TrimSpace(message.Text.Body); text != "" {
+					messages = append(messages, TextMessage{ID: message.ID, WaID: message.From, Text: text})
+				}
+			}
+		}
+	}
+	return messages, nil
+}
+
+// answer runs the message through the processor and sends the reply to the sender.
+func (wh *WhatsAppHandler) answer(message TextMessage) {
+	log.Printf("Received WhatsApp message from %s: %s", message.WaID, message.Text)
+
+	reply, err := wh.Processor.ProcessWhatsAppMessage(message.WaID, message.Text)
+	if err != nil {
+		log.Printf("Failed to process WhatsApp message: %v", err)
+		reply = "Sorry, something went wrong while answering. Please try again later."
+	}
+
+	for _, chunk := range utils.SplitMessage(reply, messageLimit) {
+		if err := wh.send(message.WaID, chunk); err != nil {
+			log.Printf("Failed to send WhatsApp reply: %v", err)
+			return
+		}
+	}
+}
+
+// send posts a text message to a WhatsApp user through the Graph API's /messages endpoint.
+func (wh *WhatsAppHandler) send(waID, text string) error {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                waID,
+		"type":              "text",
+		"text":              map[string]interface{}{"body": text, "preview_url": false},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%s/%s/messages", graphAPIBase, wh.PhoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+wh.AccessToken)
+
+	resp, err := wh.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("whatsapp API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
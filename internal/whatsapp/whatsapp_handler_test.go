@@ -0,0 +1,226 @@
+// internal/whatsapp/whatsapp_handler_test.go
+
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubProcessor records the WhatsApp messages it is asked to answer.
+type stubProcessor struct {
+	mutex sync.Mutex
+	texts []string
+	calls chan string
+}
+
+func (p *stubProcessor) ProcessWhatsAppMessage(waID, text string) (string, error) {
+	p.mutex.Lock()
+	p.texts = append(p.texts, text)
+	p.mutex.Unlock()
+	p.calls <- text
+	return "reply", nil
+}
+
+// roundTripFunc lets a test answer outbound Graph API requests.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newTestHandler returns a handler whose replies are accepted without leaving the process.
+func newTestHandler(secret string, maxConcurrent int, processor *stubProcessor) *WhatsAppHandler {
+	wh := NewWhatsAppHandler("verify-me", secret, "token", "12345", maxConcurrent, processor)
+	wh.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Header: make(http.Header)}, nil
+	})}
+	return wh
+}
+
+// sign returns the X-Hub-Signature-256 header Meta would send for body.
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const textEvent = `{"object":"whatsapp_business_account","entry":[{"changes":[{"field":"messages","value":{"messages":[
+	{"from":"15551234567","id":"wamid.1","type":"text","text":{"body":"  Best bait for bass?  "}},
+	{"from":"15551234567","id":"wamid.2","type":"image"},
+	{"from":"","id":"wamid.3","type":"text","text":{"body":"no sender"}},
+	{"from":"15557654321","id":"wamid.4","type":"text","text":{"body":"   "}}
+]}}]}]}`
+
+func TestHandleVerification(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid token", "hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=42", http.StatusOK, "42"},
+		{"wrong token", "hub.mode=subscribe&hub.verify_token=nope&hub.challenge=42", http.StatusForbidden, ""},
+		{"wrong mode", "hub.mode=unsubscribe&hub.verify_token=verify-me&hub.challenge=42", http.StatusForbidden, ""},
+	}
+	wh := newTestHandler("secret", 1, &stubProcessor{calls: make(chan string, 1)})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			wh.HandleWhatsAppWebhook(rec, httptest.NewRequest(http.MethodGet, "/whatsapp?"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account"}`)
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		want   bool
+	}{
+		{"valid signature", "secret", sign("secret", string(body)), true},
+		{"signed with another secret", "secret", sign("other", string(body)), false},
+		{"missing prefix", "secret", strings.TrimPrefix(sign("secret", string(body)), "sha256="), false},
+		{"missing header", "secret", "", false},
+		{"no app secret configured", "", sign("", string(body)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh := &WhatsAppHandler{AppSecret: tt.secret}
+			if got := wh.verifySignature(tt.header, body); got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTextMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []TextMessage
+		wantErr bool
+	}{
+		{
+			name: "text messages only",
+			body: textEvent,
+			want: []TextMessage{{ID: "wamid.1", WaID: "15551234567", Text: "Best bait for bass?"}},
+		},
+		{name: "status update", body: `{"entry":[{"changes":[{"value":{"statuses":[{"id":"wamid.9"}]}}]}]}`},
+		{name: "malformed", body: `{"entry":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractTextMessages([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d messages, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("message %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleEventSignature(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		wantStatus int
+	}{
+		{"signed event is answered", "secret", sign("secret", textEvent), http.StatusOK},
+		{"forged event is rejected", "secret", sign("guess", textEvent), http.StatusUnauthorized},
+		{"unsigned event without app secret is rejected", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &stubProcessor{calls: make(chan string, 1)}
+			wh := newTestHandler(tt.secret, 1, processor)
+			req := httptest.NewRequest(http.MethodPost, "/whatsapp", strings.NewReader(textEvent))
+			req.Header.Set("X-Hub-Signature-256", tt.header)
+			rec := httptest.NewRecorder()
+			wh.HandleWhatsAppWebhook(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			select {
+			case text := <-processor.calls:
+				if text != "Best bait for bass?" {
+					t.Errorf("processed %q", text)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("message was not answered")
+			}
+		})
+	}
+}
+
+func TestDispatchSkipsRedeliveredMessages(t *testing.T) {
+	processor := &stubProcessor{calls: make(chan string, 4)}
+	wh := newTestHandler("secret", 4, processor)
+	message := TextMessage{ID: "wamid.1", WaID: "15551234567", Text: "Best bait for bass?"}
+
+	for i := 0; i < 3; i++ {
+		if !wh.dispatch([]TextMessage{message}) {
+			t.Fatalf("delivery %d was not accepted", i+1)
+		}
+	}
+	<-processor.calls
+	select {
+	case text := <-processor.calls:
+		t.Fatalf("redelivered message %q was answered again", text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDispatchBoundsConcurrency(t *testing.T) {
+	processor := &stubProcessor{calls: make(chan string)} // Unbuffered: answers block until the test reads them
+	wh := newTestHandler("secret", 1, processor)
+
+	if !wh.dispatch([]TextMessage{{ID: "wamid.1", WaID: "1", Text: "first"}}) {
+		t.Fatal("first message was not accepted")
+	}
+	if wh.dispatch([]TextMessage{{ID: "wamid.2", WaID: "2", Text: "second"}}) {
+		t.Fatal("second message was accepted while the only slot was busy")
+	}
+	if got := <-processor.calls; got != "first" {
+		t.Fatalf("answered %q, want first", got)
+	}
+
+	// The rejected message was forgotten, so Meta's redelivery is answered once the slot frees up
+	deadline := time.Now().Add(time.Second)
+	for !wh.dispatch([]TextMessage{{ID: "wamid.2", WaID: "2", Text: "second"}}) {
+		if time.Now().After(deadline) {
+			t.Fatal("redelivered message was never accepted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := <-processor.calls; got != "second" {
+		t.Fatalf("answered %q, want second", got)
+	}
+}